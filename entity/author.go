@@ -0,0 +1,23 @@
+package entity
+
+import "github.com/google/uuid"
+
+const (
+	RoleAuthor     = "author"
+	RoleEditor     = "editor"
+	RoleTranslator = "translator"
+)
+
+type Author struct {
+	Id    uuid.UUID `json:"id" db:"id"`
+	Name  string    `json:"name" db:"name"`
+	Bio   string    `json:"bio" db:"bio"`
+	Photo string    `json:"photo" db:"photo"`
+}
+
+type BookAuthor struct {
+	BookId   uuid.UUID `json:"book_id" db:"book_id"`
+	AuthorId uuid.UUID `json:"author_id" db:"author_id"`
+	Role     string    `json:"role" db:"role"`
+	Position int       `json:"position" db:"position"`
+}
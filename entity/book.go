@@ -1,17 +1,27 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Book struct {
-	Id           uuid.UUID `json:"id" db:"id"`
-	Title        string    `json:"title" db:"title"`
-	Description  string    `json:"description" db:"description"`
-	Introduction string    `json:"introduction" db:"introduction"`
-	Image        string    `json:"image" db:"image"`
-	File         string    `json:"file" db:"file"`
-	Author       string    `json:"author" db:"author"`
-	ReleaseDate  string    `json:"release_date" db:"release_date"`
-	Price        float64   `json:"price" db:"price"`
+	Id           uuid.UUID  `json:"id" db:"id"`
+	Title        string     `json:"title" db:"title"`
+	Description  string     `json:"description" db:"description"`
+	Introduction string     `json:"introduction" db:"introduction"`
+	Image        string     `json:"image" db:"image"`
+	File         string     `json:"file" db:"file"`
+	Author       string     `json:"author" db:"author"`
+	ReleaseDate  string     `json:"release_date" db:"release_date"`
+	Price        float64    `json:"price" db:"price"`
+	PublisherId  uuid.UUID  `json:"publisher_id" db:"publisher_id"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy    uuid.UUID  `json:"deleted_by" db:"deleted_by"`
+}
+
+// Unreleased reports whether the release date is still ahead of now
+func (b Book) Unreleased(now time.Time) bool {
+	return len(b.ReleaseDate) >= 10 && b.ReleaseDate[:10] > now.Format("2006-01-02")
 }
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookFileCopy is a purchaser's watermarked copy of a book file
+type BookFileCopy struct {
+	PaymentId  uuid.UUID `json:"payment_id" db:"payment_id"`
+	BookId     uuid.UUID `json:"book_id" db:"book_id"`
+	SourceFile string    `json:"source_file" db:"source_file"`
+	Path       string    `json:"path" db:"path"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
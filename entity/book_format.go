@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FormatEbook     = "ebook"
+	FormatPaperback = "paperback"
+	FormatHardcover = "hardcover"
+	FormatAudiobook = "audiobook"
+)
+
+type BookFormat struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	BookId    uuid.UUID `json:"book_id" db:"book_id"`
+	Format    string    `json:"format" db:"format"`
+	Sku       string    `json:"sku" db:"sku"`
+	Price     float64   `json:"price" db:"price"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// digital formats are owned once: quantity 1, never re-bought, no stock
+func (f BookFormat) IsDigital() bool {
+	return f.Format == FormatEbook || f.Format == FormatAudiobook
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "github.com/google/uuid"
+
+type BookRating struct {
+	BookId      uuid.UUID `json:"book_id" db:"book_id"`
+	RatingCount int       `json:"rating_count" db:"rating_count"`
+	RatingSum   int       `json:"rating_sum" db:"rating_sum"`
+	Star1       int       `json:"star_1" db:"star_1"`
+	Star2       int       `json:"star_2" db:"star_2"`
+	Star3       int       `json:"star_3" db:"star_3"`
+	Star4       int       `json:"star_4" db:"star_4"`
+	Star5       int       `json:"star_5" db:"star_5"`
+	Average     float64   `json:"average" db:"average"`
+}
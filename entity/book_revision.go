@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+)
+
+type BookRevision struct {
+	Id        uuid.UUID      `json:"id" db:"id"`
+	BookId    uuid.UUID      `json:"book_id" db:"book_id"`
+	EditorId  uuid.UUID      `json:"editor_id" db:"editor_id"`
+	Changes   types.JSONText `json:"changes" db:"changes"`
+	RevertsId uuid.UUID      `json:"reverts_id" db:"reverts_id"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
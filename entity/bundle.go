@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Bundle struct {
+	Id          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description" db:"description"`
+	Price       float64    `json:"price" db:"price"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+type BundleBook struct {
+	BundleId uuid.UUID `json:"bundle_id" db:"bundle_id"`
+	BookId   uuid.UUID `json:"book_id" db:"book_id"`
+	Position int       `json:"position" db:"position"`
+}
@@ -6,6 +6,11 @@ type Cart struct {
 	Id         uuid.UUID `json:"id" db:"id"`
 	UserId     uuid.UUID `json:"user_id" db:"user_id"`
 	BookId     uuid.UUID `json:"book_id" db:"book_id"`
+	FormatId   uuid.UUID `json:"format_id" db:"format_id"`
 	Amount     int       `json:"amount" db:"amount"`
 	CheckoutId uuid.UUID `json:"checkout_id" db:"checkout_id"`
+	UnitPrice  float64   `json:"unit_price" db:"unit_price"`
+	Discount   float64   `json:"discount" db:"discount"`
+	SaleId     uuid.UUID `json:"sale_id" db:"sale_id"`
+	BundleId   uuid.UUID `json:"bundle_id" db:"bundle_id"`
 }
@@ -0,0 +1,9 @@
+package entity
+
+import "github.com/google/uuid"
+
+type Category struct {
+	Id       uuid.UUID `json:"id" db:"id"`
+	ParentId uuid.UUID `json:"parent_id" db:"parent_id"`
+	Name     string    `json:"name" db:"name"`
+}
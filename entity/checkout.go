@@ -3,6 +3,12 @@ package entity
 import "github.com/google/uuid"
 
 type Checkout struct {
-	Id     uuid.UUID `json:"id" db:"id"`
-	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	Id              uuid.UUID `json:"id" db:"id"`
+	UserID          uuid.UUID `json:"user_id" db:"user_id"`
+	ShippingName    string    `json:"shipping_name" db:"shipping_name"`
+	ShippingPhone   string    `json:"shipping_phone" db:"shipping_phone"`
+	ShippingAddress string    `json:"shipping_address" db:"shipping_address"`
+	ShippingFee     float64   `json:"shipping_fee" db:"shipping_fee"`
+	Subtotal        float64   `json:"subtotal" db:"subtotal"`
+	Discount        float64   `json:"discount" db:"discount"`
 }
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Download struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	UserId    uuid.UUID `json:"user_id" db:"user_id"`
+	BookId    uuid.UUID `json:"book_id" db:"book_id"`
+	IpAddress string    `json:"ip_address" db:"ip_address"`
+	UserAgent string    `json:"user_agent" db:"user_agent"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReservationHeld      = "held"
+	ReservationReleased  = "released"
+	ReservationCommitted = "committed"
+)
+
+type Inventory struct {
+	FormatId          uuid.UUID `json:"format_id" db:"format_id"`
+	BookId            uuid.UUID `json:"book_id" db:"book_id"`
+	OnHand            int       `json:"on_hand" db:"on_hand"`
+	Reserved          int       `json:"reserved" db:"reserved"`
+	LowStockThreshold int       `json:"low_stock_threshold" db:"low_stock_threshold"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type StockAdjustment struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	BookId    uuid.UUID `json:"book_id" db:"book_id"`
+	FormatId  uuid.UUID `json:"format_id" db:"format_id"`
+	Delta     int       `json:"delta" db:"delta"`
+	Reason    string    `json:"reason" db:"reason"`
+	AdminId   uuid.UUID `json:"admin_id" db:"admin_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
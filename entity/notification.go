@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NotificationSecurity     = "security"
+	NotificationOrderUpdates = "order_updates"
+	NotificationMarketing    = "marketing"
+	NotificationNewReleases  = "new_releases"
+	NotificationPriceDrops   = "price_drops"
+
+	ChannelEmail = "email"
+	ChannelInApp = "in_app"
+)
+
+type NotificationPreference struct {
+	UserId   uuid.UUID `json:"user_id" db:"user_id"`
+	Category string    `json:"category" db:"category"`
+	Channel  string    `json:"channel" db:"channel"`
+	Enabled  bool      `json:"enabled" db:"enabled"`
+}
+
+type Notification struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	UserId    uuid.UUID `json:"user_id" db:"user_id"`
+	Category  string    `json:"category" db:"category"`
+	Title     string    `json:"title" db:"title"`
+	Body      string    `json:"body" db:"body"`
+	IsRead    bool      `json:"is_read" db:"is_read"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
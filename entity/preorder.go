@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PreorderOpen      = "open"
+	PreorderFulfilled = "fulfilled"
+	PreorderCancelled = "cancelled"
+)
+
+type Preorder struct {
+	Id           uuid.UUID `json:"id" db:"id"`
+	CartId       uuid.UUID `json:"cart_id" db:"cart_id"`
+	UserId       uuid.UUID `json:"user_id" db:"user_id"`
+	BookId       uuid.UUID `json:"book_id" db:"book_id"`
+	CheckoutId   uuid.UUID `json:"checkout_id" db:"checkout_id"`
+	ReleaseDate  string    `json:"release_date" db:"release_date"`
+	Status       string    `json:"status" db:"status"`
+	RefundAmount float64   `json:"refund_amount" db:"refund_amount"`
+	CancelReason string    `json:"cancel_reason" db:"cancel_reason"`
+	CancelledBy  uuid.UUID `json:"cancelled_by" db:"cancelled_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PromotionPercentage = "percentage"
+	PromotionFixed      = "fixed"
+
+	PromotionScopeAll      = "all"
+	PromotionScopeBook     = "book"
+	PromotionScopeCategory = "category"
+)
+
+// Promotion without a code is applied automatically at checkout
+type Promotion struct {
+	Id             uuid.UUID  `json:"id" db:"id"`
+	Code           *string    `json:"code" db:"code"`
+	Name           string     `json:"name" db:"name"`
+	Kind           string     `json:"kind" db:"kind"`
+	Value          float64    `json:"value" db:"value"`
+	Scope          string     `json:"scope" db:"scope"`
+	BookId         uuid.UUID  `json:"book_id" db:"book_id"`
+	CategoryId     uuid.UUID  `json:"category_id" db:"category_id"`
+	MinSpend       float64    `json:"min_spend" db:"min_spend"`
+	MinQuantity    int        `json:"min_quantity" db:"min_quantity"`
+	MaxUses        *int       `json:"max_uses" db:"max_uses"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user" db:"max_uses_per_user"`
+	StartsAt       time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at" db:"ends_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+type PromotionRedemption struct {
+	PromotionId uuid.UUID `json:"promotion_id" db:"promotion_id"`
+	CheckoutId  uuid.UUID `json:"checkout_id" db:"checkout_id"`
+	UserId      uuid.UUID `json:"user_id" db:"user_id"`
+	Discount    float64   `json:"discount" db:"discount"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "github.com/google/uuid"
+
+type Publisher struct {
+	Id          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Logo        string    `json:"logo" db:"logo"`
+}
@@ -0,0 +1,8 @@
+package entity
+
+const (
+	RankingBestseller7d  = "bestseller_7d"
+	RankingBestseller30d = "bestseller_30d"
+	RankingBestsellerAll = "bestseller_all"
+	RankingTrending      = "trending"
+)
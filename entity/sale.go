@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sale prices a format below its list price for a window; a quantity makes
+// it a flash sale that ends early once sold out
+type Sale struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	BookId    uuid.UUID `json:"book_id" db:"book_id"`
+	FormatId  uuid.UUID `json:"format_id" db:"format_id"`
+	SalePrice float64   `json:"sale_price" db:"sale_price"`
+	StartsAt  time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time `json:"ends_at" db:"ends_at"`
+	Quantity  *int      `json:"quantity" db:"quantity"`
+	Sold      int       `json:"sold" db:"sold"`
+	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type PriceHistory struct {
+	Id         uuid.UUID `json:"id" db:"id"`
+	BookId     uuid.UUID `json:"book_id" db:"book_id"`
+	FormatId   uuid.UUID `json:"format_id" db:"format_id"`
+	Price      float64   `json:"price" db:"price"`
+	SaleId     uuid.UUID `json:"sale_id" db:"sale_id"`
+	RecordedAt time.Time `json:"recorded_at" db:"recorded_at"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SamplePages    = "pages"
+	SampleChapters = "chapters"
+	SampleFile     = "file"
+)
+
+type BookSample struct {
+	BookId     uuid.UUID `json:"book_id" db:"book_id"`
+	Mode       string    `json:"mode" db:"mode"`
+	Count      int       `json:"count" db:"count"`
+	Path       string    `json:"-" db:"path"`
+	SourceFile string    `json:"-" db:"source_file"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type Preview struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	UserId    uuid.UUID `json:"user_id" db:"user_id"`
+	BookId    uuid.UUID `json:"book_id" db:"book_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Series struct {
+	Id          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type SeriesBook struct {
+	SeriesId uuid.UUID `json:"series_id" db:"series_id"`
+	BookId   uuid.UUID `json:"book_id" db:"book_id"`
+	Position int       `json:"position" db:"position"`
+	Label    string    `json:"label" db:"label"`
+}
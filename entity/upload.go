@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	UploadPending    = "pending"
+	UploadReferenced = "referenced"
+	UploadSuperseded = "superseded"
+)
+
+type Upload struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	OwnerId   uuid.UUID `json:"owner_id" db:"owner_id"`
+	Purpose   string    `json:"purpose" db:"purpose"`
+	Path      string    `json:"path" db:"path"`
+	Url       string    `json:"url" db:"url"`
+	Status    string    `json:"status" db:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
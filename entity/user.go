@@ -1,13 +1,19 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
-	Id             uuid.UUID `json:"id" db:"id"`
-	Username       string    `json:"username" db:"username"`
-	Email          string    `json:"email" db:"email"`
-	Password       string    `json:"password" db:"password"`
-	RoleId         int       `json:"roleId" db:"role_id"`
-	IsVerified     bool      `json:"isVerified" db:"is_verified"`
-	ProfilePicture string    `json:"profilePicture" db:"profile_picture"`
+	Id             uuid.UUID  `json:"id" db:"id"`
+	Username       string     `json:"username" db:"username"`
+	Email          string     `json:"email" db:"email"`
+	Password       string     `json:"password" db:"password"`
+	RoleId         int        `json:"roleId" db:"role_id"`
+	IsVerified     bool       `json:"isVerified" db:"is_verified"`
+	ProfilePicture string     `json:"profilePicture" db:"profile_picture"`
+	IsPrivate      bool       `json:"isPrivate" db:"is_private"`
+	CreatedAt      *time.Time `json:"createdAt" db:"created_at"`
 }
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Wishlist struct {
+	UserId         uuid.UUID `json:"user_id" db:"user_id"`
+	BookId         uuid.UUID `json:"book_id" db:"book_id"`
+	AlertPrice     float64   `json:"alert_price" db:"alert_price"`
+	ReleaseAlerted bool      `json:"release_alerted" db:"release_alerted"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetAuthors(ctx *fiber.Ctx) error {
+	authorReq := model.AuthorReq{
+		Page:     ctx.QueryInt("page", 1),
+		PageSize: ctx.QueryInt("size", 20),
+		Search:   ctx.Query("search"),
+	}
+
+	if err := r.validator.Struct(authorReq); err != nil {
+		return &response.BadRequest
+	}
+
+	authors, err := r.service.AuthorService.GetAuthors(authorReq)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", authors)
+	return nil
+}
+
+func (r *Rest) GetAuthor(ctx *fiber.Ctx) error {
+	authorId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	author, err := r.service.AuthorService.GetAuthor(authorId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", author)
+	return nil
+}
+
+func (r *Rest) CreateAuthor(ctx *fiber.Ctx) error {
+	var create model.CreateAuthor
+	if err := ctx.BodyParser(&create); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(create); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.AuthorService.CreateAuthor(create); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) EditAuthor(ctx *fiber.Ctx) error {
+	var edit model.EditAuthor
+	if err := ctx.BodyParser(&edit); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(edit); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.AuthorService.EditAuthor(edit); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) DeleteAuthor(ctx *fiber.Ctx) error {
+	authorId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.AuthorService.DeleteAuthor(authorId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) UploadAuthorPhoto(ctx *fiber.Ctx) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	url, err := r.service.AuthorService.UploadAuthorPhoto(file, userId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", url)
+	return nil
+}
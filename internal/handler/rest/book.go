@@ -25,18 +25,74 @@ func (r *Rest) GetBook(ctx *fiber.Ctx) error {
 	return nil
 }
 
+// SearchBooks keeps the original GET /books contract of a plain list of books
 func (r *Rest) SearchBooks(ctx *fiber.Ctx) error {
+	bookSearch, err := r.parseBookSearch(ctx)
+	if err != nil {
+		return err
+	}
+
+	books, err := r.service.BookService.SearchBooks(bookSearch)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", books.Books)
+	return nil
+}
+
+// SearchCatalog returns a page of books along with the total, facet counts and
+// a spelling suggestion
+func (r *Rest) SearchCatalog(ctx *fiber.Ctx) error {
+	bookSearch, err := r.parseBookSearch(ctx)
+	if err != nil {
+		return err
+	}
+
+	books, err := r.service.BookService.SearchBooks(bookSearch)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", books)
+	return nil
+}
+
+func (r *Rest) parseBookSearch(ctx *fiber.Ctx) (model.BookSearch, error) {
 	var bookSearch model.BookSearch
 	bookSearch.Page = ctx.QueryInt("page", 1)
 	bookSearch.PageSize = ctx.QueryInt("size", 9)
-	bookSearch.SearchParam = ctx.Query("search", "%")
+	bookSearch.SearchParam = ctx.Query("search")
+	bookSearch.Author = ctx.Query("author")
+	bookSearch.MinPrice = ctx.QueryFloat("min_price")
+	bookSearch.MaxPrice = ctx.QueryFloat("max_price")
+	bookSearch.ReleasedFrom = ctx.Query("released_from")
+	bookSearch.ReleasedTo = ctx.Query("released_to")
+	bookSearch.MinRating = ctx.QueryFloat("min_rating")
+	bookSearch.Sort = ctx.Query("sort")
 
-	books, err := r.service.BookService.SearchBooks(bookSearch)
+	if category := ctx.Query("category"); category != "" {
+		categoryId, err := uuid.Parse(category)
+		if err != nil {
+			return bookSearch, err
+		}
+		bookSearch.CategoryId = categoryId
+	}
+
+	if err := r.validator.Struct(bookSearch); err != nil {
+		return bookSearch, &response.BadRequest
+	}
+
+	return bookSearch, nil
+}
+
+func (r *Rest) SuggestBooks(ctx *fiber.Ctx) error {
+	suggestions, err := r.service.BookService.SuggestBooks(ctx.Query("q"), ctx.QueryInt("limit", 8))
 	if err != nil {
 		return err
 	}
 
-	response.Success(ctx, http.StatusOK, "success", books)
+	response.Success(ctx, http.StatusOK, "success", suggestions)
 	return nil
 }
 
@@ -61,7 +117,12 @@ func (r *Rest) DeleteBook(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	if err := r.service.BookService.DeleteBook(bookId); err != nil {
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.BookService.DeleteBook(bookId, userId); err != nil {
 		return err
 	}
 
@@ -69,13 +130,85 @@ func (r *Rest) DeleteBook(ctx *fiber.Ctx) error {
 	return nil
 }
 
+func (r *Rest) RestoreBook(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.BookService.RestoreBook(bookId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) GetDeletedBooks(ctx *fiber.Ctx) error {
+	books, err := r.service.BookService.GetDeletedBooks(ctx.QueryInt("page", 1), ctx.QueryInt("size", 20))
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", books)
+	return nil
+}
+
 func (r *Rest) EditBook(ctx *fiber.Ctx) error {
 	var edit model.EditBook
 	if err := ctx.BodyParser(&edit); err != nil {
 		return err
 	}
 
-	if err := r.service.BookService.EditBook(edit); err != nil {
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.BookService.EditBook(edit, userId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) GetBookRevisions(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	revisions, err := r.service.BookService.GetRevisions(bookId, ctx.Query("field"))
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", revisions)
+	return nil
+}
+
+func (r *Rest) RevertBook(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var revert model.RevertBook
+	if err := ctx.BodyParser(&revert); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(revert); err != nil {
+		return &response.BadRequest
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.BookService.RevertBook(bookId, revert.RevisionId, userId); err != nil {
 		return err
 	}
 
@@ -89,7 +222,12 @@ func (r *Rest) UploadBookCover(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	url, err := r.service.BookService.UploadBookCover(file)
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	url, err := r.service.BookService.UploadBookCover(file, userId)
 	if err != nil {
 		return err
 	}
@@ -97,3 +235,70 @@ func (r *Rest) UploadBookCover(ctx *fiber.Ctx) error {
 	response.Success(ctx, http.StatusOK, "success", url)
 	return nil
 }
+
+func (r *Rest) UploadBookFile(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(model.BookFile{File: file}); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.BookService.UploadBookFile(bookId, file); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) DownloadBook(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	download, err := r.service.BookService.DownloadBook(bookId, userId, ctx.IP(), ctx.Get(fiber.HeaderUserAgent))
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", download)
+	return nil
+}
+
+func (r *Rest) DraftBookFromFile(ctx *fiber.Ctx) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(model.BookFile{File: file}); err != nil {
+		return &response.BadRequest
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	draft, err := r.service.BookService.DraftBookFromFile(file, userId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", draft)
+	return nil
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetBookFormats(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	formats, err := r.service.BookFormatService.GetBookFormats(bookId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", formats)
+	return nil
+}
+
+func (r *Rest) CreateBookFormat(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var create model.CreateBookFormat
+	if err := ctx.BodyParser(&create); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(create); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.BookFormatService.CreateFormat(bookId, create); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusCreated, "success", nil)
+	return nil
+}
+
+func (r *Rest) EditBookFormat(ctx *fiber.Ctx) error {
+	formatId, err := uuid.Parse(ctx.Params("formatId"))
+	if err != nil {
+		return err
+	}
+
+	var edit model.EditBookFormat
+	if err := ctx.BodyParser(&edit); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(edit); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.BookFormatService.EditFormat(formatId, edit); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) DeleteBookFormat(ctx *fiber.Ctx) error {
+	formatId, err := uuid.Parse(ctx.Params("formatId"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.BookFormatService.DeleteFormat(formatId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
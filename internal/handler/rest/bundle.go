@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetBundles(ctx *fiber.Ctx) error {
+	bundles, err := r.service.BundleService.GetBundles()
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", bundles)
+	return nil
+}
+
+func (r *Rest) GetBundle(ctx *fiber.Ctx) error {
+	bundleId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	bundle, err := r.service.BundleService.GetBundle(bundleId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", bundle)
+	return nil
+}
+
+func (r *Rest) CreateBundle(ctx *fiber.Ctx) error {
+	var create model.CreateBundle
+	if err := ctx.BodyParser(&create); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(create); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.BundleService.CreateBundle(create); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) EditBundle(ctx *fiber.Ctx) error {
+	var edit model.EditBundle
+	if err := ctx.BodyParser(&edit); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(edit); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.BundleService.EditBundle(edit); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) DeleteBundle(ctx *fiber.Ctx) error {
+	bundleId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.BundleService.DeleteBundle(bundleId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) AddBundleToCart(ctx *fiber.Ctx) error {
+	bundleId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.BundleService.AddBundleToCart(bundleId, userId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
@@ -16,12 +16,12 @@ func (r *Rest) GetUserCart(ctx *fiber.Ctx) error {
 		return &response.Unauthorized
 	}
 
-	var carts []entity.Cart
-	if err := r.service.CartService.GetUserCart(&carts, userId); err != nil {
+	cart, err := r.service.CartService.GetUserCart(userId)
+	if err != nil {
 		return err
 	}
 
-	response.Success(ctx, http.StatusOK, "success", carts)
+	response.Success(ctx, http.StatusOK, "success", cart)
 	return nil
 }
 
@@ -32,12 +32,12 @@ func (r *Rest) GetUserCartAdmin(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	var carts []entity.Cart
-	if err := r.service.CartService.GetUserCart(&carts, userId); err != nil {
+	cart, err := r.service.CartService.GetUserCart(userId)
+	if err != nil {
 		return err
 	}
 
-	response.Success(ctx, http.StatusOK, "success", carts)
+	response.Success(ctx, http.StatusOK, "success", cart)
 	return nil
 }
 
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetCategories(ctx *fiber.Ctx) error {
+	categories, err := r.service.CategoryService.GetCategoryTree()
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", categories)
+	return nil
+}
+
+func (r *Rest) GetCategory(ctx *fiber.Ctx) error {
+	categoryId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	category, err := r.service.CategoryService.GetCategory(categoryId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", category)
+	return nil
+}
+
+func (r *Rest) CreateCategory(ctx *fiber.Ctx) error {
+	var create model.CreateCategory
+	if err := ctx.BodyParser(&create); err != nil {
+		return err
+	}
+
+	if err := r.service.CategoryService.CreateCategory(create); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) EditCategory(ctx *fiber.Ctx) error {
+	var edit model.EditCategory
+	if err := ctx.BodyParser(&edit); err != nil {
+		return err
+	}
+
+	if err := r.service.CategoryService.EditCategory(edit); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) DeleteCategory(ctx *fiber.Ctx) error {
+	categoryId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.CategoryService.DeleteCategory(categoryId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
@@ -62,22 +62,29 @@ func (r *Rest) Checkout(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	if err := r.validator.Struct(request); err != nil {
+		return &response.BadRequest
+	}
+
 	userId, ok := ctx.Locals("userId").(uuid.UUID)
 	if !ok {
 		return &response.Unauthorized
 	}
 
 	checkoutId := uuid.New()
-	totalPrice, err := r.service.CheckoutService.Checkout(request, userId, checkoutId)
+	summary, err := r.service.CheckoutService.Checkout(request, userId, checkoutId)
 	if err != nil {
 		return err
 	}
 
-	snapRes, err := r.service.PaymentService.CreatePayment(userId, checkoutId, totalPrice)
+	snapRes, err := r.service.PaymentService.CreatePayment(userId, checkoutId, summary)
 	if err != nil {
 		return err
 	}
 
-	response.Success(ctx, http.StatusOK, "success", snapRes)
+	response.Success(ctx, http.StatusOK, "success", model.CheckoutResponse{
+		Response: snapRes,
+		Checkout: summary,
+	})
 	return nil
 }
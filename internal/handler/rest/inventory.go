@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetInventories(ctx *fiber.Ctx) error {
+	req := model.InventoryReq{
+		Page:         ctx.QueryInt("page", 1),
+		PageSize:     ctx.QueryInt("page_size", 10),
+		LowStockOnly: ctx.QueryBool("low_stock"),
+	}
+
+	if err := r.validator.Struct(req); err != nil {
+		return &response.BadRequest
+	}
+
+	inventories, err := r.service.InventoryService.GetInventories(req)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", inventories)
+	return nil
+}
+
+func (r *Rest) GetInventory(ctx *fiber.Ctx) error {
+	formatId, err := uuid.Parse(ctx.Params("formatId"))
+	if err != nil {
+		return err
+	}
+
+	inventory, err := r.service.InventoryService.GetInventory(formatId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", inventory)
+	return nil
+}
+
+func (r *Rest) GetStockAdjustments(ctx *fiber.Ctx) error {
+	formatId, err := uuid.Parse(ctx.Params("formatId"))
+	if err != nil {
+		return err
+	}
+
+	adjustments, err := r.service.InventoryService.GetAdjustments(formatId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", adjustments)
+	return nil
+}
+
+func (r *Rest) AdjustStock(ctx *fiber.Ctx) error {
+	formatId, err := uuid.Parse(ctx.Params("formatId"))
+	if err != nil {
+		return err
+	}
+
+	var adjust model.AdjustStock
+	if err := ctx.BodyParser(&adjust); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(adjust); err != nil {
+		return &response.BadRequest
+	}
+
+	adminId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.InventoryService.AdjustStock(formatId, adminId, adjust); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) SetLowStockThreshold(ctx *fiber.Ctx) error {
+	formatId, err := uuid.Parse(ctx.Params("formatId"))
+	if err != nil {
+		return err
+	}
+
+	var set model.SetLowStockThreshold
+	if err := ctx.BodyParser(&set); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(set); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.InventoryService.SetLowStockThreshold(formatId, *set.LowStockThreshold); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetNotificationPreferences(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	preferences, err := r.service.NotificationService.GetPreferences(userId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", preferences)
+	return nil
+}
+
+func (r *Rest) UpdateNotificationPreferences(ctx *fiber.Ctx) error {
+	var req model.UpdatePreferences
+	if err := ctx.BodyParser(&req); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(req); err != nil {
+		return &response.BadRequest
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.NotificationService.UpdatePreferences(userId, req); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+<p>Stop receiving {{.Category}} emails?</p>
+<form method="post" action="?token={{.Token}}&amp;category={{.Category}}">
+<input type="hidden" name="List-Unsubscribe" value="One-Click">
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>`))
+
+// ConfirmUnsubscribe only shows the confirmation, since link scanners and
+// prefetchers follow GET links in emails
+func (r *Rest) ConfirmUnsubscribe(ctx *fiber.Ctx) error {
+	token, category := ctx.Query("token"), ctx.Query("category")
+	if token == "" {
+		return &response.InvalidToken
+	}
+
+	if err := r.service.NotificationService.CheckUnsubscribe(token, category); err != nil {
+		return err
+	}
+
+	ctx.Type("html", "utf-8")
+	return unsubscribePage.Execute(ctx.Response().BodyWriter(), fiber.Map{
+		"Token":    token,
+		"Category": category,
+	})
+}
+
+// Unsubscribe takes the RFC 8058 one-click POST from mail clients as well as
+// the confirmation form
+func (r *Rest) Unsubscribe(ctx *fiber.Ctx) error {
+	token := ctx.Query("token")
+	if token == "" {
+		return &response.InvalidToken
+	}
+
+	if err := r.service.NotificationService.Unsubscribe(token, ctx.Query("category")); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) GetNotifications(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	var req model.NotificationReq
+	req.Page = ctx.QueryInt("page", 1)
+	req.PageSize = ctx.QueryInt("size", 9)
+
+	notifications, err := r.service.NotificationService.GetNotifications(userId, req)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", notifications)
+	return nil
+}
+
+func (r *Rest) ReadNotification(ctx *fiber.Ctx) error {
+	notificationId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.NotificationService.ReadNotification(notificationId, userId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
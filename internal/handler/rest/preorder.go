@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetUserPreorders(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	preorders, err := r.service.PreorderService.GetUserPreorders(userId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", preorders)
+	return nil
+}
+
+func (r *Rest) GetBookPreorders(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("bookId"))
+	if err != nil {
+		return err
+	}
+
+	preorders, err := r.service.PreorderService.GetBookPreorders(bookId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", preorders)
+	return nil
+}
+
+func (r *Rest) CancelPreorder(ctx *fiber.Ctx) error {
+	preorderId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	adminId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	var cancel model.CancelPreorder
+	if err := ctx.BodyParser(&cancel); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(cancel); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.PreorderService.CancelPreorder(preorderId, adminId, cancel); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
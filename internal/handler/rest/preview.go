@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) SetBookSample(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var set model.SetBookSample
+	if err := ctx.BodyParser(&set); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(set); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.PreviewService.SetSample(bookId, set); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) UploadSampleFile(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(model.BookFile{File: file}); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.PreviewService.UploadSampleFile(bookId, file); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) DeleteBookSample(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.PreviewService.DeleteSample(bookId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) GetBookPreview(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	preview, err := r.service.PreviewService.GetPreview(bookId, userId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", preview)
+	return nil
+}
+
+func (r *Rest) GetPreviewConversions(ctx *fiber.Ctx) error {
+	conversions, err := r.service.PreviewService.GetConversions(ctx.QueryInt("days", 30))
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", conversions)
+	return nil
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetPromotions(ctx *fiber.Ctx) error {
+	req := model.PromotionReq{
+		Page:       ctx.QueryInt("page", 1),
+		PageSize:   ctx.QueryInt("page_size", 10),
+		ActiveOnly: ctx.QueryBool("active"),
+	}
+
+	if err := r.validator.Struct(req); err != nil {
+		return &response.BadRequest
+	}
+
+	promotions, err := r.service.PromotionService.GetPromotions(req)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", promotions)
+	return nil
+}
+
+func (r *Rest) GetPromotion(ctx *fiber.Ctx) error {
+	promotionId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	promotion, err := r.service.PromotionService.GetPromotion(promotionId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", promotion)
+	return nil
+}
+
+func (r *Rest) CreatePromotion(ctx *fiber.Ctx) error {
+	var create model.CreatePromotion
+	if err := ctx.BodyParser(&create); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(create); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.PromotionService.CreatePromotion(create); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusCreated, "success", nil)
+	return nil
+}
+
+func (r *Rest) EndPromotion(ctx *fiber.Ctx) error {
+	promotionId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.PromotionService.EndPromotion(promotionId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
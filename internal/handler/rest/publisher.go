@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetPublishers(ctx *fiber.Ctx) error {
+	publishers, err := r.service.PublisherService.GetPublishers()
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", publishers)
+	return nil
+}
+
+func (r *Rest) GetPublisher(ctx *fiber.Ctx) error {
+	publisherId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	publisher, err := r.service.PublisherService.GetPublisher(publisherId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", publisher)
+	return nil
+}
+
+func (r *Rest) CreatePublisher(ctx *fiber.Ctx) error {
+	var create model.CreatePublisher
+	if err := ctx.BodyParser(&create); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(create); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.PublisherService.CreatePublisher(create); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) EditPublisher(ctx *fiber.Ctx) error {
+	var edit model.EditPublisher
+	if err := ctx.BodyParser(&edit); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(edit); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.PublisherService.EditPublisher(edit); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) DeletePublisher(ctx *fiber.Ctx) error {
+	publisherId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.PublisherService.DeletePublisher(publisherId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) UploadPublisherLogo(ctx *fiber.Ctx) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	url, err := r.service.PublisherService.UploadPublisherLogo(file, userId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", url)
+	return nil
+}
@@ -0,0 +1,28 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (r *Rest) GetBestsellers(ctx *fiber.Ctx) error {
+	books, err := r.service.RankingService.GetBestsellers(ctx.Query("window"), ctx.QueryInt("limit", 10))
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", books)
+	return nil
+}
+
+func (r *Rest) GetTrending(ctx *fiber.Ctx) error {
+	books, err := r.service.RankingService.GetTrending(ctx.QueryInt("limit", 10))
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", books)
+	return nil
+}
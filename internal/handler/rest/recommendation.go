@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetRelatedBooks(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	books, err := r.service.RecommendationService.GetRelatedBooks(bookId, ctx.QueryInt("limit", 10))
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", books)
+	return nil
+}
+
+func (r *Rest) GetRecommendedBooks(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	books, err := r.service.RecommendationService.GetRecommendedBooks(userId, ctx.QueryInt("limit", 10))
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", books)
+	return nil
+}
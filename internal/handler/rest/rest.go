@@ -49,13 +49,118 @@ func mountUser(routerGroup fiber.Router, r *Rest) {
 func mountBook(routerGroup fiber.Router, r *Rest) {
 	books := routerGroup.Group("/books")
 	books.Get("/", r.middleware.Authenticate, r.SearchBooks)
+	books.Get("/search", r.middleware.Authenticate, r.SearchCatalog)
+	books.Get("/suggest", r.middleware.Authenticate, r.SuggestBooks)
+	books.Get("/recommended", r.middleware.Authenticate, r.GetRecommendedBooks)
+	books.Get("/bestsellers", r.middleware.Authenticate, r.GetBestsellers)
+	books.Get("/trending", r.middleware.Authenticate, r.GetTrending)
+	books.Get("/previews/conversions", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.GetPreviewConversions)
+	books.Get("/trash", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.GetDeletedBooks)
 	books.Get("/:id", r.middleware.Authenticate, r.GetBook)
+	books.Get("/:id/related", r.middleware.Authenticate, r.GetRelatedBooks)
+	books.Get("/:id/formats", r.middleware.Authenticate, r.GetBookFormats)
+	books.Post("/:id/formats", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CreateBookFormat)
+	books.Patch("/formats/:formatId", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.EditBookFormat)
+	books.Delete("/formats/:formatId", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DeleteBookFormat)
+	books.Get("/:id/price-history", r.middleware.Authenticate, r.GetPriceHistory)
+	books.Get("/:id/sales", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.GetBookSales)
+	books.Post("/:id/sales", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CreateSale)
+	books.Delete("/sales/:saleId", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.EndSale)
 	books.Post("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CreateBook)
 	books.Patch("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.EditBook)
 	books.Delete("/:id", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DeleteBook)
+	books.Patch("/:id/restore", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.RestoreBook)
+	books.Get("/:id/revisions", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.GetBookRevisions)
+	books.Post("/:id/revert", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.RevertBook)
+	books.Post("/:id/file", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.UploadBookFile)
+	books.Get("/:id/download", r.middleware.Authenticate, r.DownloadBook)
+	books.Get("/:id/preview", r.middleware.Authenticate, r.GetBookPreview)
+	books.Put("/:id/sample", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.SetBookSample)
+	books.Post("/:id/sample/file", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.UploadSampleFile)
+	books.Delete("/:id/sample", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DeleteBookSample)
+	books.Post("/draft", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DraftBookFromFile)
 	books.Post("/cover", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.UploadBookCover)
 }
 
+func mountCategory(routerGroup fiber.Router, r *Rest) {
+	categories := routerGroup.Group("/categories")
+	categories.Get("/", r.middleware.Authenticate, r.GetCategories)
+	categories.Get("/:id", r.middleware.Authenticate, r.GetCategory)
+	categories.Post("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CreateCategory)
+	categories.Patch("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.EditCategory)
+	categories.Delete("/:id", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DeleteCategory)
+}
+
+func mountAuthor(routerGroup fiber.Router, r *Rest) {
+	authors := routerGroup.Group("/authors")
+	authors.Get("/", r.middleware.Authenticate, r.GetAuthors)
+	authors.Get("/:id", r.middleware.Authenticate, r.GetAuthor)
+	authors.Post("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CreateAuthor)
+	authors.Patch("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.EditAuthor)
+	authors.Delete("/:id", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DeleteAuthor)
+	authors.Post("/photo", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.UploadAuthorPhoto)
+}
+
+func mountPublisher(routerGroup fiber.Router, r *Rest) {
+	publishers := routerGroup.Group("/publishers")
+	publishers.Get("/", r.middleware.Authenticate, r.GetPublishers)
+	publishers.Get("/:id", r.middleware.Authenticate, r.GetPublisher)
+	publishers.Post("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CreatePublisher)
+	publishers.Patch("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.EditPublisher)
+	publishers.Delete("/:id", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DeletePublisher)
+	publishers.Post("/logo", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.UploadPublisherLogo)
+}
+
+func mountSeries(routerGroup fiber.Router, r *Rest) {
+	series := routerGroup.Group("/series")
+	series.Get("/", r.middleware.Authenticate, r.GetAllSeries)
+	series.Get("/:id", r.middleware.Authenticate, r.GetSeries)
+	series.Post("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CreateSeries)
+	series.Patch("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.EditSeries)
+	series.Delete("/:id", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DeleteSeries)
+	series.Put("/:id/books", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.SetSeriesBooks)
+	series.Post("/:id/cart", r.middleware.Authenticate, r.AddSeriesToCart)
+}
+
+func mountBundle(routerGroup fiber.Router, r *Rest) {
+	bundles := routerGroup.Group("/bundles")
+	bundles.Get("/", r.middleware.Authenticate, r.GetBundles)
+	bundles.Get("/:id", r.middleware.Authenticate, r.GetBundle)
+	bundles.Post("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CreateBundle)
+	bundles.Patch("/", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.EditBundle)
+	bundles.Delete("/:id", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.DeleteBundle)
+	bundles.Post("/:id/cart", r.middleware.Authenticate, r.AddBundleToCart)
+}
+
+func mountInventory(routerGroup fiber.Router, r *Rest) {
+	inventory := routerGroup.Group("/inventory")
+	inventory.Use(r.middleware.Authenticate, r.middleware.Authorize([]int{1}))
+
+	inventory.Get("/", r.GetInventories)
+	inventory.Get("/:formatId", r.GetInventory)
+	inventory.Get("/:formatId/adjustments", r.GetStockAdjustments)
+	inventory.Post("/:formatId/adjustments", r.AdjustStock)
+	inventory.Put("/:formatId/threshold", r.SetLowStockThreshold)
+}
+
+func mountPromotion(routerGroup fiber.Router, r *Rest) {
+	promotions := routerGroup.Group("/promotions")
+	promotions.Use(r.middleware.Authenticate, r.middleware.Authorize([]int{1}))
+
+	promotions.Get("/", r.GetPromotions)
+	promotions.Get("/:id", r.GetPromotion)
+	promotions.Post("/", r.CreatePromotion)
+	promotions.Delete("/:id", r.EndPromotion)
+}
+
+func mountPreorder(routerGroup fiber.Router, r *Rest) {
+	preorders := routerGroup.Group("/preorders")
+
+	preorders.Get("/", r.middleware.Authenticate, r.GetUserPreorders)
+	preorders.Get("/book/:bookId", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.GetBookPreorders)
+	preorders.Delete("/:id", r.middleware.Authenticate, r.middleware.Authorize([]int{1}), r.CancelPreorder)
+}
+
 func mountComment(routerGroup fiber.Router, r *Rest) {
 	comments := routerGroup.Group("/comments")
 	comments.Use(r.middleware.Authenticate)
@@ -79,6 +184,29 @@ func mountCart(routerGroup fiber.Router, r *Rest) {
 	carts.Delete("/:cartId", r.RemoveFromCart)
 }
 
+func mountWishlist(routerGroup fiber.Router, r *Rest) {
+	wishlists := routerGroup.Group("/wishlists")
+	wishlists.Use(r.middleware.Authenticate)
+
+	wishlists.Get("/", r.GetWishlist)
+	wishlists.Post("/", r.AddToWishlist)
+	wishlists.Delete("/:bookId", r.RemoveFromWishlist)
+	wishlists.Post("/:bookId/cart", r.MoveWishlistToCart)
+}
+
+func mountReadingList(routerGroup fiber.Router, r *Rest) {
+	lists := routerGroup.Group("/reading-lists")
+	lists.Use(r.middleware.Authenticate)
+
+	lists.Get("/", r.GetReadingLists)
+	lists.Get("/:id", r.GetReadingList)
+	lists.Post("/", r.CreateReadingList)
+	lists.Patch("/", r.EditReadingList)
+	lists.Delete("/:id", r.DeleteReadingList)
+	lists.Post("/:id/books", r.AddReadingListBook)
+	lists.Delete("/:id/books/:bookId", r.RemoveReadingListBook)
+}
+
 func mountCheckout(routerGroup fiber.Router, r *Rest) {
 	checkouts := routerGroup.Group("/checkouts")
 	checkouts.Use(r.middleware.Authenticate)
@@ -100,6 +228,17 @@ func mountPayment(routerGroup fiber.Router, r *Rest) {
 	payments.Get("/checkout/:id", r.middleware.Authenticate, r.GetPaymentByCheckout)
 }
 
+func mountNotification(routerGroup fiber.Router, r *Rest) {
+	notifications := routerGroup.Group("/notifications")
+
+	notifications.Get("/unsubscribe", r.ConfirmUnsubscribe)
+	notifications.Post("/unsubscribe", r.Unsubscribe)
+	notifications.Get("/preferences", r.middleware.Authenticate, r.GetNotificationPreferences)
+	notifications.Put("/preferences", r.middleware.Authenticate, r.UpdateNotificationPreferences)
+	notifications.Get("/", r.middleware.Authenticate, r.GetNotifications)
+	notifications.Patch("/:id/read", r.middleware.Authenticate, r.ReadNotification)
+}
+
 func (r *Rest) RegisterRoutes() {
 	routerGroup := r.router.Group("/api/v1")
 
@@ -110,10 +249,21 @@ func (r *Rest) RegisterRoutes() {
 	mountUser(routerGroup, r)
 	mountAuth(routerGroup, r)
 	mountBook(routerGroup, r)
+	mountCategory(routerGroup, r)
+	mountAuthor(routerGroup, r)
+	mountPublisher(routerGroup, r)
+	mountSeries(routerGroup, r)
+	mountBundle(routerGroup, r)
+	mountInventory(routerGroup, r)
+	mountPromotion(routerGroup, r)
 	mountComment(routerGroup, r)
 	mountCart(routerGroup, r)
+	mountWishlist(routerGroup, r)
+	mountReadingList(routerGroup, r)
 	mountCheckout(routerGroup, r)
+	mountPreorder(routerGroup, r)
 	mountPayment(routerGroup, r)
+	mountNotification(routerGroup, r)
 }
 
 func (r *Rest) Start(port string) error {
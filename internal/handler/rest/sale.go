@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetBookSales(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	sales, err := r.service.SaleService.GetBookSales(bookId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", sales)
+	return nil
+}
+
+func (r *Rest) CreateSale(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	adminId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	var create model.CreateSale
+	if err := ctx.BodyParser(&create); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(create); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.SaleService.CreateSale(bookId, adminId, create); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusCreated, "success", nil)
+	return nil
+}
+
+func (r *Rest) EndSale(ctx *fiber.Ctx) error {
+	saleId, err := uuid.Parse(ctx.Params("saleId"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.SaleService.EndSale(saleId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) GetPriceHistory(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	req := model.PriceHistoryReq{
+		Days: ctx.QueryInt("days", 30),
+	}
+
+	if formatId := ctx.Query("format_id"); formatId != "" {
+		req.FormatId, err = uuid.Parse(formatId)
+		if err != nil {
+			return &response.BadRequest
+		}
+	}
+
+	if err := r.validator.Struct(req); err != nil {
+		return &response.BadRequest
+	}
+
+	history, err := r.service.SaleService.GetPriceHistory(bookId, req)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", history)
+	return nil
+}
@@ -0,0 +1,128 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetAllSeries(ctx *fiber.Ctx) error {
+	series, err := r.service.SeriesService.GetAllSeries()
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", series)
+	return nil
+}
+
+func (r *Rest) GetSeries(ctx *fiber.Ctx) error {
+	seriesId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	series, err := r.service.SeriesService.GetSeries(seriesId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", series)
+	return nil
+}
+
+func (r *Rest) CreateSeries(ctx *fiber.Ctx) error {
+	var create model.CreateSeries
+	if err := ctx.BodyParser(&create); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(create); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.SeriesService.CreateSeries(create); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) EditSeries(ctx *fiber.Ctx) error {
+	var edit model.EditSeries
+	if err := ctx.BodyParser(&edit); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(edit); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.SeriesService.EditSeries(edit); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) DeleteSeries(ctx *fiber.Ctx) error {
+	seriesId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := r.service.SeriesService.DeleteSeries(seriesId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) SetSeriesBooks(ctx *fiber.Ctx) error {
+	seriesId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var set model.SetSeriesBooks
+	if err := ctx.BodyParser(&set); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(set); err != nil {
+		return &response.BadRequest
+	}
+
+	if err := r.service.SeriesService.SetSeriesBooks(seriesId, set); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) AddSeriesToCart(ctx *fiber.Ctx) error {
+	seriesId, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	result, err := r.service.SeriesService.AddSeriesToCart(seriesId, userId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", result)
+	return nil
+}
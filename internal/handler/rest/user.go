@@ -31,8 +31,12 @@ func (r *Rest) GetUserProfile(ctx *fiber.Ctx) (err error) {
 		return err
 	}
 
-	var profile model.Profile
-	if err := r.service.UserService.GetProfile(&profile, userId); err != nil {
+	var req model.PublicProfileReq
+	req.Page = ctx.QueryInt("page", 1)
+	req.PageSize = ctx.QueryInt("size", 9)
+
+	profile, err := r.service.UserService.GetPublicProfile(userId, req)
+	if err != nil {
 		return err
 	}
 
@@ -117,7 +121,12 @@ func (r *Rest) UploadProfilePicture(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	url, err := r.service.UserService.UploadProfilePicture(file)
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	url, err := r.service.UserService.UploadProfilePicture(file, userId)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func (r *Rest) GetWishlist(ctx *fiber.Ctx) error {
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	wishlist, err := r.service.WishlistService.GetWishlist(userId)
+	if err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", wishlist)
+	return nil
+}
+
+func (r *Rest) AddToWishlist(ctx *fiber.Ctx) error {
+	var add model.AddToWishlist
+	if err := ctx.BodyParser(&add); err != nil {
+		return err
+	}
+
+	if err := r.validator.Struct(add); err != nil {
+		return &response.BadRequest
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.WishlistService.AddToWishlist(userId, add.BookId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusCreated, "success", nil)
+	return nil
+}
+
+func (r *Rest) RemoveFromWishlist(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("bookId"))
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	if err := r.service.WishlistService.RemoveFromWishlist(userId, bookId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
+
+func (r *Rest) MoveWishlistToCart(ctx *fiber.Ctx) error {
+	bookId, err := uuid.Parse(ctx.Params("bookId"))
+	if err != nil {
+		return err
+	}
+
+	userId, ok := ctx.Locals("userId").(uuid.UUID)
+	if !ok {
+		return &response.Unauthorized
+	}
+
+	formatId := uuid.Nil
+	if param := ctx.Query("format_id"); param != "" {
+		if formatId, err = uuid.Parse(param); err != nil {
+			return err
+		}
+	}
+
+	if err := r.service.WishlistService.MoveToCart(userId, bookId, formatId); err != nil {
+		return err
+	}
+
+	response.Success(ctx, http.StatusOK, "success", nil)
+	return nil
+}
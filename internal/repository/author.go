@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IAuthorRepository interface {
+	GetAuthors(authors *[]entity.Author, page, pageSize int, search string) error
+	GetAuthor(author *entity.Author, authorId uuid.UUID) error
+	GetAuthorByName(author *entity.Author, name string) error
+	CreateAuthor(author *entity.Author) error
+	EditAuthor(author *entity.Author) error
+	DeleteAuthor(authorId uuid.UUID) error
+	GetAuthorBooks(books *[]model.AuthorBook, authorId uuid.UUID) error
+	GetBookAuthors(authors *[]model.BookAuthorResponse, bookId uuid.UUID) error
+	SetBookAuthors(bookId uuid.UUID, bookAuthors []entity.BookAuthor) error
+}
+
+type AuthorRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuthorRepository(db *sqlx.DB) IAuthorRepository {
+	return &AuthorRepository{db}
+}
+
+// books.author is kept as the display byline (and is what search indexes),
+// rebuilt from the ordered "author" role entries whenever they change
+const refreshBylines = `
+	UPDATE books SET author = COALESCE((
+		SELECT string_agg(authors.name, ', ' ORDER BY book_authors.position)
+		FROM book_authors
+		INNER JOIN authors ON authors.id = book_authors.author_id
+		WHERE book_authors.book_id = books.id AND book_authors.role = 'author'
+	), books.author)
+	WHERE ` + activeBook + ` AND `
+
+func (r *AuthorRepository) GetAuthors(authors *[]entity.Author, page, pageSize int, search string) error {
+	query, args := newQuery("authors", "*").
+		Where("name ILIKE ?", "%"+search+"%").
+		OrderBy("name ASC").
+		Paginate(page, pageSize).
+		Build()
+
+	*authors = []entity.Author{}
+	return r.db.Select(authors, query, args...)
+}
+
+func (r *AuthorRepository) GetAuthor(author *entity.Author, authorId uuid.UUID) error {
+	query := `SELECT * FROM authors WHERE id = $1`
+	err := r.db.Get(author, query, authorId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.AuthorNotFound
+	}
+	return err
+}
+
+func (r *AuthorRepository) GetAuthorByName(author *entity.Author, name string) error {
+	query := `SELECT * FROM authors WHERE LOWER(name) = LOWER($1) LIMIT 1`
+	err := r.db.Get(author, query, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.AuthorNotFound
+	}
+	return err
+}
+
+func (r *AuthorRepository) CreateAuthor(author *entity.Author) error {
+	query := `INSERT INTO authors (id, name, bio, photo) VALUES (:id, :name, :bio, :photo)`
+	_, err := r.db.NamedExec(query, author)
+	return authorError(err)
+}
+
+func (r *AuthorRepository) EditAuthor(author *entity.Author) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE authors SET name = :name, bio = :bio, photo = :photo WHERE id = :id`
+	if _, err := tx.NamedExec(query, author); err != nil {
+		return authorError(err)
+	}
+
+	if _, err := tx.Exec(refreshBylines+`books.id IN (SELECT book_id FROM book_authors WHERE author_id = $1)`, author.Id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AuthorRepository) DeleteAuthor(authorId uuid.UUID) error {
+	query := `DELETE FROM authors WHERE id = $1`
+	result, err := r.db.Exec(query, authorId)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return &response.AuthorHasBooks
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.AuthorNotFound
+	}
+
+	return nil
+}
+
+func (r *AuthorRepository) GetAuthorBooks(books *[]model.AuthorBook, authorId uuid.UUID) error {
+	query := `
+		SELECT ` + bookColumns + `, book_authors.role
+		FROM books
+		INNER JOIN book_authors ON book_authors.book_id = books.id
+		WHERE book_authors.author_id = $1 AND ` + activeBook + `
+		ORDER BY books.release_date DESC
+	`
+	*books = []model.AuthorBook{}
+	return r.db.Select(books, query, authorId)
+}
+
+func (r *AuthorRepository) GetBookAuthors(authors *[]model.BookAuthorResponse, bookId uuid.UUID) error {
+	query := `
+		SELECT authors.id, authors.name, book_authors.role, book_authors.position
+		FROM book_authors
+		INNER JOIN authors ON authors.id = book_authors.author_id
+		WHERE book_authors.book_id = $1
+		ORDER BY book_authors.position ASC
+	`
+	*authors = []model.BookAuthorResponse{}
+	return r.db.Select(authors, query, bookId)
+}
+
+func (r *AuthorRepository) SetBookAuthors(bookId uuid.UUID, bookAuthors []entity.BookAuthor) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setBookAuthors(tx, bookId, bookAuthors); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func setBookAuthors(tx *sqlx.Tx, bookId uuid.UUID, bookAuthors []entity.BookAuthor) error {
+	if _, err := tx.Exec(`DELETE FROM book_authors WHERE book_id = $1`, bookId); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO book_authors (book_id, author_id, role, position)
+		VALUES (:book_id, :author_id, :role, :position)
+		ON CONFLICT DO NOTHING
+	`
+	for _, bookAuthor := range bookAuthors {
+		if _, err := tx.NamedExec(query, bookAuthor); err != nil {
+			return authorError(err)
+		}
+	}
+
+	_, err := tx.Exec(refreshBylines+`books.id = $1`, bookId)
+	return err
+}
+
+func authorError(err error) error {
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return &response.DuplicateAuthor
+		case "23503":
+			return &response.AuthorNotFound
+		}
+	}
+	return err
+}
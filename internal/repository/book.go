@@ -7,25 +7,48 @@ import (
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
 type IBookRepository interface {
 	GetBooks(books *[]entity.Book, page, pageSize int) error
-	SearchBooks(books *[]entity.Book, page, pageSize int, searchQuery string) error
+	SearchBooks(results *[]model.BookSearchResult, search model.BookSearch) error
+	FuzzySearchBooks(results *[]model.BookSearchResult, search model.BookSearch) error
+	CountBooks(search model.BookSearch) (int, error)
+	CountFuzzyBooks(search model.BookSearch) (int, error)
+	GetBookFacets(facets *model.BookFacets, search model.BookSearch) error
+	GetSuggestions(suggestions *[]model.BookSuggestion, prefix string, limit int) error
 	GetBook(book *entity.Book, bookId uuid.UUID) error
+	GetBookWithDeleted(book *entity.Book, bookId uuid.UUID) error
+	GetDeletedBooks(books *[]entity.Book, page, pageSize int) error
 	CreateBook(book *entity.Book) error
-	DeleteBook(bookId uuid.UUID) error
-	EditBook(edit *model.EditBook) error
+	DeleteBook(bookId uuid.UUID, deletedBy uuid.UUID) error
+	RestoreBook(bookId uuid.UUID) error
+	SetBookFile(bookId uuid.UUID, path string) error
+	EditBook(edit *model.EditBook, bookAuthors []entity.BookAuthor, revision *entity.BookRevision) error
 }
 
+// books carries a search_vector column that entity.Book does not map, so never select * from it
+const bookColumns = `books.id, books.title, books.description, books.introduction, books.image, books.file, books.author, books.release_date, books.price, books.publisher_id, books.deleted_at, books.deleted_by`
+
+// soft-delete scopes; storefront queries only ever see activeBook rows
+const (
+	activeBook  = `books.deleted_at IS NULL`
+	deletedBook = `books.deleted_at IS NOT NULL`
+)
+
 type BookRepository struct {
-	db *sqlx.DB
+	db  *sqlx.DB
+	rdb *redis.Client
 }
 
-func NewBookRepository(db *sqlx.DB) IBookRepository {
-	return &BookRepository{db}
+func NewBookRepository(db *sqlx.DB, rdb *redis.Client) IBookRepository {
+	return &BookRepository{
+		db:  db,
+		rdb: rdb,
+	}
 }
 
 func (r *BookRepository) GetBooks(books *[]entity.Book, page, pageSize int) error {
@@ -38,7 +61,7 @@ func (r *BookRepository) GetBooks(books *[]entity.Book, page, pageSize int) erro
 	}
 
 	offset := (page - 1) * pageSize
-	query := `SELECT * FROM books WHERE author != 'This book is deleted' ORDER BY release_date DESC LIMIT $1 OFFSET $2`
+	query := `SELECT ` + bookColumns + ` FROM books WHERE ` + activeBook + ` ORDER BY release_date DESC LIMIT $1 OFFSET $2`
 	err := r.db.Select(books, query, pageSize, offset)
 	if errors.Is(err, sql.ErrNoRows) {
 		return &response.BookNotFound
@@ -46,35 +69,18 @@ func (r *BookRepository) GetBooks(books *[]entity.Book, page, pageSize int) erro
 	return err
 }
 
-func (r *BookRepository) SearchBooks(books *[]entity.Book, page, pageSize int, searchQuery string) error {
-	if page < 1 {
-		page = 1
-	}
-
-	if pageSize < 1 {
-		pageSize = 10
-	}
-
-	offset := (page - 1) * pageSize
-	query := `
-		SELECT * FROM books 
-		WHERE 
-			(title ILIKE $1 OR 
-			author ILIKE $1 OR 
-			description ILIKE $1) AND 
-			author != 'This book is deleted' 
-		ORDER BY release_date DESC 
-		LIMIT $2 OFFSET $3`
-	searchPattern := "%" + searchQuery + "%"
-	err := r.db.Select(books, query, searchPattern, pageSize, offset)
+func (r *BookRepository) GetBook(book *entity.Book, bookId uuid.UUID) error {
+	query := `SELECT ` + bookColumns + ` FROM books WHERE id = $1 AND ` + activeBook + ` LIMIT 1`
+	err := r.db.Get(book, query, bookId)
 	if errors.Is(err, sql.ErrNoRows) {
 		return &response.BookNotFound
 	}
 	return err
 }
 
-func (r *BookRepository) GetBook(book *entity.Book, bookId uuid.UUID) error {
-	query := `SELECT * FROM books WHERE id = $1 AND author != 'This book is deleted' LIMIT 1`
+// GetBookWithDeleted also resolves trashed books, for purchase history
+func (r *BookRepository) GetBookWithDeleted(book *entity.Book, bookId uuid.UUID) error {
+	query := `SELECT ` + bookColumns + ` FROM books WHERE id = $1 LIMIT 1`
 	err := r.db.Get(book, query, bookId)
 	if errors.Is(err, sql.ErrNoRows) {
 		return &response.BookNotFound
@@ -82,23 +88,49 @@ func (r *BookRepository) GetBook(book *entity.Book, bookId uuid.UUID) error {
 	return err
 }
 
+func (r *BookRepository) GetDeletedBooks(books *[]entity.Book, page, pageSize int) error {
+	query, args := newQuery("books", bookColumns).
+		Where(deletedBook).
+		OrderBy("books.deleted_at DESC").
+		Paginate(page, pageSize).
+		Build()
+
+	*books = []entity.Book{}
+	return r.db.Select(books, query, args...)
+}
+
 func (r *BookRepository) CreateBook(book *entity.Book) error {
-	query := `INSERT INTO books (id, title, description, author, release_date, price, introduction, image, file) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.db.Exec(query, book.Id, book.Title, book.Description, book.Author, book.ReleaseDate, book.Price, book.Introduction, book.Image, book.File)
-	return err
+	query := `INSERT INTO books (id, title, description, author, release_date, price, introduction, image, file, publisher_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	if _, err := r.db.Exec(query, book.Id, book.Title, book.Description, book.Author, book.ReleaseDate, book.Price, book.Introduction, book.Image, book.File, nullableUUID(book.PublisherId)); err != nil {
+		return err
+	}
+
+	r.invalidateSuggestions()
+	return nil
 }
 
-func (r *BookRepository) DeleteBook(bookId uuid.UUID) error {
-	query := `
-		UPDATE books 
-		SET title = 'Deleted Book',
-		    description = 'This book is deleted',
-		    introduction = 'This book is deleted',
-		    image = 'This book is deleted',
-		    author = 'This book is deleted' 
-		WHERE id = $1
-	`
+func (r *BookRepository) DeleteBook(bookId uuid.UUID, deletedBy uuid.UUID) error {
+	query := `UPDATE books SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND ` + activeBook
+	result, err := r.db.Exec(query, bookId, nullableUUID(deletedBy))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
 
+	if rowsAffected == 0 {
+		return &response.BookNotFound
+	}
+
+	r.invalidateSuggestions()
+	return nil
+}
+
+func (r *BookRepository) RestoreBook(bookId uuid.UUID) error {
+	query := `UPDATE books SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND ` + deletedBook
 	result, err := r.db.Exec(query, bookId)
 	if err != nil {
 		return err
@@ -113,10 +145,25 @@ func (r *BookRepository) DeleteBook(bookId uuid.UUID) error {
 		return &response.BookNotFound
 	}
 
+	r.invalidateSuggestions()
 	return nil
 }
 
-func (r *BookRepository) EditBook(edit *model.EditBook) error {
+func (r *BookRepository) SetBookFile(bookId uuid.UUID, path string) error {
+	query := `UPDATE books SET file = $1 WHERE id = $2 AND ` + activeBook
+	_, err := r.db.Exec(query, path, bookId)
+	return err
+}
+
+// EditBook updates a book together with its categories, credits and publisher
+// when given, and records the revision in the same transaction
+func (r *BookRepository) EditBook(edit *model.EditBook, bookAuthors []entity.BookAuthor, revision *entity.BookRevision) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE books 
 		SET title = :title,
@@ -126,9 +173,55 @@ func (r *BookRepository) EditBook(edit *model.EditBook) error {
 		    author = :author,
 		    release_date = :release_date,
 		    price = :price
-		WHERE id = :id AND author != 'This book is deleted'
+		WHERE id = :id AND ` + activeBook + `
 	`
+	if _, err := tx.NamedExec(query, edit); err != nil {
+		return err
+	}
 
-	_, err := r.db.NamedExec(query, edit)
-	return err
+	// the book price is the list price of its ebook
+	query = `UPDATE book_formats SET price = :price WHERE book_id = :id AND format = 'ebook'`
+	if _, err := tx.NamedExec(query, edit); err != nil {
+		return err
+	}
+
+	if err := recordPrices(tx, "book_formats.book_id = $1", edit.Id); err != nil {
+		return err
+	}
+
+	if edit.CategoryIds != nil {
+		if err := setBookCategories(tx, edit.Id, edit.CategoryIds); err != nil {
+			return err
+		}
+	}
+
+	// runs after the byline update above, so credits win over a typed byline
+	if bookAuthors != nil {
+		if err := setBookAuthors(tx, edit.Id, bookAuthors); err != nil {
+			return err
+		}
+	}
+
+	if edit.PublisherId != nil {
+		if err := setBookPublisher(tx, edit.Id, *edit.PublisherId); err != nil {
+			return err
+		}
+	}
+
+	if revision != nil {
+		query := `
+			INSERT INTO book_revisions (id, book_id, editor_id, changes, reverts_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		if _, err := tx.Exec(query, revision.Id, revision.BookId, nullableUUID(revision.EditorId), revision.Changes, nullableUUID(revision.RevertsId), revision.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.invalidateSuggestions()
+	return nil
 }
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IBookFormatRepository interface {
+	GetBookFormats(formats *[]entity.BookFormat, bookId uuid.UUID) error
+	GetFormat(format *entity.BookFormat, formatId uuid.UUID) error
+	GetBookFormat(format *entity.BookFormat, bookId uuid.UUID, name string) error
+	CreateFormat(format *entity.BookFormat) error
+	EditFormat(format *entity.BookFormat) error
+	DeleteFormat(formatId uuid.UUID) error
+}
+
+type BookFormatRepository struct {
+	db *sqlx.DB
+}
+
+func NewBookFormatRepository(db *sqlx.DB) IBookFormatRepository {
+	return &BookFormatRepository{db}
+}
+
+func (r *BookFormatRepository) GetBookFormats(formats *[]entity.BookFormat, bookId uuid.UUID) error {
+	query := `SELECT * FROM book_formats WHERE book_id = $1 ORDER BY price, format`
+	*formats = []entity.BookFormat{}
+	return r.db.Select(formats, query, bookId)
+}
+
+func (r *BookFormatRepository) GetFormat(format *entity.BookFormat, formatId uuid.UUID) error {
+	query := `SELECT * FROM book_formats WHERE id = $1`
+	err := r.db.Get(format, query, formatId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.FormatNotFound
+	}
+	return err
+}
+
+func (r *BookFormatRepository) GetBookFormat(format *entity.BookFormat, bookId uuid.UUID, name string) error {
+	query := `SELECT * FROM book_formats WHERE book_id = $1 AND format = $2`
+	err := r.db.Get(format, query, bookId, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.FormatNotFound
+	}
+	return err
+}
+
+func (r *BookFormatRepository) CreateFormat(format *entity.BookFormat) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO book_formats (id, book_id, format, sku, price, created_at)
+		VALUES (:id, :book_id, :format, :sku, :price, :created_at)
+	`
+	if _, err := tx.NamedExec(query, format); err != nil {
+		return formatError(err)
+	}
+
+	if err := recordPrices(tx, "book_formats.id = $1", format.Id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *BookFormatRepository) EditFormat(format *entity.BookFormat) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE book_formats SET sku = :sku, price = :price WHERE id = :id`
+	result, err := tx.NamedExec(query, format)
+	if err != nil {
+		return formatError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.FormatNotFound
+	}
+
+	if err := recordPrices(tx, "book_formats.id = $1", format.Id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *BookFormatRepository) DeleteFormat(formatId uuid.UUID) error {
+	query := `DELETE FROM book_formats WHERE id = $1`
+	result, err := r.db.Exec(query, formatId)
+	if err != nil {
+		return formatError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.FormatNotFound
+	}
+
+	return nil
+}
+
+func formatError(err error) error {
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return &response.DuplicateFormat
+		case "23503":
+			return &response.FormatInUse
+		}
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type IBookRevisionRepository interface {
+	GetRevisions(revisions *[]model.BookRevisionResponse, bookId uuid.UUID, field string) error
+	GetRevision(revision *entity.BookRevision, revisionId uuid.UUID) error
+}
+
+type BookRevisionRepository struct {
+	db *sqlx.DB
+}
+
+func NewBookRevisionRepository(db *sqlx.DB) IBookRevisionRepository {
+	return &BookRevisionRepository{db}
+}
+
+func (r *BookRevisionRepository) GetRevisions(revisions *[]model.BookRevisionResponse, bookId uuid.UUID, field string) error {
+	b := newQuery("book_revisions",
+		"book_revisions.id", "book_revisions.book_id", "book_revisions.editor_id",
+		"users.username AS editor_username", "book_revisions.changes",
+		"book_revisions.reverts_id", "book_revisions.created_at",
+	).
+		Join("users", "LEFT JOIN users ON users.id = book_revisions.editor_id").
+		Where("book_revisions.book_id = ?", bookId)
+
+	if field != "" {
+		// the jsonb ? operator would clash with the builder's placeholders
+		b.Where("jsonb_exists(book_revisions.changes, ?)", field)
+	}
+
+	query, args := b.OrderBy("book_revisions.created_at DESC").Build()
+
+	*revisions = []model.BookRevisionResponse{}
+	return r.db.Select(revisions, query, args...)
+}
+
+func (r *BookRevisionRepository) GetRevision(revision *entity.BookRevision, revisionId uuid.UUID) error {
+	query := `SELECT * FROM book_revisions WHERE id = $1`
+	err := r.db.Get(revision, query, revisionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.RevisionNotFound
+	}
+	return err
+}
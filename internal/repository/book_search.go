@@ -0,0 +1,374 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/google/uuid"
+)
+
+const suggestionCacheExpiration = 5 * time.Minute
+
+// bumped on every book write so cached suggestions are never served stale
+const suggestionVersionKey = "suggest:version"
+
+const (
+	facetAuthor = "author"
+	facetPrice  = "price"
+	facetRating = "rating"
+)
+
+const priceRangeExpression = `
+	CASE
+		WHEN effective.price < 100000 THEN '0-100000'
+		WHEN effective.price < 150000 THEN '100000-150000'
+		WHEN effective.price < 200000 THEN '150000-200000'
+		WHEN effective.price < 250000 THEN '200000-250000'
+		ELSE '250000+'
+	END`
+
+// weight of the catalog-wide mean in the top rated score, in ratings
+const ratingPriorWeight = 10
+
+func joinRatings(b *queryBuilder) {
+	b.Join("ratings", `LEFT JOIN book_ratings ratings ON ratings.book_id = books.id AND ratings.rating_count > 0`)
+}
+
+// joinRatingPrior exposes the catalog-wide mean rating as rating_prior.mean
+func joinRatingPrior(b *queryBuilder) {
+	b.Join("rating_prior", `
+		CROSS JOIN (
+			SELECT COALESCE(SUM(rating_sum)::numeric / NULLIF(SUM(rating_count), 0), 0) AS mean
+			FROM book_ratings
+		) rating_prior`)
+}
+
+// escapeHtml escapes the markup in a text expression, so the <mark> tags that
+// ts_headline adds are the only markup in a snippet
+func escapeHtml(expression string) string {
+	return `replace(replace(replace(replace(` + expression + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+}
+
+// joinEffectivePrice exposes the price a book sells for right now as effective.price
+func joinEffectivePrice(b *queryBuilder) {
+	b.Join("effective", `CROSS JOIN LATERAL (SELECT `+effectiveBookPrice+` AS price) effective`)
+}
+
+// joinAuthors adds a row per credited author, as authors, for the author facet
+func joinAuthors(b *queryBuilder) {
+	b.Join("book_authors", `INNER JOIN book_authors ON book_authors.book_id = books.id AND book_authors.role = 'author'`)
+	b.Join("authors", `INNER JOIN authors ON authors.id = book_authors.author_id`)
+}
+
+func joinSales(b *queryBuilder) {
+	b.Join("sales", `
+		LEFT JOIN (
+			SELECT carts.book_id, SUM(carts.amount) AS sold
+			FROM carts
+			INNER JOIN payments ON payments.checkout_id = carts.checkout_id
+			WHERE `+paidPayment+`
+			GROUP BY carts.book_id
+		) sales ON sales.book_id = books.id`)
+}
+
+// applies every filter in search except the facet named by except, so a facet's
+// counts are not narrowed by its own selection
+func filterBooks(b *queryBuilder, search model.BookSearch, except string) *queryBuilder {
+	b.Where(activeBook)
+
+	if search.SearchParam != "" {
+		b.Join("query", "CROSS JOIN to_tsquery('english', ?) query", buildTsQuery(search.SearchParam))
+		b.Where("books.search_vector @@ query")
+	}
+
+	if search.Author != "" && except != facetAuthor {
+		b.Where(`EXISTS (
+			SELECT 1 FROM book_authors
+			INNER JOIN authors ON authors.id = book_authors.author_id
+			WHERE book_authors.book_id = books.id AND book_authors.role = 'author' AND authors.name = ?
+		)`, search.Author)
+	}
+
+	if except != facetPrice {
+		if search.MinPrice > 0 {
+			joinEffectivePrice(b)
+			b.Where("effective.price >= ?", search.MinPrice)
+		}
+
+		if search.MaxPrice > 0 {
+			joinEffectivePrice(b)
+			b.Where("effective.price <= ?", search.MaxPrice)
+		}
+	}
+
+	if search.CategoryId != uuid.Nil {
+		b.Where(`EXISTS (
+			`+categorySubtrees+`
+			SELECT 1 FROM book_categories
+			INNER JOIN subtrees ON subtrees.id = book_categories.category_id
+			WHERE book_categories.book_id = books.id AND subtrees.root_id = ?
+		)`, search.CategoryId)
+	}
+
+	if search.ReleasedFrom != "" {
+		b.Where("books.release_date >= ?", search.ReleasedFrom)
+	}
+
+	if search.ReleasedTo != "" {
+		b.Where("books.release_date <= ?", search.ReleasedTo)
+	}
+
+	if search.MinRating > 0 && except != facetRating {
+		joinRatings(b)
+		b.Where("ratings.average >= ?", search.MinRating)
+	}
+
+	return b
+}
+
+func sortBooks(b *queryBuilder, sort string) {
+	switch sort {
+	case model.SortRelevance:
+		b.OrderBy("rank DESC")
+	case model.SortPriceAsc:
+		joinEffectivePrice(b)
+		b.OrderBy("effective.price ASC")
+	case model.SortPriceDesc:
+		joinEffectivePrice(b)
+		b.OrderBy("effective.price DESC")
+	case model.SortTitle:
+		b.OrderBy("books.title ASC")
+	case model.SortRating:
+		joinRatings(b)
+		b.OrderBy("ratings.average DESC NULLS LAST")
+	case model.SortTopRated:
+		// bayesian average: few ratings pull a book toward the catalog mean
+		joinRatings(b)
+		joinRatingPrior(b)
+		b.OrderBy("(COALESCE(ratings.rating_sum, 0) + ? * rating_prior.mean) / (COALESCE(ratings.rating_count, 0) + ?) DESC", ratingPriorWeight, ratingPriorWeight)
+	case model.SortBestselling:
+		joinSales(b)
+		b.OrderBy("sales.sold DESC NULLS LAST")
+	}
+
+	b.OrderBy("books.release_date DESC").OrderBy("books.id")
+}
+
+func (r *BookRepository) SearchBooks(results *[]model.BookSearchResult, search model.BookSearch) error {
+	if search.SearchParam != "" && buildTsQuery(search.SearchParam) == "" {
+		*results = []model.BookSearchResult{}
+		return nil
+	}
+
+	b := newQuery("books", bookColumns)
+	filterBooks(b, search, "")
+
+	if search.SearchParam != "" {
+		b.Columns(
+			"ts_rank(books.search_vector, query) AS rank",
+			`ts_headline('english', `+escapeHtml("books.description || ' ' || books.introduction")+`, query,
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=25, MinWords=10') AS headline`,
+		)
+	} else {
+		b.Columns("0 AS rank", "'' AS headline")
+	}
+
+	sort := search.Sort
+	if sort == "" || (sort == model.SortRelevance && search.SearchParam == "") {
+		sort = model.SortNewest
+		if search.SearchParam != "" {
+			sort = model.SortRelevance
+		}
+	}
+	sortBooks(b, sort)
+
+	query, args := b.Paginate(search.Page, search.PageSize).Build()
+	*results = []model.BookSearchResult{}
+	return r.db.Select(results, query, args...)
+}
+
+// typo tolerant fallback for when the full text search finds nothing
+func (r *BookRepository) FuzzySearchBooks(results *[]model.BookSearchResult, search model.BookSearch) error {
+	b := newQuery("books", bookColumns).
+		Column(`GREATEST(similarity(books.title, ?), similarity(books.author, ?),
+			word_similarity(?, books.title), word_similarity(?, books.author)) AS rank`,
+			search.SearchParam, search.SearchParam, search.SearchParam, search.SearchParam).
+		Columns("'' AS headline")
+	filterFuzzyBooks(b, search)
+	b.OrderBy("rank DESC")
+
+	query, args := b.Paginate(search.Page, search.PageSize).Build()
+	*results = []model.BookSearchResult{}
+	return r.db.Select(results, query, args...)
+}
+
+func (r *BookRepository) CountFuzzyBooks(search model.BookSearch) (int, error) {
+	var total int
+	query, args := filterFuzzyBooks(newQuery("books", "COUNT(*)"), search).Build()
+	err := r.db.Get(&total, query, args...)
+	return total, err
+}
+
+// matches titles and authors by trigram similarity instead of the tsquery
+func filterFuzzyBooks(b *queryBuilder, search model.BookSearch) *queryBuilder {
+	fuzzy := search
+	fuzzy.SearchParam = ""
+
+	filterBooks(b, fuzzy, "")
+	return b.Where("books.title % ? OR books.author % ? OR ? <% books.title OR ? <% books.author",
+		search.SearchParam, search.SearchParam, search.SearchParam, search.SearchParam)
+}
+
+func (r *BookRepository) CountBooks(search model.BookSearch) (int, error) {
+	if search.SearchParam != "" && buildTsQuery(search.SearchParam) == "" {
+		return 0, nil
+	}
+
+	var total int
+	query, args := filterBooks(newQuery("books", "COUNT(*)"), search, "").Build()
+	err := r.db.Get(&total, query, args...)
+	return total, err
+}
+
+func (r *BookRepository) GetBookFacets(facets *model.BookFacets, search model.BookSearch) error {
+	*facets = model.BookFacets{
+		Authors:     []model.FacetCount{},
+		PriceRanges: []model.FacetCount{},
+		Ratings:     []model.FacetCount{},
+	}
+
+	if search.SearchParam != "" && buildTsQuery(search.SearchParam) == "" {
+		return nil
+	}
+
+	authors := newQuery("books", "authors.name AS value", "COUNT(*) AS count")
+	joinAuthors(authors)
+	filterBooks(authors, search, facetAuthor).
+		GroupBy("authors.name").
+		OrderBy("count DESC").
+		OrderBy("value ASC")
+	query, args := authors.Build()
+	if err := r.db.Select(&facets.Authors, query, args...); err != nil {
+		return err
+	}
+
+	prices := newQuery("books", priceRangeExpression+" AS value", "COUNT(*) AS count")
+	joinEffectivePrice(prices)
+	filterBooks(prices, search, facetPrice).
+		GroupBy("value").
+		OrderBy("MIN(effective.price) ASC")
+	query, args = prices.Build()
+	if err := r.db.Select(&facets.PriceRanges, query, args...); err != nil {
+		return err
+	}
+
+	ratings := newQuery("books", "FLOOR(ratings.average)::int::text AS value", "COUNT(*) AS count")
+	joinRatings(ratings)
+	filterBooks(ratings, search, facetRating).
+		Where("ratings.average IS NOT NULL").
+		GroupBy("value").
+		OrderBy("value DESC")
+	query, args = ratings.Build()
+	return r.db.Select(&facets.Ratings, query, args...)
+}
+
+func (r *BookRepository) GetSuggestions(suggestions *[]model.BookSuggestion, prefix string, limit int) error {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		*suggestions = []model.BookSuggestion{}
+		return nil
+	}
+
+	version, _ := r.rdb.Get(context.Background(), suggestionVersionKey).Result()
+	key := "suggest:" + version + ":" + strconv.Itoa(limit) + ":" + prefix
+	if cached, err := r.rdb.Get(context.Background(), key).Bytes(); err == nil {
+		if err := json.Unmarshal(cached, suggestions); err == nil {
+			return nil
+		}
+	}
+
+	query := `
+		SELECT value, kind, book_id FROM (
+			SELECT title AS value, 'title' AS kind, id AS book_id,
+				word_similarity($1, title) AS score, title ILIKE $2 AS is_prefix
+			FROM books
+			WHERE (title ILIKE $2 OR $1 <% title) AND ` + activeBook + `
+			UNION ALL
+			SELECT authors.name AS value, 'author' AS kind, NULL AS book_id,
+				word_similarity($1, authors.name) AS score, authors.name ILIKE $2 AS is_prefix
+			FROM authors
+			WHERE (authors.name ILIKE $2 OR $1 <% authors.name) AND EXISTS (
+				SELECT 1 FROM book_authors
+				INNER JOIN books ON books.id = book_authors.book_id
+				WHERE book_authors.author_id = authors.id AND book_authors.role = 'author' AND ` + activeBook + `
+			)
+		) suggestions
+		ORDER BY is_prefix DESC, score DESC, value ASC
+		LIMIT $3`
+	*suggestions = []model.BookSuggestion{}
+	likePattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+	if err := r.db.Select(suggestions, query, prefix, likePattern, limit); err != nil {
+		return err
+	}
+
+	if encoded, err := json.Marshal(suggestions); err == nil {
+		r.rdb.Set(context.Background(), key, encoded, suggestionCacheExpiration)
+	}
+
+	return nil
+}
+
+// invalidateSuggestions moves the suggestion cache to a new version; the old
+// entries are never read again and expire on their own
+func (r *BookRepository) invalidateSuggestions() {
+	r.rdb.Incr(context.Background(), suggestionVersionKey)
+}
+
+// turns user input into a to_tsquery expression: "quoted words" become phrases,
+// a trailing * makes a prefix match and everything else is ANDed together
+func buildTsQuery(search string) string {
+	var terms []string
+	for i, part := range strings.Split(search, `"`) {
+		if i%2 == 1 {
+			var words []string
+			for _, word := range strings.Fields(part) {
+				if word = sanitizeTsWord(word); word != "" {
+					words = append(words, word)
+				}
+			}
+
+			if len(words) > 0 {
+				terms = append(terms, "("+strings.Join(words, " <-> ")+")")
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			prefix := strings.HasSuffix(word, "*")
+			if word = sanitizeTsWord(word); word == "" {
+				continue
+			}
+
+			if prefix {
+				word += ":*"
+			}
+			terms = append(terms, word)
+		}
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+func sanitizeTsWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, word)
+}
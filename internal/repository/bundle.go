@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IBundleRepository interface {
+	GetBundles(bundles *[]entity.Bundle) error
+	GetBundle(bundle *entity.Bundle, bundleId uuid.UUID) error
+	CreateBundle(bundle *entity.Bundle, books []entity.BundleBook) error
+	EditBundle(bundle *entity.Bundle) error
+	DeleteBundle(bundleId uuid.UUID) error
+	GetBundleBooks(books *[]model.BundleBookRow, bundleIds []uuid.UUID) error
+}
+
+type BundleRepository struct {
+	db *sqlx.DB
+}
+
+func NewBundleRepository(db *sqlx.DB) IBundleRepository {
+	return &BundleRepository{db}
+}
+
+func (r *BundleRepository) GetBundles(bundles *[]entity.Bundle) error {
+	query := `SELECT * FROM bundles WHERE deleted_at IS NULL ORDER BY created_at DESC`
+	*bundles = []entity.Bundle{}
+	return r.db.Select(bundles, query)
+}
+
+func (r *BundleRepository) GetBundle(bundle *entity.Bundle, bundleId uuid.UUID) error {
+	query := `SELECT * FROM bundles WHERE id = $1 AND deleted_at IS NULL`
+	err := r.db.Get(bundle, query, bundleId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.BundleNotFound
+	}
+	return err
+}
+
+func (r *BundleRepository) CreateBundle(bundle *entity.Bundle, books []entity.BundleBook) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO bundles (id, name, description, price, created_at) VALUES (:id, :name, :description, :price, :created_at)`
+	if _, err := tx.NamedExec(query, bundle); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO bundle_books (bundle_id, book_id, position) VALUES (:bundle_id, :book_id, :position)`
+	for _, book := range books {
+		if _, err := tx.NamedExec(query, book); err != nil {
+			var pgErr *pq.Error
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return &response.BookNotFound
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *BundleRepository) EditBundle(bundle *entity.Bundle) error {
+	query := `UPDATE bundles SET name = :name, description = :description, price = :price WHERE id = :id AND deleted_at IS NULL`
+	_, err := r.db.NamedExec(query, bundle)
+	return err
+}
+
+// DeleteBundle retires the bundle and drops it from open carts; checked out
+// carts keep pointing at it for order history
+func (r *BundleRepository) DeleteBundle(bundleId uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE bundles SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, bundleId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.BundleNotFound
+	}
+
+	if _, err := tx.Exec(`DELETE FROM carts WHERE bundle_id = $1 AND checkout_id IS NULL`, bundleId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetBundleBooks includes trashed books so a bundle that lost one shows as unavailable
+func (r *BundleRepository) GetBundleBooks(books *[]model.BundleBookRow, bundleIds []uuid.UUID) error {
+	query := `
+		SELECT ` + bookColumns + `, bundle_books.bundle_id
+		FROM bundle_books
+		INNER JOIN books ON books.id = bundle_books.book_id
+		WHERE bundle_books.bundle_id = ANY($1)
+		ORDER BY bundle_books.bundle_id, bundle_books.position ASC
+	`
+	*books = []model.BundleBookRow{}
+	return r.db.Select(books, query, pq.Array(uuidStrings(bundleIds)))
+}
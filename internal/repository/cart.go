@@ -13,7 +13,11 @@ import (
 type ICartRepository interface {
 	GetUserCart(carts *[]entity.Cart, user *entity.User) error
 	GetCart(cart *entity.Cart, cartId uuid.UUID) error
-	AddToCart(user *entity.User, book *entity.Book, amount int) error
+	AddToCart(user *entity.User, format *entity.BookFormat, amount int) error
+	GetOpenCart(cart *entity.Cart, userId uuid.UUID, formatId uuid.UUID) error
+	AddBundleToCart(userId uuid.UUID, bundleId uuid.UUID, formats []entity.BookFormat) error
+	AddFormatsToCart(userId uuid.UUID, formats []entity.BookFormat) error
+	GetBundleCarts(carts *[]entity.Cart, userId uuid.UUID, bundleId uuid.UUID) error
 	RemoveFromCart(cartId uuid.UUID) error
 	EditCart(cart *entity.Cart, amount int) error
 	DeleteCartByBook(bookId uuid.UUID) error
@@ -49,18 +53,88 @@ func (r *CartRepository) GetCart(cart *entity.Cart, cartId uuid.UUID) error {
 	return err
 }
 
-func (r *CartRepository) AddToCart(user *entity.User, book *entity.Book, amount int) error {
+func (r *CartRepository) AddToCart(user *entity.User, format *entity.BookFormat, amount int) error {
 	if amount < 1 {
 		return errors.New("invalid amount")
 	}
 
 	var cart entity.Cart
-	if r.doesCartExist(&cart, user.Id, book.Id); cart.Amount > 0 {
-		return r.addAmount(cart.Id, amount)
+	if r.GetOpenCart(&cart, user.Id, format.Id); cart.Amount > 0 {
+		if err := r.addAmount(cart.Id, amount); err != nil {
+			return err
+		}
+		return r.recordCartEvent(user.Id, format.BookId, amount)
 	}
 
-	query := `INSERT INTO carts (id, user_id, book_id, amount, checkout_id) VALUES ($1, $2, $3, $4, NULL)`
-	_, err := r.db.Exec(query, uuid.New(), user.Id, book.Id, amount)
+	query := `INSERT INTO carts (id, user_id, book_id, format_id, amount, checkout_id) VALUES ($1, $2, $3, $4, $5, NULL)`
+	if _, err := r.db.Exec(query, uuid.New(), user.Id, format.BookId, format.Id, amount); err != nil {
+		return err
+	}
+	return r.recordCartEvent(user.Id, format.BookId, amount)
+}
+
+// AddBundleToCart puts one row per ebook in the cart, replacing any loose
+// copies of the same ebooks already there
+func (r *CartRepository) AddBundleToCart(userId uuid.UUID, bundleId uuid.UUID, formats []entity.BookFormat) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, format := range formats {
+		query := `DELETE FROM carts WHERE user_id = $1 AND format_id = $2 AND bundle_id IS NULL AND checkout_id IS NULL`
+		if _, err := tx.Exec(query, userId, format.Id); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO carts (id, user_id, book_id, format_id, amount, checkout_id, bundle_id) VALUES ($1, $2, $3, $4, 1, NULL, $5)`
+		if _, err := tx.Exec(query, uuid.New(), userId, format.BookId, format.Id, bundleId); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO cart_events (id, user_id, book_id, amount, created_at) VALUES ($1, $2, $3, 1, NOW())`
+		if _, err := tx.Exec(query, uuid.New(), userId, format.BookId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// AddFormatsToCart puts one copy of each format in the cart, all or none
+func (r *CartRepository) AddFormatsToCart(userId uuid.UUID, formats []entity.BookFormat) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, format := range formats {
+		query := `INSERT INTO carts (id, user_id, book_id, format_id, amount, checkout_id) VALUES ($1, $2, $3, $4, 1, NULL)`
+		if _, err := tx.Exec(query, uuid.New(), userId, format.BookId, format.Id); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO cart_events (id, user_id, book_id, amount, created_at) VALUES ($1, $2, $3, 1, NOW())`
+		if _, err := tx.Exec(query, uuid.New(), userId, format.BookId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *CartRepository) GetBundleCarts(carts *[]entity.Cart, userId uuid.UUID, bundleId uuid.UUID) error {
+	query := `SELECT * FROM carts WHERE user_id = $1 AND bundle_id = $2 AND checkout_id IS NULL`
+	*carts = []entity.Cart{}
+	return r.db.Select(carts, query, userId, bundleId)
+}
+
+// cart rows disappear on removal and checkout, so adds are logged separately for trending
+func (r *CartRepository) recordCartEvent(userId uuid.UUID, bookId uuid.UUID, amount int) error {
+	query := `INSERT INTO cart_events (id, user_id, book_id, amount, created_at) VALUES ($1, $2, $3, $4, NOW())`
+	_, err := r.db.Exec(query, uuid.New(), userId, bookId, amount)
 	return err
 }
 
@@ -84,8 +158,13 @@ func (r *CartRepository) EditCart(cart *entity.Cart, amount int) error {
 	return nil
 }
 
+// RemoveFromCart takes the rest of a bundle out along with any of its rows
 func (r *CartRepository) RemoveFromCart(cartId uuid.UUID) error {
-	query := `DELETE FROM carts WHERE id = $1 AND checkout_id IS NULL`
+	query := `
+		DELETE FROM carts WHERE checkout_id IS NULL AND (
+			id = $1 OR (user_id, bundle_id) = (SELECT user_id, bundle_id FROM carts WHERE id = $1)
+		)
+	`
 	result, err := r.db.Exec(query, cartId)
 
 	if err != nil {
@@ -104,9 +183,12 @@ func (r *CartRepository) RemoveFromCart(cartId uuid.UUID) error {
 	return nil
 }
 
-func (r *CartRepository) doesCartExist(cart *entity.Cart, userId uuid.UUID, bookId uuid.UUID) error {
-	query := `SELECT * FROM carts WHERE user_id = $1 AND book_id = $2 AND checkout_id IS NULL LIMIT 1`
-	err := r.db.Get(cart, query, userId, bookId)
+func (r *CartRepository) GetOpenCart(cart *entity.Cart, userId uuid.UUID, formatId uuid.UUID) error {
+	query := `SELECT * FROM carts WHERE user_id = $1 AND format_id = $2 AND checkout_id IS NULL LIMIT 1`
+	err := r.db.Get(cart, query, userId, formatId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.CartNotFound
+	}
 	return err
 }
 
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ICategoryRepository interface {
+	GetCategories(categories *[]model.CategoryResponse) error
+	GetCategory(category *entity.Category, categoryId uuid.UUID) error
+	GetSubtreeIds(categoryId uuid.UUID) ([]uuid.UUID, error)
+	HasChildren(categoryId uuid.UUID) (bool, error)
+	CreateCategory(category *entity.Category) error
+	EditCategory(category *entity.Category) error
+	DeleteCategory(categoryId uuid.UUID) error
+	GetBookCategories(categories *[]entity.Category, bookId uuid.UUID) error
+	SetBookCategories(bookId uuid.UUID, categoryIds []uuid.UUID) error
+}
+
+type CategoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) ICategoryRepository {
+	return &CategoryRepository{db}
+}
+
+// recursive CTE listing every category together with all of its descendants
+const categorySubtrees = `
+	WITH RECURSIVE subtrees AS (
+		SELECT id AS root_id, id FROM categories
+		UNION ALL
+		SELECT subtrees.root_id, categories.id
+		FROM categories
+		INNER JOIN subtrees ON categories.parent_id = subtrees.id
+	)`
+
+func nullableUUID(id uuid.UUID) any {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+// a book counts towards a category when it is linked to the category or any of its descendants
+func (r *CategoryRepository) GetCategories(categories *[]model.CategoryResponse) error {
+	query := categorySubtrees + `
+		SELECT categories.id, categories.parent_id, categories.name, COUNT(DISTINCT books.id) AS book_count
+		FROM categories
+		LEFT JOIN subtrees ON subtrees.root_id = categories.id
+		LEFT JOIN book_categories ON book_categories.category_id = subtrees.id
+		LEFT JOIN books ON books.id = book_categories.book_id AND ` + activeBook + `
+		GROUP BY categories.id, categories.parent_id, categories.name
+		ORDER BY categories.name ASC
+	`
+	return r.db.Select(categories, query)
+}
+
+func (r *CategoryRepository) GetCategory(category *entity.Category, categoryId uuid.UUID) error {
+	query := `SELECT * FROM categories WHERE id = $1`
+	err := r.db.Get(category, query, categoryId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.CategoryNotFound
+	}
+	return err
+}
+
+func (r *CategoryRepository) GetSubtreeIds(categoryId uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := categorySubtrees + `SELECT id FROM subtrees WHERE root_id = $1`
+	err := r.db.Select(&ids, query, categoryId)
+	return ids, err
+}
+
+func (r *CategoryRepository) HasChildren(categoryId uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`
+	err := r.db.Get(&exists, query, categoryId)
+	return exists, err
+}
+
+func (r *CategoryRepository) CreateCategory(category *entity.Category) error {
+	query := `INSERT INTO categories (id, parent_id, name) VALUES ($1, $2, $3)`
+	_, err := r.db.Exec(query, category.Id, nullableUUID(category.ParentId), category.Name)
+	return categoryError(err)
+}
+
+func (r *CategoryRepository) EditCategory(category *entity.Category) error {
+	query := `UPDATE categories SET parent_id = $1, name = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, nullableUUID(category.ParentId), category.Name, category.Id)
+	return categoryError(err)
+}
+
+func (r *CategoryRepository) DeleteCategory(categoryId uuid.UUID) error {
+	query := `DELETE FROM categories WHERE id = $1`
+	result, err := r.db.Exec(query, categoryId)
+	if err != nil {
+		return categoryError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.CategoryNotFound
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) GetBookCategories(categories *[]entity.Category, bookId uuid.UUID) error {
+	query := `
+		SELECT categories.* FROM categories
+		INNER JOIN book_categories ON book_categories.category_id = categories.id
+		WHERE book_categories.book_id = $1
+		ORDER BY categories.name ASC
+	`
+	return r.db.Select(categories, query, bookId)
+}
+
+func (r *CategoryRepository) SetBookCategories(bookId uuid.UUID, categoryIds []uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setBookCategories(tx, bookId, categoryIds); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func setBookCategories(tx *sqlx.Tx, bookId uuid.UUID, categoryIds []uuid.UUID) error {
+	if _, err := tx.Exec(`DELETE FROM book_categories WHERE book_id = $1`, bookId); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO book_categories (book_id, category_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, categoryId := range categoryIds {
+		if _, err := tx.Exec(query, bookId, categoryId); err != nil {
+			return categoryError(err)
+		}
+	}
+
+	return nil
+}
+
+func categoryError(err error) error {
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return &response.DuplicateCategory
+		case "23503":
+			return &response.CategoryNotFound
+		}
+	}
+	return err
+}
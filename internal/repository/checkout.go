@@ -3,8 +3,10 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"sort"
 
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -12,9 +14,8 @@ import (
 
 type ICheckoutRepository interface {
 	GetUserCheckouts(userId uuid.UUID) (*[]entity.Checkout, error)
-	GetCheckoutCarts(checkoutId uuid.UUID) (*[]entity.Cart, error)
-	AddCheckoutId(cartID uuid.UUID, checkoutId uuid.UUID) error
-	NewCheckout(userId, checkoutId uuid.UUID) error
+	GetCheckoutCarts(checkoutId uuid.UUID) (*[]model.CheckoutCart, error)
+	CreateCheckout(checkout *entity.Checkout, carts []entity.Cart, redemptions []entity.PromotionRedemption) error
 	GetCheckout(checkoutId uuid.UUID) (*entity.Checkout, error)
 	DeleteUser(userId uuid.UUID) error
 }
@@ -40,9 +41,19 @@ func (r *CheckoutRepository) GetUserCheckouts(userId uuid.UUID) (*[]entity.Check
 	return &checkouts, err
 }
 
-func (r *CheckoutRepository) GetCheckoutCarts(checkoutId uuid.UUID) (*[]entity.Cart, error) {
-	var carts []entity.Cart
-	query := `SELECT * FROM carts WHERE checkout_id = $1`
+func (r *CheckoutRepository) GetCheckoutCarts(checkoutId uuid.UUID) (*[]model.CheckoutCart, error) {
+	var carts []model.CheckoutCart
+	query := `
+		SELECT carts.*, books.title AS book_title, books.image AS book_image,
+			books.price AS book_price, books.deleted_at AS book_deleted_at,
+			book_formats.format, book_formats.price AS format_price,
+			COALESCE(bundles.name, '') AS bundle_name
+		FROM carts
+		INNER JOIN books ON books.id = carts.book_id
+		INNER JOIN book_formats ON book_formats.id = carts.format_id
+		LEFT JOIN bundles ON bundles.id = carts.bundle_id
+		WHERE carts.checkout_id = $1
+	`
 	err := r.db.Select(&carts, query, checkoutId)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, &response.CheckoutNotFound
@@ -51,16 +62,68 @@ func (r *CheckoutRepository) GetCheckoutCarts(checkoutId uuid.UUID) (*[]entity.C
 	return &carts, err
 }
 
-func (r *CheckoutRepository) AddCheckoutId(cartID uuid.UUID, checkoutId uuid.UUID) error {
-	query := `UPDATE carts SET checkout_id = $1 WHERE id = $2`
-	_, err := r.db.Exec(query, checkoutId, cartID)
-	return err
-}
+// CreateCheckout claims the carts, reserves their stock and flash sale units
+// and redeems the promotions in one transaction, so a cart can not be checked
+// out twice, stock and sales are never oversold and promotion limits hold
+func (r *CheckoutRepository) CreateCheckout(checkout *entity.Checkout, carts []entity.Cart, redemptions []entity.PromotionRedemption) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-func (r *CheckoutRepository) NewCheckout(checkoutId, userId uuid.UUID) error {
-	query := `INSERT INTO checkouts (id, user_id) VALUES ($1, $2)`
-	_, err := r.db.Exec(query, checkoutId, userId)
-	return err
+	query := `
+		INSERT INTO checkouts (id, user_id, shipping_name, shipping_phone, shipping_address, shipping_fee, subtotal, discount)
+		VALUES (:id, :user_id, :shipping_name, :shipping_phone, :shipping_address, :shipping_fee, :subtotal, :discount)
+	`
+	if _, err := tx.NamedExec(query, checkout); err != nil {
+		return err
+	}
+
+	// lock inventory rows in a fixed order so concurrent checkouts can not deadlock
+	sorted := append([]entity.Cart{}, carts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].FormatId.String() < sorted[j].FormatId.String()
+	})
+
+	for _, cart := range sorted {
+		query := `UPDATE carts SET checkout_id = $1, unit_price = $3, discount = $4, sale_id = $5 WHERE id = $2 AND checkout_id IS NULL`
+		result, err := tx.Exec(query, checkout.Id, cart.Id, cart.UnitPrice, cart.Discount, nullableUUID(cart.SaleId))
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return &response.CartNotFound
+		}
+
+		if err := reserveStock(tx, checkout.Id, cart.FormatId, cart.Amount); err != nil {
+			return err
+		}
+
+		if cart.SaleId != uuid.Nil {
+			if err := claimSale(tx, checkout.Id, cart.SaleId, cart.Amount); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := openPreorders(tx, checkout.Id); err != nil {
+		return err
+	}
+
+	for _, redemption := range redemptions {
+		if err := redeemPromotion(tx, redemption); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *CheckoutRepository) GetCheckout(checkoutId uuid.UUID) (*entity.Checkout, error) {
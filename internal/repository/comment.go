@@ -3,21 +3,28 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type ICommentRepository interface {
 	GetComment(id uuid.UUID) (*entity.Comment, error)
 	GetCommentByBook(bookId uuid.UUID) (*[]entity.Comment, error)
+	GetUserReviews(userId uuid.UUID, page, pageSize int) (*[]model.UserReview, error)
+	CountBooksReviewed(count *int, userId uuid.UUID) error
 	CreateComment(comment *entity.Comment) error
 	UpdateComment(comment *entity.Comment) error
 	DeleteComment(id uuid.UUID) error
 	DeleteCommentByBook(bookId uuid.UUID) error
 	DeleteUser(userId uuid.UUID) error
+	GetBookRating(rating *entity.BookRating, bookId uuid.UUID) error
+	GetBookRatings(ratings *[]entity.BookRating, bookIds []uuid.UUID) error
 }
 
 type CommentRepository struct {
@@ -41,6 +48,37 @@ func (r *CommentRepository) GetCommentByBook(bookId uuid.UUID) (*[]entity.Commen
 	return &comments, err
 }
 
+func (r *CommentRepository) GetUserReviews(userId uuid.UUID, page, pageSize int) (*[]model.UserReview, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+	query := `
+		SELECT comments.id, comments.book_id, books.title AS book_title, comments.comment, comments.rating, comments.created_at
+		FROM comments
+		INNER JOIN books ON comments.book_id = books.id
+		WHERE comments.user_id = $1
+		ORDER BY comments.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	reviews := []model.UserReview{}
+	err := r.db.Select(&reviews, query, userId, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &reviews, nil
+}
+
+func (r *CommentRepository) CountBooksReviewed(count *int, userId uuid.UUID) error {
+	query := `SELECT COUNT(DISTINCT book_id) FROM comments WHERE user_id = $1`
+	return r.db.Get(count, query, userId)
+}
+
 func (r *CommentRepository) GetComment(id uuid.UUID) (*entity.Comment, error) {
 	query := `SELECT * FROM comments WHERE id = $1`
 	var comment entity.Comment
@@ -55,16 +93,44 @@ func (r *CommentRepository) GetComment(id uuid.UUID) (*entity.Comment, error) {
 }
 
 func (r *CommentRepository) CreateComment(comment *entity.Comment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO comments (id, user_id, book_id, comment, rating, created_at) 
 		VALUES (:id, :user_id, :book_id, :comment, :rating, :created_at)
 	`
-	_, err := r.db.NamedExec(query, comment)
-	return err
+	if _, err := tx.NamedExec(query, comment); err != nil {
+		return err
+	}
+
+	if err := adjustRating(tx, comment.BookId, comment.Rating, 1); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *CommentRepository) UpdateComment(comment *entity.Comment) error {
-	query := `
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previous entity.Comment
+	query := `SELECT * FROM comments WHERE id = $1 AND user_id = $2 FOR UPDATE`
+	if err := tx.Get(&previous, query, comment.Id, comment.UserId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &response.CommentNotFound
+		}
+		return err
+	}
+
+	query = `
 		UPDATE comments 
 		SET 
 			book_id = :book_id,
@@ -73,22 +139,99 @@ func (r *CommentRepository) UpdateComment(comment *entity.Comment) error {
 			created_at = :created_at
 		WHERE id = :id AND user_id = :user_id
 	`
-	_, err := r.db.NamedExec(query, comment)
-	return err
+	if _, err := tx.NamedExec(query, comment); err != nil {
+		return err
+	}
+
+	if previous.BookId != comment.BookId || previous.Rating != comment.Rating {
+		if err := adjustRating(tx, previous.BookId, previous.Rating, -1); err != nil {
+			return err
+		}
+
+		if err := adjustRating(tx, comment.BookId, comment.Rating, 1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *CommentRepository) DeleteComment(id uuid.UUID) error {
-	query := `DELETE FROM comments WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deleted entity.Comment
+	query := `DELETE FROM comments WHERE id = $1 RETURNING *`
+	if err := tx.Get(&deleted, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &response.CommentNotFound
+		}
+		return err
+	}
+
+	if err := adjustRating(tx, deleted.BookId, deleted.Rating, -1); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *CommentRepository) DeleteCommentByBook(bookId uuid.UUID) error {
-	query := `DELETE FROM comments WHERE book_id = $1`
-	_, err := r.db.Exec(query, bookId)
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM comments WHERE book_id = $1`, bookId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM book_ratings WHERE book_id = $1`, bookId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// adjustRating adds delta ratings of the given star to a book's aggregate; the
+// upsert takes the row lock, so concurrent comments on one book serialize here
+func adjustRating(tx *sqlx.Tx, bookId uuid.UUID, rating int, delta int) error {
+	if rating < 1 || rating > 5 {
+		return &response.BadRequest
+	}
+
+	star := fmt.Sprintf("star_%d", rating)
+	query := `
+		INSERT INTO book_ratings (book_id, rating_count, rating_sum, ` + star + `)
+		VALUES ($1, $2, $2 * $3, $2)
+		ON CONFLICT (book_id) DO UPDATE SET
+			rating_count = book_ratings.rating_count + EXCLUDED.rating_count,
+			rating_sum = book_ratings.rating_sum + EXCLUDED.rating_sum,
+			` + star + ` = book_ratings.` + star + ` + EXCLUDED.` + star
+	_, err := tx.Exec(query, bookId, delta, rating)
+	return err
+}
+
+func (r *CommentRepository) GetBookRating(rating *entity.BookRating, bookId uuid.UUID) error {
+	query := `SELECT * FROM book_ratings WHERE book_id = $1`
+	err := r.db.Get(rating, query, bookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		*rating = entity.BookRating{BookId: bookId}
+		return nil
+	}
 	return err
 }
 
+func (r *CommentRepository) GetBookRatings(ratings *[]entity.BookRating, bookIds []uuid.UUID) error {
+	*ratings = []entity.BookRating{}
+	query := `SELECT * FROM book_ratings WHERE book_id = ANY($1)`
+	return r.db.Select(ratings, query, pq.Array(uuidStrings(bookIds)))
+}
+
 func (r *CommentRepository) DeleteUser(userId uuid.UUID) error {
 	query := `UPDATE comments SET user_id = $1 WHERE user_id = $2`
 	_, err := r.db.Exec(query, uuid.Nil, userId)
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type IDownloadRepository interface {
+	ClaimDownload(download *entity.Download, since time.Time, limit int) (bool, error)
+	ReleaseDownload(downloadId uuid.UUID) error
+	GetFileCopy(copy *entity.BookFileCopy, paymentId uuid.UUID, bookId uuid.UUID) error
+	SaveFileCopy(copy *entity.BookFileCopy) error
+	DeleteBookFileCopies(bookId uuid.UUID) ([]string, error)
+}
+
+type DownloadRepository struct {
+	db *sqlx.DB
+}
+
+func NewDownloadRepository(db *sqlx.DB) IDownloadRepository {
+	return &DownloadRepository{db}
+}
+
+// ClaimDownload records the download only while the user has fewer than limit
+// downloads of the book since the given time. The advisory lock serialises
+// parallel requests for the same user and book, so they can not all pass.
+func (r *DownloadRepository) ClaimDownload(download *entity.Download, since time.Time, limit int) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, download.UserId.String()+download.BookId.String()); err != nil {
+		return false, err
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM book_downloads WHERE user_id = $1 AND book_id = $2 AND created_at >= $3`
+	if err := tx.Get(&count, query, download.UserId, download.BookId, since); err != nil {
+		return false, err
+	}
+
+	if count >= limit {
+		return false, nil
+	}
+
+	query = `
+		INSERT INTO book_downloads (id, user_id, book_id, ip_address, user_agent, created_at)
+		VALUES (:id, :user_id, :book_id, :ip_address, :user_agent, :created_at)
+	`
+	if _, err := tx.NamedExec(query, download); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ReleaseDownload gives a claimed download back when no file was handed out
+func (r *DownloadRepository) ReleaseDownload(downloadId uuid.UUID) error {
+	query := `DELETE FROM book_downloads WHERE id = $1`
+	_, err := r.db.Exec(query, downloadId)
+	return err
+}
+
+func (r *DownloadRepository) GetFileCopy(copy *entity.BookFileCopy, paymentId uuid.UUID, bookId uuid.UUID) error {
+	query := `SELECT * FROM book_file_copies WHERE payment_id = $1 AND book_id = $2`
+	err := r.db.Get(copy, query, paymentId, bookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.BookFileNotFound
+	}
+	return err
+}
+
+func (r *DownloadRepository) SaveFileCopy(copy *entity.BookFileCopy) error {
+	query := `
+		INSERT INTO book_file_copies (payment_id, book_id, source_file, path, created_at)
+		VALUES (:payment_id, :book_id, :source_file, :path, :created_at)
+		ON CONFLICT (payment_id, book_id) DO UPDATE
+		SET source_file = EXCLUDED.source_file, path = EXCLUDED.path, created_at = EXCLUDED.created_at
+	`
+	_, err := r.db.NamedExec(query, copy)
+	return err
+}
+
+// DeleteBookFileCopies drops the cached copies of a book and returns their storage paths
+func (r *DownloadRepository) DeleteBookFileCopies(bookId uuid.UUID) ([]string, error) {
+	var paths []string
+	query := `DELETE FROM book_file_copies WHERE book_id = $1 RETURNING path`
+	err := r.db.Select(&paths, query, bookId)
+	return paths, err
+}
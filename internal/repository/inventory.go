@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const inventoryColumns = `
+	book_inventory.format_id, books.id AS book_id, books.title, book_formats.format, book_formats.sku, book_inventory.on_hand, book_inventory.reserved,
+	book_inventory.on_hand - book_inventory.reserved AS available,
+	book_inventory.low_stock_threshold,
+	book_inventory.on_hand - book_inventory.reserved <= book_inventory.low_stock_threshold AS low_stock`
+
+type IInventoryRepository interface {
+	GetInventories(inventories *[]model.InventoryResponse, req model.InventoryReq) error
+	GetInventory(inventory *model.InventoryResponse, formatId uuid.UUID) error
+	GetAdjustments(adjustments *[]entity.StockAdjustment, formatId uuid.UUID) error
+	AdjustStock(adjustment *entity.StockAdjustment) error
+	SetLowStockThreshold(formatId uuid.UUID, threshold int) error
+	ReleaseReservations(checkoutId uuid.UUID) error
+	CommitReservations(checkoutId uuid.UUID) error
+	GetStaleCheckouts(before time.Time) ([]uuid.UUID, error)
+}
+
+type InventoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewInventoryRepository(db *sqlx.DB) IInventoryRepository {
+	return &InventoryRepository{db}
+}
+
+func (r *InventoryRepository) GetInventories(inventories *[]model.InventoryResponse, req model.InventoryReq) error {
+	b := newQuery("book_inventory", inventoryColumns).
+		Join("book_formats", "INNER JOIN book_formats ON book_formats.id = book_inventory.format_id").
+		Join("books", "INNER JOIN books ON books.id = book_formats.book_id").
+		Where(activeBook)
+
+	if req.LowStockOnly {
+		b.Where("book_inventory.on_hand - book_inventory.reserved <= book_inventory.low_stock_threshold")
+	}
+
+	query, args := b.OrderBy("available ASC").OrderBy("books.title").Paginate(req.Page, req.PageSize).Build()
+
+	*inventories = []model.InventoryResponse{}
+	return r.db.Select(inventories, query, args...)
+}
+
+func (r *InventoryRepository) GetInventory(inventory *model.InventoryResponse, formatId uuid.UUID) error {
+	query := `
+		SELECT ` + inventoryColumns + `
+		FROM book_inventory
+		INNER JOIN book_formats ON book_formats.id = book_inventory.format_id
+		INNER JOIN books ON books.id = book_formats.book_id
+		WHERE book_inventory.format_id = $1
+	`
+	err := r.db.Get(inventory, query, formatId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.InventoryNotFound
+	}
+	return err
+}
+
+func (r *InventoryRepository) GetAdjustments(adjustments *[]entity.StockAdjustment, formatId uuid.UUID) error {
+	query := `SELECT * FROM stock_adjustments WHERE format_id = $1 ORDER BY created_at DESC`
+	*adjustments = []entity.StockAdjustment{}
+	return r.db.Select(adjustments, query, formatId)
+}
+
+// AdjustStock starts tracking a format on its first adjustment and logs every
+// change; on_hand may never drop below what is already reserved
+func (r *InventoryRepository) AdjustStock(adjustment *entity.StockAdjustment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO book_inventory (format_id, book_id, on_hand, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (format_id) DO UPDATE SET
+			on_hand = book_inventory.on_hand + EXCLUDED.on_hand,
+			updated_at = NOW()
+	`
+	if _, err := tx.Exec(query, adjustment.FormatId, adjustment.BookId, adjustment.Delta); err != nil {
+		return inventoryError(err)
+	}
+
+	query = `
+		INSERT INTO stock_adjustments (id, book_id, format_id, delta, reason, admin_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if _, err := tx.Exec(query, adjustment.Id, adjustment.BookId, adjustment.FormatId, adjustment.Delta, adjustment.Reason, nullableUUID(adjustment.AdminId), adjustment.CreatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *InventoryRepository) SetLowStockThreshold(formatId uuid.UUID, threshold int) error {
+	query := `UPDATE book_inventory SET low_stock_threshold = $1, updated_at = NOW() WHERE format_id = $2`
+	result, err := r.db.Exec(query, threshold, formatId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.InventoryNotFound
+	}
+
+	return nil
+}
+
+// reserveStock holds quantity copies for a checkout. The conditional update
+// takes the inventory row lock, so concurrent checkouts can not oversell.
+// Formats without an inventory row, digital ones included, are skipped.
+func reserveStock(tx *sqlx.Tx, checkoutId uuid.UUID, formatId uuid.UUID, quantity int) error {
+	query := `
+		UPDATE book_inventory
+		SET reserved = reserved + $2, updated_at = NOW()
+		WHERE format_id = $1 AND on_hand - reserved >= $2
+	`
+	result, err := tx.Exec(query, formatId, quantity)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		var tracked bool
+		if err := tx.Get(&tracked, `SELECT EXISTS (SELECT 1 FROM book_inventory WHERE format_id = $1)`, formatId); err != nil {
+			return err
+		}
+
+		if tracked {
+			return &response.OutOfStock
+		}
+		return nil
+	}
+
+	query = `
+		INSERT INTO stock_reservations (checkout_id, book_id, format_id, quantity, status, created_at, updated_at)
+		SELECT $1, book_id, format_id, $3, $4, NOW(), NOW() FROM book_inventory WHERE format_id = $2
+		ON CONFLICT (checkout_id, format_id) DO UPDATE SET quantity = stock_reservations.quantity + EXCLUDED.quantity
+	`
+	_, err = tx.Exec(query, checkoutId, formatId, quantity, entity.ReservationHeld)
+	return err
+}
+
+// ReleaseReservations returns a checkout's held copies to available stock
+func (r *InventoryRepository) ReleaseReservations(checkoutId uuid.UUID) error {
+	return r.settleReservations(checkoutId, entity.ReservationReleased, `reserved = book_inventory.reserved - held.quantity`)
+}
+
+// CommitReservations turns a checkout's held copies into sold ones
+func (r *InventoryRepository) CommitReservations(checkoutId uuid.UUID) error {
+	return r.settleReservations(checkoutId, entity.ReservationCommitted, `
+		reserved = book_inventory.reserved - held.quantity,
+		on_hand = book_inventory.on_hand - held.quantity`)
+}
+
+// settleReservations moves held reservations to status, applying set to the
+// inventory rows; reservations already settled are left alone. Flash sale
+// claims settle along with them, released ones giving their units back.
+func (r *InventoryRepository) settleReservations(checkoutId uuid.UUID, status string, set string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH held AS (
+			UPDATE stock_reservations SET status = $2, updated_at = NOW()
+			WHERE checkout_id = $1 AND status = $3
+			RETURNING format_id, quantity
+		)
+		UPDATE book_inventory SET ` + set + `, updated_at = NOW()
+		FROM held
+		WHERE book_inventory.format_id = held.format_id
+	`
+	if _, err := tx.Exec(query, checkoutId, status, entity.ReservationHeld); err != nil {
+		return err
+	}
+
+	query = `
+		WITH held AS (
+			UPDATE sale_claims SET status = $2, updated_at = NOW()
+			WHERE checkout_id = $1 AND status = $3
+			RETURNING sale_id, quantity
+		)
+		UPDATE book_sales SET sold = book_sales.sold - held.quantity
+		FROM held
+		WHERE book_sales.id = held.sale_id AND $2 = $4
+	`
+	if _, err := tx.Exec(query, checkoutId, status, entity.ReservationHeld, entity.ReservationReleased); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetStaleCheckouts lists checkouts still holding stock or flash sale units
+// since before the given time whose payment never went through
+func (r *InventoryRepository) GetStaleCheckouts(before time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT held.checkout_id
+		FROM (
+			SELECT checkout_id, created_at FROM stock_reservations WHERE status = $1
+			UNION ALL
+			SELECT checkout_id, created_at FROM sale_claims WHERE status = $1
+		) held
+		LEFT JOIN payments ON payments.checkout_id = held.checkout_id
+		WHERE held.created_at < $2
+			AND (payments.id IS NULL OR payments.status_id NOT IN (1, 4, 5))
+	`
+	checkoutIds := []uuid.UUID{}
+	err := r.db.Select(&checkoutIds, query, entity.ReservationHeld, before)
+	return checkoutIds, err
+}
+
+func inventoryError(err error) error {
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23514":
+			return &response.InvalidStock
+		case "23503":
+			return &response.BookNotFound
+		}
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type INotificationRepository interface {
+	GetPreferences(preferences *[]entity.NotificationPreference, userId uuid.UUID) error
+	UpsertPreferences(preferences []entity.NotificationPreference) error
+	GetUnsubscribeToken(userId uuid.UUID, newToken string) (string, error)
+	GetUserByUnsubscribeToken(token string) (uuid.UUID, error)
+	CreateNotification(notification *entity.Notification, deliveryKey string) error
+	GetDeliveredChannels(channels *[]string, userId uuid.UUID, deliveryKey string) error
+	MarkDelivered(userId uuid.UUID, deliveryKey string, channel string) error
+	ClearDeliveries(userId uuid.UUID, deliveryKey string) error
+	GetNotifications(notifications *[]entity.Notification, userId uuid.UUID, page, pageSize int) error
+	ReadNotification(notificationId uuid.UUID, userId uuid.UUID) error
+}
+
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) INotificationRepository {
+	return &NotificationRepository{db}
+}
+
+func (r *NotificationRepository) GetPreferences(preferences *[]entity.NotificationPreference, userId uuid.UUID) error {
+	query := `SELECT * FROM notification_preferences WHERE user_id = $1`
+	return r.db.Select(preferences, query, userId)
+}
+
+func (r *NotificationRepository) UpsertPreferences(preferences []entity.NotificationPreference) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notification_preferences (user_id, category, channel, enabled)
+		VALUES (:user_id, :category, :channel, :enabled)
+		ON CONFLICT (user_id, category, channel) DO UPDATE SET enabled = EXCLUDED.enabled
+	`
+	for _, preference := range preferences {
+		if _, err := tx.NamedExec(query, preference); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// returns the user's existing token, storing newToken only when they do not have one yet
+func (r *NotificationRepository) GetUnsubscribeToken(userId uuid.UUID, newToken string) (string, error) {
+	var token string
+	query := `
+		INSERT INTO unsubscribe_tokens (user_id, token) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET user_id = EXCLUDED.user_id
+		RETURNING token
+	`
+	err := r.db.Get(&token, query, userId, newToken)
+	return token, err
+}
+
+func (r *NotificationRepository) GetUserByUnsubscribeToken(token string) (uuid.UUID, error) {
+	var userId uuid.UUID
+	query := `SELECT user_id FROM unsubscribe_tokens WHERE token = $1`
+	err := r.db.Get(&userId, query, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, &response.InvalidToken
+	}
+	return userId, err
+}
+
+// CreateNotification stores an in-app notification. With a delivery key the
+// in-app channel is marked delivered in the same transaction, and a key that is
+// already marked stores nothing, so retrying a notification never doubles it.
+func (r *NotificationRepository) CreateNotification(notification *entity.Notification, deliveryKey string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if deliveryKey != "" {
+		query := `
+			INSERT INTO notification_deliveries (user_id, delivery_key, channel, delivered_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT DO NOTHING
+		`
+		result, err := tx.Exec(query, notification.UserId, deliveryKey, entity.ChannelInApp)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return nil
+		}
+	}
+
+	query := `
+		INSERT INTO notifications (id, user_id, category, title, body, is_read, created_at)
+		VALUES (:id, :user_id, :category, :title, :body, :is_read, :created_at)
+	`
+	if _, err := tx.NamedExec(query, notification); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *NotificationRepository) GetDeliveredChannels(channels *[]string, userId uuid.UUID, deliveryKey string) error {
+	query := `SELECT channel FROM notification_deliveries WHERE user_id = $1 AND delivery_key = $2`
+	*channels = []string{}
+	return r.db.Select(channels, query, userId, deliveryKey)
+}
+
+func (r *NotificationRepository) MarkDelivered(userId uuid.UUID, deliveryKey string, channel string) error {
+	query := `
+		INSERT INTO notification_deliveries (user_id, delivery_key, channel, delivered_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.Exec(query, userId, deliveryKey, channel)
+	return err
+}
+
+func (r *NotificationRepository) ClearDeliveries(userId uuid.UUID, deliveryKey string) error {
+	query := `DELETE FROM notification_deliveries WHERE user_id = $1 AND delivery_key = $2`
+	_, err := r.db.Exec(query, userId, deliveryKey)
+	return err
+}
+
+func (r *NotificationRepository) GetNotifications(notifications *[]entity.Notification, userId uuid.UUID, page, pageSize int) error {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+	query := `SELECT * FROM notifications WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	return r.db.Select(notifications, query, userId, pageSize, offset)
+}
+
+func (r *NotificationRepository) ReadNotification(notificationId uuid.UUID, userId uuid.UUID) error {
+	query := `UPDATE notifications SET is_read = TRUE WHERE id = $1 AND user_id = $2`
+	result, err := r.db.Exec(query, notificationId, userId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.NotificationNotFound
+	}
+
+	return nil
+}
@@ -10,11 +10,17 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// paidPayment matches payments that went through: accepted card captures (1)
+// and settlements (5)
+const paidPayment = `payments.status_id IN (1, 5)`
+
 type IPaymentRepository interface {
 	GetPayment(paymentId uuid.UUID) (*entity.Payment, error)
 	CreatePayment(payment entity.Payment) error
 	UpdatePaymentStatus(statusId int, paymentId uuid.UUID) error
 	CheckUserBookPurchase(userId uuid.UUID, bookId uuid.UUID) (*bool, error)
+	GetBookPurchase(userId uuid.UUID, bookId uuid.UUID) (*entity.Payment, error)
+	CheckUserFormatPurchase(userId uuid.UUID, formatId uuid.UUID) (bool, error)
 	GetPayments(page, pageSize int) ([]entity.Payment, error)
 	GetPaymentByCheckout(checkoutId uuid.UUID) (*entity.Payment, error)
 	GetPaymentByUser(userId uuid.UUID) (*[]entity.Payment, error)
@@ -64,7 +70,7 @@ func (r *PaymentRepository) CheckUserBookPurchase(userId uuid.UUID, bookId uuid.
 			SELECT 1 FROM payments
 			INNER JOIN checkouts ON payments.checkout_id = checkouts.id
 			INNER JOIN carts ON checkouts.id = carts.checkout_id
-			WHERE payments.user_id = $1 AND carts.book_id = $2 AND payments.status_id = 1
+			WHERE payments.user_id = $1 AND carts.book_id = $2 AND ` + paidPayment + ` AND ` + notRefunded + `
 		)
 	`
 	err := r.db.Get(&exists, query, userId, bookId)
@@ -75,6 +81,43 @@ func (r *PaymentRepository) CheckUserBookPurchase(userId uuid.UUID, bookId uuid.
 	return &exists, nil
 }
 
+// GetBookPurchase finds the first paid order of the book's ebook, the format the file belongs to
+func (r *PaymentRepository) GetBookPurchase(userId uuid.UUID, bookId uuid.UUID) (*entity.Payment, error) {
+	var payment entity.Payment
+	query := `
+		SELECT payments.* FROM payments
+		INNER JOIN checkouts ON payments.checkout_id = checkouts.id
+		INNER JOIN carts ON checkouts.id = carts.checkout_id
+		INNER JOIN book_formats ON book_formats.id = carts.format_id AND book_formats.format = 'ebook'
+		WHERE payments.user_id = $1 AND carts.book_id = $2 AND ` + paidPayment + ` AND ` + notRefunded + `
+		ORDER BY payments.created_at ASC
+		LIMIT 1
+	`
+	err := r.db.Get(&payment, query, userId, bookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &response.BookNotPurchased
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// CheckUserFormatPurchase reports whether the user has paid for this exact format
+func (r *PaymentRepository) CheckUserFormatPurchase(userId uuid.UUID, formatId uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM payments
+			INNER JOIN carts ON carts.checkout_id = payments.checkout_id
+			WHERE payments.user_id = $1 AND carts.format_id = $2 AND ` + paidPayment + ` AND ` + notRefunded + `
+		)
+	`
+	err := r.db.Get(&exists, query, userId, formatId)
+	return exists, err
+}
+
 func (r *PaymentRepository) GetPayments(page, pageSize int) ([]entity.Payment, error) {
 	if page < 1 {
 		page = 1
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// notRefunded keeps cancelled pre-orders from counting as purchases
+const notRefunded = `NOT EXISTS (
+	SELECT 1 FROM preorders WHERE preorders.cart_id = carts.id AND preorders.status = 'cancelled'
+)`
+
+const preorderColumns = `
+	preorders.*, books.title, book_formats.format, carts.amount,
+	carts.unit_price * carts.amount - carts.discount AS line_total,
+	payment.id AS payment_id, COALESCE(payment.status_id IN (1, 5), FALSE) AS paid`
+
+const preorderJoins = `
+	INNER JOIN books ON books.id = preorders.book_id
+	INNER JOIN carts ON carts.id = preorders.cart_id
+	INNER JOIN book_formats ON book_formats.id = carts.format_id
+	LEFT JOIN LATERAL (
+		SELECT payments.id, payments.status_id FROM payments
+		WHERE payments.checkout_id = preorders.checkout_id
+		ORDER BY payments.created_at DESC
+		LIMIT 1
+	) payment ON TRUE`
+
+const paidPreorder = `preorders.status = 'open' AND EXISTS (
+	SELECT 1 FROM payments WHERE payments.checkout_id = preorders.checkout_id AND ` + paidPayment + `
+)`
+
+type IPreorderRepository interface {
+	GetUserPreorders(preorders *[]model.PreorderResponse, userId uuid.UUID) error
+	GetBookPreorders(preorders *[]model.PreorderResponse, bookId uuid.UUID) error
+	GetPreorder(preorder *model.PreorderResponse, preorderId uuid.UUID) error
+	CancelPreorder(preorderId uuid.UUID, refundAmount float64, reason string, adminId uuid.UUID) error
+	GetUnrefunded(preorders *[]model.PreorderResponse, checkoutId uuid.UUID) error
+	SetRefund(preorderId uuid.UUID, refundAmount float64) error
+	GetRescheduled(notices *[]model.PreorderNotice) error
+	SetReleaseDate(preorderId uuid.UUID, releaseDate string) error
+	GetReleased(notices *[]model.PreorderNotice) error
+	MarkFulfilled(preorderId uuid.UUID) error
+}
+
+type PreorderRepository struct {
+	db *sqlx.DB
+}
+
+func NewPreorderRepository(db *sqlx.DB) IPreorderRepository {
+	return &PreorderRepository{db}
+}
+
+func (r *PreorderRepository) GetUserPreorders(preorders *[]model.PreorderResponse, userId uuid.UUID) error {
+	query := `SELECT ` + preorderColumns + ` FROM preorders ` + preorderJoins + `
+		WHERE preorders.user_id = $1
+		ORDER BY preorders.created_at DESC
+	`
+	*preorders = []model.PreorderResponse{}
+	return r.db.Select(preorders, query, userId)
+}
+
+func (r *PreorderRepository) GetBookPreorders(preorders *[]model.PreorderResponse, bookId uuid.UUID) error {
+	query := `SELECT ` + preorderColumns + ` FROM preorders ` + preorderJoins + `
+		WHERE preorders.book_id = $1
+		ORDER BY preorders.created_at DESC
+	`
+	*preorders = []model.PreorderResponse{}
+	return r.db.Select(preorders, query, bookId)
+}
+
+func (r *PreorderRepository) GetPreorder(preorder *model.PreorderResponse, preorderId uuid.UUID) error {
+	query := `SELECT ` + preorderColumns + ` FROM preorders ` + preorderJoins + ` WHERE preorders.id = $1`
+	err := r.db.Get(preorder, query, preorderId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.PreorderNotFound
+	}
+	return err
+}
+
+func (r *PreorderRepository) CancelPreorder(preorderId uuid.UUID, refundAmount float64, reason string, adminId uuid.UUID) error {
+	query := `
+		UPDATE preorders
+		SET status = $2, refund_amount = $3, cancel_reason = $4, cancelled_by = $5, updated_at = NOW()
+		WHERE id = $1 AND status = $6
+	`
+	result, err := r.db.Exec(query, preorderId, entity.PreorderCancelled, refundAmount, reason, nullableUUID(adminId), entity.PreorderOpen)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.PreorderClosed
+	}
+
+	return nil
+}
+
+// GetUnrefunded lists a checkout's pre-orders that were cancelled before it was
+// paid, so nothing has been refunded for them yet
+func (r *PreorderRepository) GetUnrefunded(preorders *[]model.PreorderResponse, checkoutId uuid.UUID) error {
+	query := `SELECT ` + preorderColumns + ` FROM preorders ` + preorderJoins + `
+		WHERE preorders.checkout_id = $1 AND preorders.status = $2 AND preorders.refund_amount = 0
+	`
+	*preorders = []model.PreorderResponse{}
+	return r.db.Select(preorders, query, checkoutId, entity.PreorderCancelled)
+}
+
+func (r *PreorderRepository) SetRefund(preorderId uuid.UUID, refundAmount float64) error {
+	query := `UPDATE preorders SET refund_amount = $2, updated_at = NOW() WHERE id = $1 AND status = $3`
+	_, err := r.db.Exec(query, preorderId, refundAmount, entity.PreorderCancelled)
+	return err
+}
+
+// GetRescheduled lists paid pre-orders whose book moved to another future date
+func (r *PreorderRepository) GetRescheduled(notices *[]model.PreorderNotice) error {
+	query := `
+		SELECT preorders.id, preorders.user_id, preorders.book_id, books.title,
+			TO_CHAR(books.release_date, 'YYYY-MM-DD') AS release_date
+		FROM preorders
+		INNER JOIN books ON books.id = preorders.book_id
+		WHERE ` + paidPreorder + ` AND books.release_date <> preorders.release_date
+			AND books.release_date > CURRENT_DATE
+	`
+	*notices = []model.PreorderNotice{}
+	return r.db.Select(notices, query)
+}
+
+func (r *PreorderRepository) SetReleaseDate(preorderId uuid.UUID, releaseDate string) error {
+	query := `UPDATE preorders SET release_date = $2, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Exec(query, preorderId, releaseDate)
+	return err
+}
+
+func (r *PreorderRepository) GetReleased(notices *[]model.PreorderNotice) error {
+	query := `
+		SELECT preorders.id, preorders.user_id, preorders.book_id, books.title,
+			TO_CHAR(books.release_date, 'YYYY-MM-DD') AS release_date
+		FROM preorders
+		INNER JOIN books ON books.id = preorders.book_id
+		WHERE ` + paidPreorder + ` AND books.release_date <= CURRENT_DATE
+	`
+	*notices = []model.PreorderNotice{}
+	return r.db.Select(notices, query)
+}
+
+func (r *PreorderRepository) MarkFulfilled(preorderId uuid.UUID) error {
+	query := `UPDATE preorders SET status = $2, updated_at = NOW() WHERE id = $1 AND status = $3`
+	_, err := r.db.Exec(query, preorderId, entity.PreorderFulfilled, entity.PreorderOpen)
+	return err
+}
+
+// openPreorders records the checkout's carts of books not released yet
+func openPreorders(tx *sqlx.Tx, checkoutId uuid.UUID) error {
+	query := `
+		INSERT INTO preorders (id, cart_id, user_id, book_id, checkout_id, release_date, status, created_at, updated_at)
+		SELECT gen_random_uuid()::text, carts.id, carts.user_id, carts.book_id, carts.checkout_id, books.release_date, $2, NOW(), NOW()
+		FROM carts
+		INNER JOIN books ON books.id = carts.book_id
+		WHERE carts.checkout_id = $1 AND books.release_date > CURRENT_DATE
+	`
+	_, err := tx.Exec(query, checkoutId, entity.PreorderOpen)
+	return err
+}
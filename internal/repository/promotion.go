@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// a redemption holds its use while its checkout has a pending, challenged or
+// paid payment. A checkout gets its payment in the same request, so one with no
+// payment only holds the use briefly; after that checkout or token creation has
+// failed and the use is given back.
+const activeRedemption = `(EXISTS (
+	SELECT 1 FROM payments
+	WHERE payments.checkout_id = promotion_redemptions.checkout_id AND payments.status_id IN (0, 1, 4, 5)
+) OR (
+	promotion_redemptions.created_at > NOW() - INTERVAL '15 minutes' AND NOT EXISTS (
+		SELECT 1 FROM payments WHERE payments.checkout_id = promotion_redemptions.checkout_id
+	)
+))`
+
+const promotionColumns = `promotions.*, (
+	SELECT COUNT(*) FROM promotion_redemptions
+	WHERE promotion_redemptions.promotion_id = promotions.id AND ` + activeRedemption + `
+) AS uses`
+
+const activePromotion = `promotions.starts_at <= $1 AND (promotions.ends_at IS NULL OR promotions.ends_at > $1)`
+
+type IPromotionRepository interface {
+	GetPromotions(promotions *[]model.PromotionResponse, req model.PromotionReq) error
+	GetPromotion(promotion *model.PromotionResponse, promotionId uuid.UUID) error
+	GetPromotionByCode(promotion *entity.Promotion, code string, now time.Time) error
+	GetAutomaticPromotions(promotions *[]entity.Promotion, now time.Time) error
+	CountRedemptions(promotionId uuid.UUID, userId uuid.UUID) (total int, user int, err error)
+	CreatePromotion(promotion *entity.Promotion) error
+	EndPromotion(promotionId uuid.UUID) error
+}
+
+type PromotionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPromotionRepository(db *sqlx.DB) IPromotionRepository {
+	return &PromotionRepository{db}
+}
+
+func (r *PromotionRepository) GetPromotions(promotions *[]model.PromotionResponse, req model.PromotionReq) error {
+	b := newQuery("promotions", promotionColumns)
+	if req.ActiveOnly {
+		b.Where("promotions.starts_at <= NOW() AND (promotions.ends_at IS NULL OR promotions.ends_at > NOW())")
+	}
+
+	query, args := b.OrderBy("promotions.created_at DESC").Paginate(req.Page, req.PageSize).Build()
+
+	*promotions = []model.PromotionResponse{}
+	return r.db.Select(promotions, query, args...)
+}
+
+func (r *PromotionRepository) GetPromotion(promotion *model.PromotionResponse, promotionId uuid.UUID) error {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE promotions.id = $1`
+	err := r.db.Get(promotion, query, promotionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.PromotionNotFound
+	}
+	return err
+}
+
+// GetPromotionByCode only finds codes whose validity window contains now
+func (r *PromotionRepository) GetPromotionByCode(promotion *entity.Promotion, code string, now time.Time) error {
+	query := `SELECT * FROM promotions WHERE ` + activePromotion + ` AND promotions.code = $2`
+	err := r.db.Get(promotion, query, now, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.InvalidPromotion
+	}
+	return err
+}
+
+func (r *PromotionRepository) GetAutomaticPromotions(promotions *[]entity.Promotion, now time.Time) error {
+	query := `
+		SELECT * FROM promotions
+		WHERE ` + activePromotion + ` AND promotions.code IS NULL
+		ORDER BY promotions.created_at
+	`
+	*promotions = []entity.Promotion{}
+	return r.db.Select(promotions, query, now)
+}
+
+func (r *PromotionRepository) CountRedemptions(promotionId uuid.UUID, userId uuid.UUID) (int, int, error) {
+	return countRedemptions(r.db, promotionId, userId)
+}
+
+func countRedemptions(q sqlx.Queryer, promotionId uuid.UUID, userId uuid.UUID) (int, int, error) {
+	var counts struct {
+		Total int `db:"total"`
+		User  int `db:"user_total"`
+	}
+	query := `
+		SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE promotion_redemptions.user_id = $2) AS user_total
+		FROM promotion_redemptions
+		WHERE promotion_redemptions.promotion_id = $1 AND ` + activeRedemption
+	err := sqlx.Get(q, &counts, query, promotionId, userId)
+	return counts.Total, counts.User, err
+}
+
+func (r *PromotionRepository) CreatePromotion(promotion *entity.Promotion) error {
+	query := `
+		INSERT INTO promotions (
+			id, code, name, kind, value, scope, book_id, category_id, min_spend, min_quantity,
+			max_uses, max_uses_per_user, starts_at, ends_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+	_, err := r.db.Exec(query,
+		promotion.Id, promotion.Code, promotion.Name, promotion.Kind, promotion.Value, promotion.Scope,
+		nullableUUID(promotion.BookId), nullableUUID(promotion.CategoryId), promotion.MinSpend, promotion.MinQuantity,
+		promotion.MaxUses, promotion.MaxUsesPerUser, promotion.StartsAt, promotion.EndsAt, promotion.CreatedAt,
+	)
+	return promotionError(err)
+}
+
+// EndPromotion closes the validity window now, keeping its redemption history
+func (r *PromotionRepository) EndPromotion(promotionId uuid.UUID) error {
+	query := `
+		UPDATE promotions SET ends_at = GREATEST(NOW(), starts_at)
+		WHERE id = $1 AND (ends_at IS NULL OR ends_at > NOW())
+	`
+	result, err := r.db.Exec(query, promotionId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.PromotionNotFound
+	}
+
+	return nil
+}
+
+// redeemPromotion records a use inside the checkout transaction. Locking the
+// promotion row serialises concurrent checkouts so usage limits hold.
+func redeemPromotion(tx *sqlx.Tx, redemption entity.PromotionRedemption) error {
+	var limits struct {
+		MaxUses        *int `db:"max_uses"`
+		MaxUsesPerUser *int `db:"max_uses_per_user"`
+	}
+	query := `SELECT max_uses, max_uses_per_user FROM promotions WHERE id = $1 FOR UPDATE`
+	if err := tx.Get(&limits, query, redemption.PromotionId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &response.InvalidPromotion
+		}
+		return err
+	}
+
+	total, user, err := countRedemptions(tx, redemption.PromotionId, redemption.UserId)
+	if err != nil {
+		return err
+	}
+
+	if (limits.MaxUses != nil && total >= *limits.MaxUses) || (limits.MaxUsesPerUser != nil && user >= *limits.MaxUsesPerUser) {
+		return &response.PromotionExhausted
+	}
+
+	query = `
+		INSERT INTO promotion_redemptions (promotion_id, checkout_id, user_id, discount, created_at)
+		VALUES (:promotion_id, :checkout_id, :user_id, :discount, :created_at)
+	`
+	_, err = tx.NamedExec(query, redemption)
+	return err
+}
+
+func promotionError(err error) error {
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return &response.DuplicatePromotion
+		case "23503", "23514":
+			return &response.BadRequest
+		}
+	}
+	return err
+}
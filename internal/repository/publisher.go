@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IPublisherRepository interface {
+	GetPublishers(publishers *[]entity.Publisher) error
+	GetPublisher(publisher *entity.Publisher, publisherId uuid.UUID) error
+	GetPublisherByName(publisher *entity.Publisher, name string) error
+	CreatePublisher(publisher *entity.Publisher) error
+	EditPublisher(publisher *entity.Publisher) error
+	DeletePublisher(publisherId uuid.UUID) error
+	GetPublisherBooks(books *[]entity.Book, publisherId uuid.UUID) error
+	SetBookPublisher(bookId uuid.UUID, publisherId uuid.UUID) error
+}
+
+type PublisherRepository struct {
+	db *sqlx.DB
+}
+
+func NewPublisherRepository(db *sqlx.DB) IPublisherRepository {
+	return &PublisherRepository{db}
+}
+
+func (r *PublisherRepository) GetPublishers(publishers *[]entity.Publisher) error {
+	query := `SELECT * FROM publishers ORDER BY name ASC`
+	*publishers = []entity.Publisher{}
+	return r.db.Select(publishers, query)
+}
+
+func (r *PublisherRepository) GetPublisher(publisher *entity.Publisher, publisherId uuid.UUID) error {
+	query := `SELECT * FROM publishers WHERE id = $1`
+	err := r.db.Get(publisher, query, publisherId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.PublisherNotFound
+	}
+	return err
+}
+
+func (r *PublisherRepository) GetPublisherByName(publisher *entity.Publisher, name string) error {
+	query := `SELECT * FROM publishers WHERE LOWER(name) = LOWER($1) LIMIT 1`
+	err := r.db.Get(publisher, query, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.PublisherNotFound
+	}
+	return err
+}
+
+func (r *PublisherRepository) CreatePublisher(publisher *entity.Publisher) error {
+	query := `INSERT INTO publishers (id, name, description, logo) VALUES (:id, :name, :description, :logo)`
+	_, err := r.db.NamedExec(query, publisher)
+	return publisherError(err)
+}
+
+func (r *PublisherRepository) EditPublisher(publisher *entity.Publisher) error {
+	query := `UPDATE publishers SET name = :name, description = :description, logo = :logo WHERE id = :id`
+	_, err := r.db.NamedExec(query, publisher)
+	return publisherError(err)
+}
+
+func (r *PublisherRepository) DeletePublisher(publisherId uuid.UUID) error {
+	query := `DELETE FROM publishers WHERE id = $1`
+	result, err := r.db.Exec(query, publisherId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.PublisherNotFound
+	}
+
+	return nil
+}
+
+func (r *PublisherRepository) GetPublisherBooks(books *[]entity.Book, publisherId uuid.UUID) error {
+	query := `
+		SELECT ` + bookColumns + ` FROM books
+		WHERE publisher_id = $1 AND ` + activeBook + `
+		ORDER BY release_date DESC
+	`
+	*books = []entity.Book{}
+	return r.db.Select(books, query, publisherId)
+}
+
+func (r *PublisherRepository) SetBookPublisher(bookId uuid.UUID, publisherId uuid.UUID) error {
+	query := `UPDATE books SET publisher_id = $1 WHERE id = $2`
+	_, err := r.db.Exec(query, nullableUUID(publisherId), bookId)
+	return publisherError(err)
+}
+
+func setBookPublisher(tx *sqlx.Tx, bookId uuid.UUID, publisherId uuid.UUID) error {
+	query := `UPDATE books SET publisher_id = $1 WHERE id = $2`
+	_, err := tx.Exec(query, nullableUUID(publisherId), bookId)
+	return publisherError(err)
+}
+
+func publisherError(err error) error {
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return &response.DuplicatePublisher
+		case "23503":
+			return &response.PublisherNotFound
+		}
+	}
+	return err
+}
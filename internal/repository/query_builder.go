@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// small SELECT builder so filters can be composed without hand-numbering $n placeholders,
+// clauses are written with ? and renumbered when the query is built
+type queryBuilder struct {
+	columns    []string
+	columnArgs []any
+	from       string
+	joins      []string
+	joinArgs   []any
+	wheres     []string
+	whereArgs  []any
+	groupBy    []string
+	orderBy    []string
+	orderArgs  []any
+	limit      int
+	offset     int
+	joined     map[string]bool
+}
+
+func newQuery(from string, columns ...string) *queryBuilder {
+	return &queryBuilder{
+		columns: columns,
+		from:    from,
+		joined:  map[string]bool{},
+	}
+}
+
+func (b *queryBuilder) Columns(columns ...string) *queryBuilder {
+	b.columns = append(b.columns, columns...)
+	return b
+}
+
+func (b *queryBuilder) Column(expression string, args ...any) *queryBuilder {
+	b.columns = append(b.columns, expression)
+	b.columnArgs = append(b.columnArgs, args...)
+	return b
+}
+
+// joins are keyed by name so several filters can ask for the same join
+func (b *queryBuilder) Join(name string, clause string, args ...any) *queryBuilder {
+	if b.joined[name] {
+		return b
+	}
+
+	b.joined[name] = true
+	b.joins = append(b.joins, clause)
+	b.joinArgs = append(b.joinArgs, args...)
+	return b
+}
+
+func (b *queryBuilder) Where(condition string, args ...any) *queryBuilder {
+	b.wheres = append(b.wheres, condition)
+	b.whereArgs = append(b.whereArgs, args...)
+	return b
+}
+
+func (b *queryBuilder) GroupBy(columns ...string) *queryBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+func (b *queryBuilder) OrderBy(expression string, args ...any) *queryBuilder {
+	b.orderBy = append(b.orderBy, expression)
+	b.orderArgs = append(b.orderArgs, args...)
+	return b
+}
+
+func (b *queryBuilder) Paginate(page, pageSize int) *queryBuilder {
+	if page < 1 {
+		page = 1
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	b.limit = pageSize
+	b.offset = (page - 1) * pageSize
+	return b
+}
+
+func (b *queryBuilder) Build() (string, []any) {
+	var query strings.Builder
+	args := make([]any, 0, len(b.columnArgs)+len(b.joinArgs)+len(b.whereArgs)+len(b.orderArgs)+2)
+
+	query.WriteString("SELECT ")
+	query.WriteString(strings.Join(b.columns, ", "))
+	args = append(args, b.columnArgs...)
+	query.WriteString(" FROM ")
+	query.WriteString(b.from)
+
+	for _, join := range b.joins {
+		query.WriteString(" ")
+		query.WriteString(join)
+	}
+	args = append(args, b.joinArgs...)
+
+	if len(b.wheres) > 0 {
+		query.WriteString(" WHERE (")
+		query.WriteString(strings.Join(b.wheres, ") AND ("))
+		query.WriteString(")")
+		args = append(args, b.whereArgs...)
+	}
+
+	if len(b.groupBy) > 0 {
+		query.WriteString(" GROUP BY ")
+		query.WriteString(strings.Join(b.groupBy, ", "))
+	}
+
+	if len(b.orderBy) > 0 {
+		query.WriteString(" ORDER BY ")
+		query.WriteString(strings.Join(b.orderBy, ", "))
+		args = append(args, b.orderArgs...)
+	}
+
+	if b.limit > 0 {
+		query.WriteString(" LIMIT ? OFFSET ?")
+		args = append(args, b.limit, b.offset)
+	}
+
+	return numberPlaceholders(query.String()), args
+}
+
+// numberPlaceholders turns each ? into $n, leaving quoted literals and
+// identifiers alone. ?? stands for a literal ?, such as the jsonb operator.
+func numberPlaceholders(query string) string {
+	var numbered strings.Builder
+	n := 0
+	var quote rune
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			// a doubled quote inside a literal is an escaped quote and toggles twice
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?' && i+1 < len(runes) && runes[i+1] == '?':
+			i++
+		case r == '?':
+			n++
+			numbered.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		numbered.WriteRune(r)
+	}
+	return numbered.String()
+}
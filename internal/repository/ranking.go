@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// trending weights per event, before time decay
+const (
+	trendingPurchaseWeight = 3.0
+	trendingCartWeight     = 1.0
+	trendingReviewWeight   = 2.0
+)
+
+type IRankingRepository interface {
+	RefreshBestsellers(list string, since *time.Time, size int) error
+	RefreshTrending(halfLife time.Duration, size int) error
+	GetRanking(rankings *[]model.BookRanking, list string, limit int) error
+}
+
+type RankingRepository struct {
+	db *sqlx.DB
+}
+
+func NewRankingRepository(db *sqlx.DB) IRankingRepository {
+	return &RankingRepository{db}
+}
+
+// replaceRanking swaps a list's rows for the output of a query selecting
+// (book_id, score) in ranked order
+func (r *RankingRepository) replaceRanking(list string, scores string, args ...any) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM book_rankings WHERE list = $1`, list); err != nil {
+		return err
+	}
+
+	query := scores + `
+		INSERT INTO book_rankings (list, book_id, score, position, computed_at)
+		SELECT $1, book_id, score, ROW_NUMBER() OVER (ORDER BY score DESC, book_id), NOW()
+		FROM ranked
+	`
+	if _, err := tx.Exec(query, append([]any{list}, args...)...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RefreshBestsellers counts copies sold through accepted or settled payments
+// since the given time, or over all time when since is nil
+func (r *RankingRepository) RefreshBestsellers(list string, since *time.Time, size int) error {
+	scores := `
+		WITH ranked AS (
+			SELECT carts.book_id, SUM(carts.amount)::float AS score
+			FROM payments
+			INNER JOIN carts ON carts.checkout_id = payments.checkout_id
+			WHERE ` + paidPayment + `
+				AND ($2::timestamp IS NULL OR payments.created_at >= $2)
+			GROUP BY carts.book_id
+			ORDER BY score DESC, carts.book_id
+			LIMIT $3
+		)`
+	return r.replaceRanking(list, scores, since, size)
+}
+
+// RefreshTrending scores purchases, add-to-carts and reviews, each decaying by
+// half every halfLife; events older than five half-lives are ignored
+func (r *RankingRepository) RefreshTrending(halfLife time.Duration, size int) error {
+	scores := `
+		WITH events AS (
+			SELECT carts.book_id, carts.amount * $4::float AS weight, payments.created_at
+			FROM payments
+			INNER JOIN carts ON carts.checkout_id = payments.checkout_id
+			WHERE ` + paidPayment + ` AND payments.created_at >= $2
+			UNION ALL
+			SELECT book_id, amount * $5::float, created_at FROM cart_events WHERE created_at >= $2
+			UNION ALL
+			SELECT book_id, $6::float, created_at FROM comments WHERE created_at >= $2
+		), ranked AS (
+			SELECT book_id, SUM(weight * EXP(-LN(2) * EXTRACT(EPOCH FROM NOW() - created_at) / $7)) AS score
+			FROM events
+			GROUP BY book_id
+			ORDER BY score DESC, book_id
+			LIMIT $3
+		)`
+	since := time.Now().Add(-5 * halfLife)
+	return r.replaceRanking(entity.RankingTrending, scores, since, size, trendingPurchaseWeight, trendingCartWeight, trendingReviewWeight, halfLife.Seconds())
+}
+
+func (r *RankingRepository) GetRanking(rankings *[]model.BookRanking, list string, limit int) error {
+	query := `
+		SELECT ` + bookColumns + `, book_rankings.position, book_rankings.score
+		FROM book_rankings
+		INNER JOIN books ON books.id = book_rankings.book_id
+		WHERE book_rankings.list = $1 AND ` + activeBook + `
+		ORDER BY book_rankings.position
+		LIMIT $2
+	`
+	*rankings = []model.BookRanking{}
+	return r.db.Select(rankings, query, list, limit)
+}
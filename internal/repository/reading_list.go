@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IReadingListRepository interface {
+	GetUserReadingLists(lists *[]model.ReadingListSummary, userId uuid.UUID, publicOnly bool) error
+	GetReadingList(list *entity.ReadingList, listId uuid.UUID) error
+	CreateReadingList(list *entity.ReadingList) error
+	EditReadingList(list *entity.ReadingList) error
+	DeleteReadingList(listId uuid.UUID, userId uuid.UUID) error
+	GetReadingListBooks(books *[]model.ReadingListBookRow, listId uuid.UUID) error
+	AddReadingListBook(listId uuid.UUID, bookId uuid.UUID) error
+	RemoveReadingListBook(listId uuid.UUID, bookId uuid.UUID) error
+}
+
+type ReadingListRepository struct {
+	db *sqlx.DB
+}
+
+func NewReadingListRepository(db *sqlx.DB) IReadingListRepository {
+	return &ReadingListRepository{db}
+}
+
+// trashed books stay on a list but are neither counted nor shown
+func (r *ReadingListRepository) GetUserReadingLists(lists *[]model.ReadingListSummary, userId uuid.UUID, publicOnly bool) error {
+	query := `
+		SELECT reading_lists.*, (
+			SELECT COUNT(*) FROM reading_list_books
+			INNER JOIN books ON books.id = reading_list_books.book_id
+			WHERE reading_list_books.list_id = reading_lists.id AND ` + activeBook + `
+		) AS book_count
+		FROM reading_lists
+		WHERE reading_lists.user_id = $1 AND (reading_lists.is_public OR NOT $2)
+		ORDER BY reading_lists.created_at DESC
+	`
+	*lists = []model.ReadingListSummary{}
+	return r.db.Select(lists, query, userId, publicOnly)
+}
+
+func (r *ReadingListRepository) GetReadingList(list *entity.ReadingList, listId uuid.UUID) error {
+	query := `SELECT * FROM reading_lists WHERE id = $1`
+	err := r.db.Get(list, query, listId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.ReadingListNotFound
+	}
+	return err
+}
+
+func (r *ReadingListRepository) CreateReadingList(list *entity.ReadingList) error {
+	query := `
+		INSERT INTO reading_lists (id, user_id, name, description, is_public, created_at, updated_at)
+		VALUES (:id, :user_id, :name, :description, :is_public, :created_at, :updated_at)
+	`
+	_, err := r.db.NamedExec(query, list)
+	return err
+}
+
+func (r *ReadingListRepository) EditReadingList(list *entity.ReadingList) error {
+	query := `
+		UPDATE reading_lists SET name = :name, description = :description, is_public = :is_public, updated_at = NOW()
+		WHERE id = :id AND user_id = :user_id
+	`
+	_, err := r.db.NamedExec(query, list)
+	return err
+}
+
+func (r *ReadingListRepository) DeleteReadingList(listId uuid.UUID, userId uuid.UUID) error {
+	query := `DELETE FROM reading_lists WHERE id = $1 AND user_id = $2`
+	result, err := r.db.Exec(query, listId, userId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.ReadingListNotFound
+	}
+
+	return nil
+}
+
+func (r *ReadingListRepository) GetReadingListBooks(books *[]model.ReadingListBookRow, listId uuid.UUID) error {
+	query := `
+		SELECT ` + bookColumns + `, reading_list_books.added_at
+		FROM reading_list_books
+		INNER JOIN books ON books.id = reading_list_books.book_id
+		WHERE reading_list_books.list_id = $1 AND ` + activeBook + `
+		ORDER BY reading_list_books.added_at ASC
+	`
+	*books = []model.ReadingListBookRow{}
+	return r.db.Select(books, query, listId)
+}
+
+func (r *ReadingListRepository) AddReadingListBook(listId uuid.UUID, bookId uuid.UUID) error {
+	query := `INSERT INTO reading_list_books (list_id, book_id, added_at) VALUES ($1, $2, NOW())`
+	_, err := r.db.Exec(query, listId, bookId)
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return &response.BookInReadingList
+		case "23503":
+			return &response.BookNotFound
+		}
+	}
+	return err
+}
+
+func (r *ReadingListRepository) RemoveReadingListBook(listId uuid.UUID, bookId uuid.UUID) error {
+	query := `DELETE FROM reading_list_books WHERE list_id = $1 AND book_id = $2`
+	result, err := r.db.Exec(query, listId, bookId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.BookNotInReadingList
+	}
+
+	return nil
+}
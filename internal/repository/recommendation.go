@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// deletedUser owns the payments of deleted accounts, which are not one buyer
+const deletedUser = `00000000-0000-0000-0000-000000000000`
+
+// purchasedBooks lists every (user_id, book_id) pair with a paid payment that
+// was not refunded
+const purchasedBooks = `
+	SELECT DISTINCT payments.user_id, carts.book_id
+	FROM payments
+	INNER JOIN carts ON carts.checkout_id = payments.checkout_id
+	WHERE ` + paidPayment + ` AND payments.user_id <> '` + deletedUser + `' AND ` + notRefunded
+
+type IRecommendationRepository interface {
+	RefreshSimilarities(neighbours int) error
+	GetRelatedBooks(books *[]entity.Book, bookId uuid.UUID, limit int) error
+	GetRecommendedBooks(books *[]entity.Book, userId uuid.UUID, limit int) error
+	GetSimilarBooks(books *[]entity.Book, seedIds []uuid.UUID, excludeIds []uuid.UUID, limit int) error
+	GetUserSeeds(userId uuid.UUID) ([]uuid.UUID, error)
+}
+
+type RecommendationRepository struct {
+	db *sqlx.DB
+}
+
+func NewRecommendationRepository(db *sqlx.DB) IRecommendationRepository {
+	return &RecommendationRepository{db}
+}
+
+// RefreshSimilarities rebuilds book_similarities from purchase co-occurrence,
+// scored by cosine similarity over buyers and keeping the top neighbours per book
+func (r *RecommendationRepository) RefreshSimilarities(neighbours int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM book_similarities`); err != nil {
+		return err
+	}
+
+	query := `
+		WITH purchases AS (` + purchasedBooks + `
+		), buyers AS (
+			SELECT book_id, COUNT(*) AS buyers FROM purchases GROUP BY book_id
+		), pairs AS (
+			SELECT a.book_id, b.book_id AS related_id, COUNT(*) AS co_purchases
+			FROM purchases a
+			INNER JOIN purchases b ON b.user_id = a.user_id AND b.book_id <> a.book_id
+			GROUP BY a.book_id, b.book_id
+		), scored AS (
+			SELECT pairs.book_id, pairs.related_id, pairs.co_purchases,
+				pairs.co_purchases / SQRT(a.buyers * b.buyers) AS score
+			FROM pairs
+			INNER JOIN buyers a ON a.book_id = pairs.book_id
+			INNER JOIN buyers b ON b.book_id = pairs.related_id
+		), ranked AS (
+			SELECT scored.*, ROW_NUMBER() OVER (
+				PARTITION BY book_id ORDER BY score DESC, co_purchases DESC
+			) AS position
+			FROM scored
+		)
+		INSERT INTO book_similarities (book_id, related_id, score, co_purchases, updated_at)
+		SELECT book_id, related_id, score, co_purchases, NOW()
+		FROM ranked
+		WHERE position <= $1
+	`
+	if _, err := tx.Exec(query, neighbours); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RecommendationRepository) GetRelatedBooks(books *[]entity.Book, bookId uuid.UUID, limit int) error {
+	query := `
+		SELECT ` + bookColumns + `
+		FROM book_similarities
+		INNER JOIN books ON books.id = book_similarities.related_id
+		WHERE book_similarities.book_id = $1 AND ` + activeBook + `
+		ORDER BY book_similarities.score DESC, book_similarities.co_purchases DESC
+		LIMIT $2
+	`
+	*books = []entity.Book{}
+	return r.db.Select(books, query, bookId, limit)
+}
+
+// GetRecommendedBooks sums the similarities of everything the user bought or
+// rated 4+ stars, leaving out the seed books themselves
+func (r *RecommendationRepository) GetRecommendedBooks(books *[]entity.Book, userId uuid.UUID, limit int) error {
+	query := `
+		WITH seeds AS (
+			SELECT book_id, 1.0 AS weight FROM (` + purchasedBooks + `) purchases WHERE user_id = $1
+			UNION ALL
+			SELECT book_id, (rating - 3) * 0.5 AS weight FROM comments WHERE user_id = $1 AND rating >= 4
+		), scored AS (
+			SELECT book_similarities.related_id, SUM(book_similarities.score * seeds.weight) AS score
+			FROM seeds
+			INNER JOIN book_similarities ON book_similarities.book_id = seeds.book_id
+			WHERE book_similarities.related_id NOT IN (SELECT book_id FROM seeds)
+			GROUP BY book_similarities.related_id
+		)
+		SELECT ` + bookColumns + `
+		FROM scored
+		INNER JOIN books ON books.id = scored.related_id
+		WHERE ` + activeBook + `
+		ORDER BY scored.score DESC, books.release_date DESC
+		LIMIT $2
+	`
+	*books = []entity.Book{}
+	return r.db.Select(books, query, userId, limit)
+}
+
+// GetSimilarBooks is the cold-start fallback: books sharing an author or a
+// category with any seed, authors weighing more than categories
+func (r *RecommendationRepository) GetSimilarBooks(books *[]entity.Book, seedIds []uuid.UUID, excludeIds []uuid.UUID, limit int) error {
+	query := `
+		SELECT ` + bookColumns + `
+		FROM books
+		LEFT JOIN (
+			SELECT book_id, COUNT(*) AS shared FROM book_authors
+			WHERE author_id IN (SELECT author_id FROM book_authors WHERE book_id = ANY($1))
+			GROUP BY book_id
+		) authors ON authors.book_id = books.id
+		LEFT JOIN (
+			SELECT book_id, COUNT(*) AS shared FROM book_categories
+			WHERE category_id IN (SELECT category_id FROM book_categories WHERE book_id = ANY($1))
+			GROUP BY book_id
+		) categories ON categories.book_id = books.id
+		WHERE ` + activeBook + `
+			AND NOT books.id = ANY($2)
+			AND (
+				authors.shared IS NOT NULL OR categories.shared IS NOT NULL
+				OR books.author IN (SELECT author FROM books WHERE id = ANY($1))
+			)
+		ORDER BY 2 * COALESCE(authors.shared, 0) + COALESCE(categories.shared, 0) DESC, books.release_date DESC
+		LIMIT $3
+	`
+	*books = []entity.Book{}
+	return r.db.Select(books, query, pq.Array(uuidStrings(seedIds)), pq.Array(uuidStrings(excludeIds)), limit)
+}
+
+func (r *RecommendationRepository) GetUserSeeds(userId uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT book_id FROM (` + purchasedBooks + `) purchases WHERE user_id = $1
+		UNION
+		SELECT book_id FROM comments WHERE user_id = $1 AND rating >= 4
+	`
+	seeds := []uuid.UUID{}
+	err := r.db.Select(&seeds, query, userId)
+	return seeds, err
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strs
+}
@@ -6,23 +6,61 @@ import (
 )
 
 type Repository struct {
-	UserRepository     IUserRepository
-	AuthRepository     IAuthRepository
-	BookRepository     IBookRepository
-	CartRepository     ICartRepository
-	CommentRepository  ICommentRepository
-	CheckoutRepository ICheckoutRepository
-	PaymentRepository  IPaymentRepository
+	UserRepository           IUserRepository
+	AuthRepository           IAuthRepository
+	BookRepository           IBookRepository
+	CartRepository           ICartRepository
+	CommentRepository        ICommentRepository
+	CheckoutRepository       ICheckoutRepository
+	PaymentRepository        IPaymentRepository
+	UploadRepository         IUploadRepository
+	NotificationRepository   INotificationRepository
+	CategoryRepository       ICategoryRepository
+	AuthorRepository         IAuthorRepository
+	PublisherRepository      IPublisherRepository
+	BookRevisionRepository   IBookRevisionRepository
+	DownloadRepository       IDownloadRepository
+	SampleRepository         ISampleRepository
+	RecommendationRepository IRecommendationRepository
+	RankingRepository        IRankingRepository
+	WishlistRepository       IWishlistRepository
+	InventoryRepository      IInventoryRepository
+	BookFormatRepository     IBookFormatRepository
+	PromotionRepository      IPromotionRepository
+	SaleRepository           ISaleRepository
+	PreorderRepository       IPreorderRepository
+	SeriesRepository         ISeriesRepository
+	BundleRepository         IBundleRepository
+	ReadingListRepository    IReadingListRepository
 }
 
 func NewRepository(db *sqlx.DB, redis *redis.Client) *Repository {
 	return &Repository{
-		UserRepository:     NewUserRepository(db),
-		AuthRepository:     NewAuthRepository(db, redis),
-		BookRepository:     NewBookRepository(db),
-		CartRepository:     NewCartRepository(db),
-		CommentRepository:  NewCommentRepository(db),
-		CheckoutRepository: NewCheckoutRepository(db),
-		PaymentRepository:  NewPaymentRepository(db),
+		UserRepository:           NewUserRepository(db),
+		AuthRepository:           NewAuthRepository(db, redis),
+		BookRepository:           NewBookRepository(db, redis),
+		CartRepository:           NewCartRepository(db),
+		CommentRepository:        NewCommentRepository(db),
+		CheckoutRepository:       NewCheckoutRepository(db),
+		PaymentRepository:        NewPaymentRepository(db),
+		UploadRepository:         NewUploadRepository(db),
+		NotificationRepository:   NewNotificationRepository(db),
+		CategoryRepository:       NewCategoryRepository(db),
+		AuthorRepository:         NewAuthorRepository(db),
+		PublisherRepository:      NewPublisherRepository(db),
+		BookRevisionRepository:   NewBookRevisionRepository(db),
+		DownloadRepository:       NewDownloadRepository(db),
+		SampleRepository:         NewSampleRepository(db),
+		RecommendationRepository: NewRecommendationRepository(db),
+		RankingRepository:        NewRankingRepository(db),
+		WishlistRepository:       NewWishlistRepository(db),
+		InventoryRepository:      NewInventoryRepository(db),
+		BookFormatRepository:     NewBookFormatRepository(db),
+		PromotionRepository:      NewPromotionRepository(db),
+		SaleRepository:           NewSaleRepository(db),
+		PreorderRepository:       NewPreorderRepository(db),
+		SeriesRepository:         NewSeriesRepository(db),
+		BundleRepository:         NewBundleRepository(db),
+		ReadingListRepository:    NewReadingListRepository(db),
 	}
 }
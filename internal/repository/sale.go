@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const activeSale = `book_sales.starts_at <= NOW() AND book_sales.ends_at > NOW()
+	AND (book_sales.quantity IS NULL OR book_sales.sold < book_sales.quantity)`
+
+// formatPrices resolves each format's effective price: the cheapest running
+// sale, else the list price. The lowest price over the last 30 days also
+// counts the price that was already in effect when the window opened.
+const formatPrices = `
+	SELECT book_formats.id AS format_id, book_formats.book_id, book_formats.format,
+		book_formats.price AS list_price,
+		COALESCE(sale.sale_price, book_formats.price) AS effective_price,
+		sale.id AS sale_id, sale.ends_at AS sale_ends_at, sale.quantity - sale.sold AS sale_remaining,
+		LEAST(COALESCE(sale.sale_price, book_formats.price), COALESCE(history.lowest, book_formats.price)) AS lowest_price_30d
+	FROM book_formats
+	LEFT JOIN LATERAL (
+		SELECT book_sales.* FROM book_sales
+		WHERE book_sales.format_id = book_formats.id AND ` + activeSale + `
+		ORDER BY book_sales.sale_price, book_sales.ends_at
+		LIMIT 1
+	) sale ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT MIN(price_history.price) AS lowest FROM price_history
+		WHERE price_history.format_id = book_formats.id AND price_history.recorded_at >= (
+			SELECT COALESCE(MAX(earlier.recorded_at), NOW() - INTERVAL '30 days') FROM price_history earlier
+			WHERE earlier.format_id = book_formats.id AND earlier.recorded_at <= NOW() - INTERVAL '30 days'
+		)
+	) history ON TRUE
+`
+
+// effectiveBookPrice is the price a book sells for as an ebook right now
+const effectiveBookPrice = `COALESCE((
+	SELECT COALESCE(MIN(book_sales.sale_price), book_formats.price) FROM book_formats
+	LEFT JOIN book_sales ON book_sales.format_id = book_formats.id AND ` + activeSale + `
+	WHERE book_formats.book_id = books.id AND book_formats.format = 'ebook'
+	GROUP BY book_formats.price
+), books.price)`
+
+type ISaleRepository interface {
+	GetBookSales(sales *[]entity.Sale, bookId uuid.UUID) error
+	CreateSale(sale *entity.Sale) error
+	EndSale(saleId uuid.UUID) error
+	GetFormatPrices(prices *[]model.FormatPrice, bookIds []uuid.UUID) error
+	GetFormatPrice(price *model.FormatPrice, formatId uuid.UUID) error
+	GetPriceHistory(history *[]entity.PriceHistory, bookId uuid.UUID, req model.PriceHistoryReq) error
+	RecordPrices() error
+}
+
+type SaleRepository struct {
+	db *sqlx.DB
+}
+
+func NewSaleRepository(db *sqlx.DB) ISaleRepository {
+	return &SaleRepository{db}
+}
+
+func (r *SaleRepository) GetBookSales(sales *[]entity.Sale, bookId uuid.UUID) error {
+	query := `SELECT * FROM book_sales WHERE book_id = $1 ORDER BY starts_at DESC`
+	*sales = []entity.Sale{}
+	return r.db.Select(sales, query, bookId)
+}
+
+func (r *SaleRepository) CreateSale(sale *entity.Sale) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO book_sales (id, book_id, format_id, sale_price, starts_at, ends_at, quantity, sold, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8, $9)
+	`
+	_, err = tx.Exec(query, sale.Id, sale.BookId, sale.FormatId, sale.SalePrice, sale.StartsAt, sale.EndsAt, sale.Quantity, nullableUUID(sale.CreatedBy), sale.CreatedAt)
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) && (pgErr.Code == "23503" || pgErr.Code == "23514") {
+		return &response.BadRequest
+	}
+	if err != nil {
+		return err
+	}
+
+	// a sale starting now changes the price at once, later ones are left to the poll
+	if err := recordPrices(tx, "book_formats.id = $1", sale.FormatId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// EndSale stops a running or upcoming sale now, keeping it for the history
+func (r *SaleRepository) EndSale(saleId uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var formatId string
+	query := `UPDATE book_sales SET ends_at = GREATEST(NOW(), starts_at) WHERE id = $1 AND ends_at > NOW() RETURNING format_id`
+	err = tx.Get(&formatId, query, saleId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.SaleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := recordPrices(tx, "book_formats.id = $1", formatId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SaleRepository) GetFormatPrices(prices *[]model.FormatPrice, bookIds []uuid.UUID) error {
+	*prices = []model.FormatPrice{}
+	if len(bookIds) == 0 {
+		return nil
+	}
+
+	query := formatPrices + ` WHERE book_formats.book_id = ANY($1) ORDER BY book_formats.price, book_formats.format`
+	return r.db.Select(prices, query, pq.Array(uuidStrings(bookIds)))
+}
+
+func (r *SaleRepository) GetFormatPrice(price *model.FormatPrice, formatId uuid.UUID) error {
+	query := formatPrices + ` WHERE book_formats.id = $1`
+	err := r.db.Get(price, query, formatId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.FormatNotFound
+	}
+	return err
+}
+
+func (r *SaleRepository) GetPriceHistory(history *[]entity.PriceHistory, bookId uuid.UUID, req model.PriceHistoryReq) error {
+	b := newQuery("price_history", "price_history.*").
+		Where("price_history.book_id = ?", bookId).
+		Where("price_history.recorded_at >= NOW() - make_interval(days => ?)", req.Days)
+
+	if req.FormatId != uuid.Nil {
+		b.Where("price_history.format_id = ?", req.FormatId)
+	}
+
+	query, args := b.OrderBy("price_history.recorded_at DESC").Build()
+
+	*history = []entity.PriceHistory{}
+	return r.db.Select(history, query, args...)
+}
+
+// RecordPrices appends a history row for every format whose effective price
+// moved since its last row, which catches sales starting, ending and selling out
+func (r *SaleRepository) RecordPrices() error {
+	return recordPrices(r.db, "TRUE")
+}
+
+// recordPrices appends a history row for the formats matching where whose
+// effective price moved since their last row. Edits call it in their own
+// transaction so the history never misses a change between polls.
+func recordPrices(db sqlx.Execer, where string, args ...any) error {
+	query := `
+		INSERT INTO price_history (id, book_id, format_id, price, sale_id, recorded_at)
+		SELECT gen_random_uuid()::text, current.book_id, current.format_id, current.effective_price, current.sale_id, NOW()
+		FROM (` + formatPrices + ` WHERE ` + where + `) current
+		WHERE current.effective_price IS DISTINCT FROM (
+			SELECT price_history.price FROM price_history
+			WHERE price_history.format_id = current.format_id
+			ORDER BY price_history.recorded_at DESC
+			LIMIT 1
+		)
+	`
+	_, err := db.Exec(query, args...)
+	return err
+}
+
+// claimSale takes flash sale units for a checkout. The conditional update
+// locks the sale row, so concurrent checkouts can not oversell it.
+func claimSale(tx *sqlx.Tx, checkoutId uuid.UUID, saleId uuid.UUID, quantity int) error {
+	query := `
+		UPDATE book_sales SET sold = sold + $2
+		WHERE id = $1 AND starts_at <= NOW() AND ends_at > NOW()
+			AND (quantity IS NULL OR sold + $2 <= quantity)
+	`
+	result, err := tx.Exec(query, saleId, quantity)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.SaleUnavailable
+	}
+
+	query = `
+		INSERT INTO sale_claims (sale_id, checkout_id, quantity, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (sale_id, checkout_id) DO UPDATE SET quantity = sale_claims.quantity + EXCLUDED.quantity
+	`
+	_, err = tx.Exec(query, saleId, checkoutId, quantity, entity.ReservationHeld)
+	return err
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type ISampleRepository interface {
+	GetSample(sample *entity.BookSample, bookId uuid.UUID) error
+	SaveSample(sample *entity.BookSample) error
+	DeleteSample(bookId uuid.UUID) (string, error)
+	CreatePreview(preview *entity.Preview) error
+	GetConversions(conversions *[]model.SampleConversion, since time.Time) error
+}
+
+type SampleRepository struct {
+	db *sqlx.DB
+}
+
+func NewSampleRepository(db *sqlx.DB) ISampleRepository {
+	return &SampleRepository{db}
+}
+
+func (r *SampleRepository) GetSample(sample *entity.BookSample, bookId uuid.UUID) error {
+	query := `SELECT * FROM book_samples WHERE book_id = $1`
+	err := r.db.Get(sample, query, bookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.SampleNotFound
+	}
+	return err
+}
+
+func (r *SampleRepository) SaveSample(sample *entity.BookSample) error {
+	query := `
+		INSERT INTO book_samples (book_id, mode, count, path, source_file, updated_at)
+		VALUES (:book_id, :mode, :count, :path, :source_file, :updated_at)
+		ON CONFLICT (book_id) DO UPDATE
+		SET mode = EXCLUDED.mode, count = EXCLUDED.count, path = EXCLUDED.path,
+			source_file = EXCLUDED.source_file, updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.NamedExec(query, sample)
+	return err
+}
+
+func (r *SampleRepository) DeleteSample(bookId uuid.UUID) (string, error) {
+	var path string
+	query := `DELETE FROM book_samples WHERE book_id = $1 RETURNING path`
+	err := r.db.Get(&path, query, bookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", &response.SampleNotFound
+	}
+	return path, err
+}
+
+func (r *SampleRepository) CreatePreview(preview *entity.Preview) error {
+	query := `INSERT INTO book_previews (id, user_id, book_id, created_at) VALUES (:id, :user_id, :book_id, :created_at)`
+	_, err := r.db.NamedExec(query, preview)
+	return err
+}
+
+// GetConversions counts, per book, the users who previewed it since the given
+// time and how many of them went on to buy it after their first preview
+func (r *SampleRepository) GetConversions(conversions *[]model.SampleConversion, since time.Time) error {
+	query := `
+		WITH first_previews AS (
+			SELECT book_id, user_id, MIN(created_at) AS previewed_at
+			FROM book_previews
+			WHERE user_id IS NOT NULL AND created_at >= $1
+			GROUP BY book_id, user_id
+		), converted AS (
+			SELECT first_previews.*, EXISTS (
+				SELECT 1 FROM payments
+				INNER JOIN carts ON carts.checkout_id = payments.checkout_id
+				WHERE payments.user_id = first_previews.user_id
+					AND carts.book_id = first_previews.book_id
+					AND ` + paidPayment + `
+					AND payments.created_at >= first_previews.previewed_at
+			) AS purchased
+			FROM first_previews
+		)
+		SELECT books.id AS book_id, books.title,
+			COUNT(*) AS previewers,
+			COUNT(*) FILTER (WHERE purchased) AS conversions,
+			ROUND(COUNT(*) FILTER (WHERE purchased)::numeric / COUNT(*), 4)::float AS rate
+		FROM converted
+		INNER JOIN books ON books.id = converted.book_id
+		GROUP BY books.id, books.title
+		ORDER BY conversions DESC, previewers DESC
+	`
+	*conversions = []model.SampleConversion{}
+	return r.db.Select(conversions, query, since)
+}
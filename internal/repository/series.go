@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ISeriesRepository interface {
+	GetAllSeries(series *[]entity.Series) error
+	GetSeries(series *entity.Series, seriesId uuid.UUID) error
+	CreateSeries(series *entity.Series) error
+	EditSeries(series *entity.Series) error
+	DeleteSeries(seriesId uuid.UUID) error
+	GetSeriesBooks(books *[]model.SeriesBookRow, seriesId uuid.UUID) error
+	SetSeriesBooks(seriesId uuid.UUID, books []entity.SeriesBook) error
+	GetBookSeries(links *[]model.SeriesLinkRow, bookId uuid.UUID) error
+}
+
+type SeriesRepository struct {
+	db *sqlx.DB
+}
+
+func NewSeriesRepository(db *sqlx.DB) ISeriesRepository {
+	return &SeriesRepository{db}
+}
+
+func (r *SeriesRepository) GetAllSeries(series *[]entity.Series) error {
+	query := `SELECT * FROM series ORDER BY name ASC`
+	*series = []entity.Series{}
+	return r.db.Select(series, query)
+}
+
+func (r *SeriesRepository) GetSeries(series *entity.Series, seriesId uuid.UUID) error {
+	query := `SELECT * FROM series WHERE id = $1`
+	err := r.db.Get(series, query, seriesId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &response.SeriesNotFound
+	}
+	return err
+}
+
+func (r *SeriesRepository) CreateSeries(series *entity.Series) error {
+	query := `INSERT INTO series (id, name, description, created_at) VALUES (:id, :name, :description, :created_at)`
+	_, err := r.db.NamedExec(query, series)
+	return err
+}
+
+func (r *SeriesRepository) EditSeries(series *entity.Series) error {
+	query := `UPDATE series SET name = :name, description = :description WHERE id = :id`
+	_, err := r.db.NamedExec(query, series)
+	return err
+}
+
+func (r *SeriesRepository) DeleteSeries(seriesId uuid.UUID) error {
+	query := `DELETE FROM series WHERE id = $1`
+	result, err := r.db.Exec(query, seriesId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.SeriesNotFound
+	}
+
+	return nil
+}
+
+func (r *SeriesRepository) GetSeriesBooks(books *[]model.SeriesBookRow, seriesId uuid.UUID) error {
+	query := `
+		SELECT ` + bookColumns + `, series_books.position, series_books.label
+		FROM series_books
+		INNER JOIN books ON books.id = series_books.book_id
+		WHERE series_books.series_id = $1 AND ` + activeBook + `
+		ORDER BY series_books.position ASC
+	`
+	*books = []model.SeriesBookRow{}
+	return r.db.Select(books, query, seriesId)
+}
+
+func (r *SeriesRepository) SetSeriesBooks(seriesId uuid.UUID, books []entity.SeriesBook) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM series_books WHERE series_id = $1`, seriesId); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO series_books (series_id, book_id, position, label) VALUES (:series_id, :book_id, :position, :label)`
+	for _, book := range books {
+		if _, err := tx.NamedExec(query, book); err != nil {
+			var pgErr *pq.Error
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case "23505":
+					return &response.DuplicateSeriesBook
+				case "23503":
+					return &response.BookNotFound
+				}
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetBookSeries finds the book's neighbours in every series it belongs to,
+// skipping trashed volumes
+func (r *SeriesRepository) GetBookSeries(links *[]model.SeriesLinkRow, bookId uuid.UUID) error {
+	query := `
+		WITH volumes AS (
+			SELECT series_books.series_id, series_books.book_id, series_books.position, series_books.label,
+				LAG(books.id) OVER reading AS previous_id,
+				COALESCE(LAG(books.title) OVER reading, '') AS previous_title,
+				COALESCE(LAG(series_books.label) OVER reading, '') AS previous_label,
+				LEAD(books.id) OVER reading AS next_id,
+				COALESCE(LEAD(books.title) OVER reading, '') AS next_title,
+				COALESCE(LEAD(series_books.label) OVER reading, '') AS next_label
+			FROM series_books
+			INNER JOIN books ON books.id = series_books.book_id
+			WHERE ` + activeBook + ` AND series_books.series_id IN (
+				SELECT series_id FROM series_books WHERE book_id = $1
+			)
+			WINDOW reading AS (PARTITION BY series_books.series_id ORDER BY series_books.position)
+		)
+		SELECT volumes.series_id, series.name, volumes.position, volumes.label,
+			volumes.previous_id, volumes.previous_title, volumes.previous_label,
+			volumes.next_id, volumes.next_title, volumes.next_label
+		FROM volumes
+		INNER JOIN series ON series.id = volumes.series_id
+		WHERE volumes.book_id = $1
+		ORDER BY series.name ASC
+	`
+	*links = []model.SeriesLinkRow{}
+	return r.db.Select(links, query, bookId)
+}
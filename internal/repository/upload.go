@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IUploadRepository interface {
+	CreateUpload(upload *entity.Upload) error
+	ReferenceUpload(url string) error
+	SupersedeUpload(url string) error
+	GetExpiredUploads(uploads *[]entity.Upload, before time.Time) error
+	DeleteUploads(uploadIds []uuid.UUID) error
+}
+
+type UploadRepository struct {
+	db *sqlx.DB
+}
+
+func NewUploadRepository(db *sqlx.DB) IUploadRepository {
+	return &UploadRepository{db}
+}
+
+func (r *UploadRepository) CreateUpload(upload *entity.Upload) error {
+	query := `
+		INSERT INTO uploads (id, owner_id, purpose, path, url, status, created_at, updated_at)
+		VALUES (:id, :owner_id, :purpose, :path, :url, :status, :created_at, :updated_at)
+	`
+	_, err := r.db.NamedExec(query, upload)
+	return err
+}
+
+// urls that were not uploaded through us (e.g. seeded covers) simply match no rows
+func (r *UploadRepository) ReferenceUpload(url string) error {
+	query := `UPDATE uploads SET status = $1, updated_at = NOW() WHERE url = $2`
+	_, err := r.db.Exec(query, entity.UploadReferenced, url)
+	return err
+}
+
+// SupersedeUpload releases an upload its previous owner no longer shows. The
+// same url can be set on several rows, so it stays referenced while any book,
+// trashed ones included since they can be restored, or other owner still uses it.
+func (r *UploadRepository) SupersedeUpload(url string) error {
+	query := `
+		UPDATE uploads SET status = $1, updated_at = NOW()
+		WHERE url = $2
+			AND NOT EXISTS (SELECT 1 FROM books WHERE books.image = uploads.url)
+			AND NOT EXISTS (SELECT 1 FROM authors WHERE authors.photo = uploads.url)
+			AND NOT EXISTS (SELECT 1 FROM publishers WHERE publishers.logo = uploads.url)
+			AND NOT EXISTS (SELECT 1 FROM users WHERE users.profile_picture = uploads.url)
+	`
+	_, err := r.db.Exec(query, entity.UploadSuperseded, url)
+	return err
+}
+
+// GetExpiredUploads keeps superseded covers that a book revision can still put back
+func (r *UploadRepository) GetExpiredUploads(uploads *[]entity.Upload, before time.Time) error {
+	query := `
+		SELECT * FROM uploads
+		WHERE status IN ($1, $2) AND updated_at < $3 AND NOT EXISTS (
+			SELECT 1 FROM book_revisions WHERE book_revisions.changes->'image'->>'old' = uploads.url
+		)
+	`
+	return r.db.Select(uploads, query, entity.UploadPending, entity.UploadSuperseded, before)
+}
+
+func (r *UploadRepository) DeleteUploads(uploadIds []uuid.UUID) error {
+	ids := make([]string, len(uploadIds))
+	for i, id := range uploadIds {
+		ids[i] = id.String()
+	}
+
+	query := `DELETE FROM uploads WHERE id = ANY($1)`
+	_, err := r.db.Exec(query, pq.Array(ids))
+	return err
+}
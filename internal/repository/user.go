@@ -87,9 +87,9 @@ func (r *UserRepository) UpdateRole(userId uuid.UUID, roleId int) error {
 }
 
 func (r *UserRepository) EditUser(edit *model.EditProfile) error {
-	query := `UPDATE users SET username = $1, profile_picture = $2 WHERE id = $3`
+	query := `UPDATE users SET username = $1, profile_picture = $2, is_private = $3 WHERE id = $4`
 
-	_, err := r.db.Exec(query, edit.Username, edit.ProfilePicture, edit.Id)
+	_, err := r.db.Exec(query, edit.Username, edit.ProfilePicture, *edit.IsPrivate, edit.Id)
 	return err
 }
 
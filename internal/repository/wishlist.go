@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type IWishlistRepository interface {
+	GetWishlist(items *[]model.WishlistItem, userId uuid.UUID) error
+	AddToWishlist(userId uuid.UUID, bookId uuid.UUID) error
+	RemoveFromWishlist(userId uuid.UUID, bookId uuid.UUID) error
+	GetPriceDrops(alerts *[]model.WishlistAlert) error
+	SetAlertPrice(userId uuid.UUID, bookId uuid.UUID, price float64) error
+	RaiseAlertPrices() error
+	GetReleases(alerts *[]model.WishlistAlert) error
+	MarkReleaseAlerted(userId uuid.UUID, bookId uuid.UUID) error
+}
+
+type WishlistRepository struct {
+	db *sqlx.DB
+}
+
+func NewWishlistRepository(db *sqlx.DB) IWishlistRepository {
+	return &WishlistRepository{db}
+}
+
+func (r *WishlistRepository) GetWishlist(items *[]model.WishlistItem, userId uuid.UUID) error {
+	query := `
+		SELECT ` + bookColumns + `, wishlists.created_at AS added_at
+		FROM wishlists
+		INNER JOIN books ON books.id = wishlists.book_id
+		WHERE wishlists.user_id = $1 AND ` + activeBook + `
+		ORDER BY wishlists.created_at DESC
+	`
+	*items = []model.WishlistItem{}
+	return r.db.Select(items, query, userId)
+}
+
+// AddToWishlist snapshots the current price as the price-drop baseline; books
+// already released are marked so only pre-announced ones get a release alert
+func (r *WishlistRepository) AddToWishlist(userId uuid.UUID, bookId uuid.UUID) error {
+	query := `
+		INSERT INTO wishlists (user_id, book_id, alert_price, release_alerted, created_at)
+		SELECT $1, books.id, ` + effectiveBookPrice + `, books.release_date <= CURRENT_DATE, NOW()
+		FROM books
+		WHERE books.id = $2 AND ` + activeBook
+	result, err := r.db.Exec(query, userId, bookId)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return &response.AlreadyWishlisted
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.BookNotFound
+	}
+
+	return nil
+}
+
+func (r *WishlistRepository) RemoveFromWishlist(userId uuid.UUID, bookId uuid.UUID) error {
+	query := `DELETE FROM wishlists WHERE user_id = $1 AND book_id = $2`
+	result, err := r.db.Exec(query, userId, bookId)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return &response.WishlistNotFound
+	}
+
+	return nil
+}
+
+func (r *WishlistRepository) GetPriceDrops(alerts *[]model.WishlistAlert) error {
+	query := `
+		SELECT wishlists.user_id, wishlists.book_id, books.title, wishlists.alert_price, ` + effectiveBookPrice + ` AS price
+		FROM wishlists
+		INNER JOIN books ON books.id = wishlists.book_id
+		WHERE ` + effectiveBookPrice + ` < wishlists.alert_price AND ` + activeBook
+	*alerts = []model.WishlistAlert{}
+	return r.db.Select(alerts, query)
+}
+
+func (r *WishlistRepository) SetAlertPrice(userId uuid.UUID, bookId uuid.UUID, price float64) error {
+	query := `UPDATE wishlists SET alert_price = $1 WHERE user_id = $2 AND book_id = $3`
+	_, err := r.db.Exec(query, price, userId, bookId)
+	return err
+}
+
+// RaiseAlertPrices follows price increases, so dropping back after a rise or
+// a new sale after one ends alerts again
+func (r *WishlistRepository) RaiseAlertPrices() error {
+	query := `
+		UPDATE wishlists SET alert_price = ` + effectiveBookPrice + `
+		FROM books
+		WHERE books.id = wishlists.book_id AND ` + effectiveBookPrice + ` > wishlists.alert_price
+	`
+	_, err := r.db.Exec(query)
+	return err
+}
+
+func (r *WishlistRepository) GetReleases(alerts *[]model.WishlistAlert) error {
+	query := `
+		SELECT wishlists.user_id, wishlists.book_id, books.title, wishlists.alert_price, ` + effectiveBookPrice + ` AS price
+		FROM wishlists
+		INNER JOIN books ON books.id = wishlists.book_id
+		WHERE NOT wishlists.release_alerted AND books.release_date <= CURRENT_DATE AND ` + activeBook
+	*alerts = []model.WishlistAlert{}
+	return r.db.Select(alerts, query)
+}
+
+func (r *WishlistRepository) MarkReleaseAlerted(userId uuid.UUID, bookId uuid.UUID) error {
+	query := `UPDATE wishlists SET release_alerted = TRUE WHERE user_id = $1 AND book_id = $2`
+	_, err := r.db.Exec(query, userId, bookId)
+	return err
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"mime/multipart"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/supabase"
+	"github.com/google/uuid"
+)
+
+type IAuthorService interface {
+	GetAuthors(authorReq model.AuthorReq) (*[]entity.Author, error)
+	GetAuthor(authorId uuid.UUID) (*model.AuthorResponse, error)
+	CreateAuthor(create model.CreateAuthor) error
+	EditAuthor(edit model.EditAuthor) error
+	DeleteAuthor(authorId uuid.UUID) error
+	UploadAuthorPhoto(file *multipart.FileHeader, userId uuid.UUID) (string, error)
+}
+
+type AuthorService struct {
+	authorRepo repository.IAuthorRepository
+	uploadRepo repository.IUploadRepository
+	Supabase   supabase.ISupabase
+}
+
+func NewAuthorService(authorRepo repository.IAuthorRepository, uploadRepo repository.IUploadRepository, Supabase supabase.ISupabase) IAuthorService {
+	return &AuthorService{
+		authorRepo: authorRepo,
+		uploadRepo: uploadRepo,
+		Supabase:   Supabase,
+	}
+}
+
+func (s *AuthorService) GetAuthors(authorReq model.AuthorReq) (*[]entity.Author, error) {
+	var authors []entity.Author
+	if err := s.authorRepo.GetAuthors(&authors, authorReq.Page, authorReq.PageSize, authorReq.Search); err != nil {
+		return nil, err
+	}
+
+	return &authors, nil
+}
+
+func (s *AuthorService) GetAuthor(authorId uuid.UUID) (*model.AuthorResponse, error) {
+	var author entity.Author
+	if err := s.authorRepo.GetAuthor(&author, authorId); err != nil {
+		return nil, err
+	}
+
+	var books []model.AuthorBook
+	if err := s.authorRepo.GetAuthorBooks(&books, authorId); err != nil {
+		return nil, err
+	}
+
+	booksResponse := make([]model.AuthorBookResponse, len(books))
+	for i, book := range books {
+		booksResponse[i] = model.AuthorBookResponse{
+			BookResponse: model.BookToBookResponse(book.Book),
+			Role:         book.Role,
+		}
+	}
+
+	return &model.AuthorResponse{
+		Author: author,
+		Books:  booksResponse,
+	}, nil
+}
+
+func (s *AuthorService) CreateAuthor(create model.CreateAuthor) error {
+	if err := s.authorRepo.CreateAuthor(&entity.Author{
+		Id:    uuid.New(),
+		Name:  create.Name,
+		Bio:   create.Bio,
+		Photo: create.Photo,
+	}); err != nil {
+		return err
+	}
+
+	return s.uploadRepo.ReferenceUpload(create.Photo)
+}
+
+func (s *AuthorService) EditAuthor(edit model.EditAuthor) error {
+	var author entity.Author
+	if err := s.authorRepo.GetAuthor(&author, edit.Id); err != nil {
+		return err
+	}
+
+	oldPhoto := author.Photo
+	if edit.Name != "" {
+		author.Name = edit.Name
+	}
+	if edit.Bio != "" {
+		author.Bio = edit.Bio
+	}
+	if edit.Photo != "" {
+		author.Photo = edit.Photo
+	}
+
+	if err := s.authorRepo.EditAuthor(&author); err != nil {
+		return err
+	}
+
+	if author.Photo != oldPhoto {
+		if err := s.uploadRepo.ReferenceUpload(author.Photo); err != nil {
+			return err
+		}
+
+		if err := s.uploadRepo.SupersedeUpload(oldPhoto); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *AuthorService) DeleteAuthor(authorId uuid.UUID) error {
+	var author entity.Author
+	if err := s.authorRepo.GetAuthor(&author, authorId); err != nil {
+		return err
+	}
+
+	if err := s.authorRepo.DeleteAuthor(authorId); err != nil {
+		return err
+	}
+
+	return s.uploadRepo.SupersedeUpload(author.Photo)
+}
+
+func (s *AuthorService) UploadAuthorPhoto(file *multipart.FileHeader, userId uuid.UUID) (string, error) {
+	path, url, err := s.Supabase.UploadFile(file, "author")
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.uploadRepo.CreateUpload(&entity.Upload{
+		Id:        uuid.New(),
+		OwnerId:   userId,
+		Purpose:   "author",
+		Path:      path,
+		Url:       url,
+		Status:    entity.UploadPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
@@ -1,11 +1,19 @@
 package service
 
 import (
+	"encoding/json"
+	"io"
 	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/ebook"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/supabase"
 	"github.com/google/uuid"
@@ -13,26 +21,64 @@ import (
 
 type IBookService interface {
 	GetBook(bookId uuid.UUID) (*model.BookResponse, error)
-	SearchBooks(bookSearch model.BookSearch) (*[]model.BookResponse, error)
+	SearchBooks(bookSearch model.BookSearch) (*model.BookSearchResponse, error)
+	SuggestBooks(prefix string, limit int) (*[]model.BookSuggestion, error)
 	CreateBook(create *model.CreateBook) error
-	DeleteBook(bookId uuid.UUID) error
-	EditBook(edit model.EditBook) error
-	UploadBookCover(file *multipart.FileHeader) (string, error)
+	DeleteBook(bookId uuid.UUID, deletedBy uuid.UUID) error
+	RestoreBook(bookId uuid.UUID) error
+	GetDeletedBooks(page, pageSize int) (*[]entity.Book, error)
+	EditBook(edit model.EditBook, editorId uuid.UUID) error
+	GetRevisions(bookId uuid.UUID, field string) (*[]model.BookRevisionResponse, error)
+	RevertBook(bookId uuid.UUID, revisionId uuid.UUID, editorId uuid.UUID) error
+	UploadBookCover(file *multipart.FileHeader, userId uuid.UUID) (string, error)
+	UploadBookFile(bookId uuid.UUID, file *multipart.FileHeader) error
+	DraftBookFromFile(file *multipart.FileHeader, userId uuid.UUID) (*model.BookDraft, error)
+	DownloadBook(bookId uuid.UUID, userId uuid.UUID, ipAddress string, userAgent string) (*model.BookDownload, error)
 }
 
 type BookService struct {
-	bookRepo    repository.IBookRepository
-	cartRepo    repository.ICartRepository
-	commentRepo repository.ICommentRepository
-	Supabase    supabase.ISupabase
+	bookRepo       repository.IBookRepository
+	cartRepo       repository.ICartRepository
+	commentRepo    repository.ICommentRepository
+	uploadRepo     repository.IUploadRepository
+	categoryRepo   repository.ICategoryRepository
+	authorRepo     repository.IAuthorRepository
+	publisherRepo  repository.IPublisherRepository
+	revisionRepo   repository.IBookRevisionRepository
+	paymentRepo    repository.IPaymentRepository
+	downloadRepo   repository.IDownloadRepository
+	userRepo       repository.IUserRepository
+	formatRepo     repository.IBookFormatRepository
+	saleRepo       repository.ISaleRepository
+	seriesRepo     repository.ISeriesRepository
+	previewService IPreviewService
+	Supabase       supabase.ISupabase
+	ebook          ebook.IEbook
+	downloadTTL    int
+	downloadLimit  int
 }
 
-func NewBookService(bookRepo repository.IBookRepository, cartRepo repository.ICartRepository, commentRepo repository.ICommentRepository, Supabase supabase.ISupabase) IBookService {
+func NewBookService(bookRepo repository.IBookRepository, cartRepo repository.ICartRepository, commentRepo repository.ICommentRepository, uploadRepo repository.IUploadRepository, categoryRepo repository.ICategoryRepository, authorRepo repository.IAuthorRepository, publisherRepo repository.IPublisherRepository, revisionRepo repository.IBookRevisionRepository, paymentRepo repository.IPaymentRepository, downloadRepo repository.IDownloadRepository, userRepo repository.IUserRepository, formatRepo repository.IBookFormatRepository, saleRepo repository.ISaleRepository, seriesRepo repository.ISeriesRepository, previewService IPreviewService, Supabase supabase.ISupabase, ebook ebook.IEbook) IBookService {
 	return &BookService{
-		bookRepo:    bookRepo,
-		cartRepo:    cartRepo,
-		commentRepo: commentRepo,
-		Supabase:    Supabase,
+		bookRepo:       bookRepo,
+		cartRepo:       cartRepo,
+		commentRepo:    commentRepo,
+		uploadRepo:     uploadRepo,
+		categoryRepo:   categoryRepo,
+		authorRepo:     authorRepo,
+		publisherRepo:  publisherRepo,
+		revisionRepo:   revisionRepo,
+		paymentRepo:    paymentRepo,
+		downloadRepo:   downloadRepo,
+		userRepo:       userRepo,
+		formatRepo:     formatRepo,
+		saleRepo:       saleRepo,
+		seriesRepo:     seriesRepo,
+		previewService: previewService,
+		Supabase:       Supabase,
+		ebook:          ebook,
+		downloadTTL:    envInt("DOWNLOAD_URL_TTL", 300),
+		downloadLimit:  envInt("DOWNLOAD_DAILY_LIMIT", 5),
 	}
 }
 
@@ -45,27 +91,159 @@ func (s *BookService) GetBook(bookId uuid.UUID) (*model.BookResponse, error) {
 
 	bookResponse := model.BookToBookResponse(book)
 
+	var rating entity.BookRating
+	if err := s.commentRepo.GetBookRating(&rating, bookId); err != nil {
+		return nil, err
+	}
+	bookResponse.Rating = model.RatingToRatingSummary(rating)
+
+	bookResponse.Categories = []entity.Category{}
+	if err := s.categoryRepo.GetBookCategories(&bookResponse.Categories, bookId); err != nil {
+		return nil, err
+	}
+
+	bookResponse.Authors = []model.BookAuthorResponse{}
+	if err := s.authorRepo.GetBookAuthors(&bookResponse.Authors, bookId); err != nil {
+		return nil, err
+	}
+
+	if err := s.saleRepo.GetFormatPrices(&bookResponse.Formats, []uuid.UUID{bookId}); err != nil {
+		return nil, err
+	}
+
+	var ebookPrice model.FormatPrice
+	for _, price := range bookResponse.Formats {
+		if price.Format == entity.FormatEbook {
+			ebookPrice = price
+		}
+	}
+	applyPrice(&bookResponse, ebookPrice)
+
+	var links []model.SeriesLinkRow
+	if err := s.seriesRepo.GetBookSeries(&links, bookId); err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		bookResponse.Series = append(bookResponse.Series, model.SeriesLinkRowToSeriesLink(link))
+	}
+
+	if book.PublisherId != uuid.Nil {
+		var publisher entity.Publisher
+		if err := s.publisherRepo.GetPublisher(&publisher, book.PublisherId); err != nil {
+			return nil, err
+		}
+		bookResponse.Publisher = &publisher
+	}
+
 	return &bookResponse, nil
 }
 
-func (s *BookService) SearchBooks(bookSearch model.BookSearch) (*[]model.BookResponse, error) {
-	var booksEntity []entity.Book
-	err := s.bookRepo.SearchBooks(&booksEntity, bookSearch.Page, bookSearch.PageSize, bookSearch.SearchParam)
+func (s *BookService) SearchBooks(bookSearch model.BookSearch) (*model.BookSearchResponse, error) {
+	var results []model.BookSearchResult
+	if err := s.bookRepo.SearchBooks(&results, bookSearch); err != nil {
+		return nil, err
+	}
+
+	total, err := s.bookRepo.CountBooks(bookSearch)
 	if err != nil {
 		return nil, err
 	}
 
-	booksResponse := make([]model.BookResponse, len(booksEntity))
-	for i, book := range booksEntity {
-		booksResponse[i] = model.BookToBookResponse(book)
+	var didYouMean string
+	if total == 0 && bookSearch.SearchParam != "" {
+		if err := s.bookRepo.FuzzySearchBooks(&results, bookSearch); err != nil {
+			return nil, err
+		}
+
+		total, err = s.bookRepo.CountFuzzyBooks(bookSearch)
+		if err != nil {
+			return nil, err
+		}
+
+		var suggestions []model.BookSuggestion
+		if err := s.bookRepo.GetSuggestions(&suggestions, bookSearch.SearchParam, 1); err != nil {
+			return nil, err
+		}
+
+		if len(suggestions) > 0 {
+			didYouMean = suggestions[0].Value
+		}
+	}
+
+	var facets model.BookFacets
+	if err := s.bookRepo.GetBookFacets(&facets, bookSearch); err != nil {
+		return nil, err
+	}
+
+	booksResponse := make([]model.BookResponse, len(results))
+	for i, result := range results {
+		booksResponse[i] = model.SearchResultToBookResponse(result)
+	}
+
+	if err := fillRatings(s.commentRepo, booksResponse); err != nil {
+		return nil, err
 	}
 
-	return &booksResponse, nil
+	if err := fillPrices(s.saleRepo, booksResponse); err != nil {
+		return nil, err
+	}
+
+	return &model.BookSearchResponse{
+		Books:      booksResponse,
+		Total:      total,
+		Facets:     &facets,
+		DidYouMean: didYouMean,
+	}, nil
+}
+
+// fillRatings attaches rating summaries to a page of books in one query
+func fillRatings(commentRepo repository.ICommentRepository, books []model.BookResponse) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	bookIds := make([]uuid.UUID, len(books))
+	for i, book := range books {
+		bookIds[i] = book.Id
+	}
+
+	var ratings []entity.BookRating
+	if err := commentRepo.GetBookRatings(&ratings, bookIds); err != nil {
+		return err
+	}
+
+	byBook := make(map[uuid.UUID]entity.BookRating, len(ratings))
+	for _, rating := range ratings {
+		byBook[rating.BookId] = rating
+	}
+
+	for i := range books {
+		if rating, ok := byBook[books[i].Id]; ok {
+			books[i].Rating = model.RatingToRatingSummary(rating)
+		}
+	}
+
+	return nil
+}
+
+func (s *BookService) SuggestBooks(prefix string, limit int) (*[]model.BookSuggestion, error) {
+	if limit < 1 || limit > 20 {
+		limit = 8
+	}
+
+	var suggestions []model.BookSuggestion
+	if err := s.bookRepo.GetSuggestions(&suggestions, prefix, limit); err != nil {
+		return nil, err
+	}
+
+	return &suggestions, nil
 }
 
 func (s *BookService) CreateBook(create *model.CreateBook) error {
-	return s.bookRepo.CreateBook(&entity.Book{
-		Id:           uuid.New(),
+	bookId := uuid.New()
+	if err := s.bookRepo.CreateBook(&entity.Book{
+		Id:           bookId,
 		Title:        create.Title,
 		Description:  create.Description,
 		Author:       create.Author,
@@ -73,17 +251,43 @@ func (s *BookService) CreateBook(create *model.CreateBook) error {
 		Price:        create.Price,
 		Introduction: create.Introduction,
 		Image:        create.Image,
-		File:         create.File,
-	})
-}
+		PublisherId:  create.PublisherId,
+	}); err != nil {
+		return err
+	}
 
-func (s *BookService) DeleteBook(bookId uuid.UUID) error {
-	var book entity.Book
-	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+	// every book starts out sellable as an ebook at its list price
+	if err := s.formatRepo.CreateFormat(&entity.BookFormat{
+		Id:        uuid.New(),
+		BookId:    bookId,
+		Format:    entity.FormatEbook,
+		Sku:       formatSku(entity.FormatEbook, bookId),
+		Price:     create.Price,
+		CreatedAt: time.Now(),
+	}); err != nil {
 		return err
 	}
 
-	if err := s.commentRepo.DeleteCommentByBook(bookId); err != nil {
+	if len(create.CategoryIds) > 0 {
+		if err := s.categoryRepo.SetBookCategories(bookId, create.CategoryIds); err != nil {
+			return err
+		}
+	}
+
+	if len(create.Authors) > 0 {
+		if err := s.authorRepo.SetBookAuthors(bookId, toBookAuthors(bookId, create.Authors)); err != nil {
+			return err
+		}
+	}
+
+	return s.uploadRepo.ReferenceUpload(create.Image)
+}
+
+// DeleteBook moves a book to the trash. Comments and the cover stay so a
+// restore brings the book back as it was; only unpaid carts are dropped.
+func (s *BookService) DeleteBook(bookId uuid.UUID, deletedBy uuid.UUID) error {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
 		return err
 	}
 
@@ -92,14 +296,27 @@ func (s *BookService) DeleteBook(bookId uuid.UUID) error {
 		return err
 	}
 
-	if err := s.bookRepo.DeleteBook(bookId); err != nil {
-		return err
+	return s.bookRepo.DeleteBook(bookId, deletedBy)
+}
+
+func (s *BookService) RestoreBook(bookId uuid.UUID) error {
+	return s.bookRepo.RestoreBook(bookId)
+}
+
+func (s *BookService) GetDeletedBooks(page, pageSize int) (*[]entity.Book, error) {
+	var books []entity.Book
+	if err := s.bookRepo.GetDeletedBooks(&books, page, pageSize); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &books, nil
+}
+
+func (s *BookService) EditBook(edit model.EditBook, editorId uuid.UUID) error {
+	return s.editBook(edit, editorId, uuid.Nil)
 }
 
-func (s *BookService) EditBook(edit model.EditBook) error {
+func (s *BookService) editBook(edit model.EditBook, editorId uuid.UUID, revertsId uuid.UUID) error {
 	var book entity.Book
 	if err := s.bookRepo.GetBook(&book, edit.Id); err != nil {
 		return err
@@ -128,14 +345,397 @@ func (s *BookService) EditBook(edit model.EditBook) error {
 		edit.Price = book.Price
 	}
 
-	if err := s.bookRepo.EditBook(&edit); err != nil {
+	var categories []entity.Category
+	if err := s.categoryRepo.GetBookCategories(&categories, edit.Id); err != nil {
 		return err
 	}
 
+	categoryIds := make([]uuid.UUID, len(categories))
+	for i, category := range categories {
+		categoryIds[i] = category.Id
+	}
+
+	var credits []model.BookAuthorResponse
+	if err := s.authorRepo.GetBookAuthors(&credits, edit.Id); err != nil {
+		return err
+	}
+
+	authors := make([]model.BookAuthorReq, len(credits))
+	for i, credit := range credits {
+		authors[i] = model.BookAuthorReq{AuthorId: credit.Id, Role: credit.Role}
+	}
+
+	var revision *entity.BookRevision
+	if changes := model.DiffBook(book, categoryIds, authors, edit); len(changes) > 0 {
+		changesJson, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+
+		revision = &entity.BookRevision{
+			Id:        uuid.New(),
+			BookId:    edit.Id,
+			EditorId:  editorId,
+			Changes:   changesJson,
+			RevertsId: revertsId,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	var bookAuthors []entity.BookAuthor
+	if edit.Authors != nil {
+		bookAuthors = toBookAuthors(edit.Id, edit.Authors)
+	}
+
+	if err := s.bookRepo.EditBook(&edit, bookAuthors, revision); err != nil {
+		return err
+	}
+
+	if edit.Image != book.Image {
+		if err := s.uploadRepo.ReferenceUpload(edit.Image); err != nil {
+			return err
+		}
+
+		if err := s.uploadRepo.SupersedeUpload(book.Image); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (s *BookService) UploadBookCover(file *multipart.FileHeader) (string, error) {
-	url, err := s.Supabase.UploadFile(file, "cover")
-	return url, err
+func (s *BookService) GetRevisions(bookId uuid.UUID, field string) (*[]model.BookRevisionResponse, error) {
+	var revisions []model.BookRevisionResponse
+	if err := s.revisionRepo.GetRevisions(&revisions, bookId, field); err != nil {
+		return nil, err
+	}
+
+	return &revisions, nil
+}
+
+// RevertBook puts back the values a revision replaced, recorded as a new revision
+func (s *BookService) RevertBook(bookId uuid.UUID, revisionId uuid.UUID, editorId uuid.UUID) error {
+	var revision entity.BookRevision
+	if err := s.revisionRepo.GetRevision(&revision, revisionId); err != nil {
+		return err
+	}
+
+	if revision.BookId != bookId {
+		return &response.RevisionNotFound
+	}
+
+	var changes map[string]model.FieldChange
+	if err := json.Unmarshal(revision.Changes, &changes); err != nil {
+		return err
+	}
+
+	return s.editBook(model.RevertEdit(bookId, changes), editorId, revisionId)
+}
+
+func (s *BookService) UploadBookCover(file *multipart.FileHeader, userId uuid.UUID) (string, error) {
+	path, url, err := s.Supabase.UploadFile(file, "cover")
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.uploadRepo.CreateUpload(&entity.Upload{
+		Id:        uuid.New(),
+		OwnerId:   userId,
+		Purpose:   "cover",
+		Path:      path,
+		Url:       url,
+		Status:    entity.UploadPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+func toBookAuthors(bookId uuid.UUID, authors []model.BookAuthorReq) []entity.BookAuthor {
+	bookAuthors := make([]entity.BookAuthor, len(authors))
+	for i, author := range authors {
+		role := author.Role
+		if role == "" {
+			role = entity.RoleAuthor
+		}
+
+		bookAuthors[i] = entity.BookAuthor{
+			BookId:   bookId,
+			AuthorId: author.AuthorId,
+			Role:     role,
+			Position: i,
+		}
+	}
+	return bookAuthors
+}
+
+func (s *BookService) UploadBookFile(bookId uuid.UUID, file *multipart.FileHeader) error {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return err
+	}
+
+	path, err := s.Supabase.UploadPrivateFile(file, "books")
+	if err != nil {
+		return err
+	}
+
+	if err := s.bookRepo.SetBookFile(bookId, path); err != nil {
+		return err
+	}
+
+	stale, err := s.downloadRepo.DeleteBookFileCopies(bookId)
+	if err != nil {
+		return err
+	}
+
+	if book.File != "" {
+		stale = append(stale, book.File)
+	}
+
+	if err := s.Supabase.DeletePrivateFiles(stale); err != nil {
+		return err
+	}
+
+	return s.previewService.RebuildSample(bookId)
+}
+
+func (s *BookService) DraftBookFromFile(file *multipart.FileHeader, userId uuid.UUID) (*model.BookDraft, error) {
+	contentType, err := model.GetBookFileType(file)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata *ebook.Metadata
+	switch contentType {
+	case model.BookFilePdf:
+		metadata, err = s.ebook.ExtractPdf(data)
+	case model.BookFileEpub:
+		metadata, err = s.ebook.ExtractEpub(data)
+	default:
+		err = ebook.ErrUnsupported
+	}
+	if err != nil {
+		return nil, &response.BadRequest
+	}
+
+	draft := model.BookDraft{
+		Book: model.CreateBook{
+			Title:       metadata.Title,
+			Description: metadata.Description,
+			Author:      strings.Join(metadata.Creators, ", "),
+			ReleaseDate: metadata.Date,
+		},
+		Isbn:             metadata.Isbn,
+		UnmatchedAuthors: []string{},
+	}
+
+	for _, name := range metadata.Creators {
+		var author entity.Author
+		err := s.authorRepo.GetAuthorByName(&author, name)
+		if err == &response.AuthorNotFound {
+			draft.UnmatchedAuthors = append(draft.UnmatchedAuthors, name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		draft.Book.Authors = append(draft.Book.Authors, model.BookAuthorReq{
+			AuthorId: author.Id,
+			Role:     entity.RoleAuthor,
+		})
+	}
+
+	if metadata.Publisher != "" {
+		var publisher entity.Publisher
+		err := s.publisherRepo.GetPublisherByName(&publisher, metadata.Publisher)
+		if err == &response.PublisherNotFound {
+			draft.UnmatchedPublisher = metadata.Publisher
+		} else if err != nil {
+			return nil, err
+		} else {
+			draft.Book.PublisherId = publisher.Id
+		}
+	}
+
+	if len(metadata.Cover) > 0 {
+		path, url, err := s.Supabase.UploadBytes(metadata.Cover, "cover", metadata.CoverExt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.uploadRepo.CreateUpload(&entity.Upload{
+			Id:        uuid.New(),
+			OwnerId:   userId,
+			Purpose:   "cover",
+			Path:      path,
+			Url:       url,
+			Status:    entity.UploadPending,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}); err != nil {
+			return nil, err
+		}
+
+		draft.Book.Image = url
+	}
+
+	return &draft, nil
+}
+
+// DownloadBook hands out a short-lived signed url to the private file. Books
+// that were trashed after purchase stay downloadable for their buyers.
+func (s *BookService) DownloadBook(bookId uuid.UUID, userId uuid.UUID, ipAddress string, userAgent string) (*model.BookDownload, error) {
+	var book entity.Book
+	if err := s.bookRepo.GetBookWithDeleted(&book, bookId); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.paymentRepo.GetBookPurchase(userId, bookId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if book.Unreleased(now) {
+		return nil, &response.NotReleased
+	}
+
+	if book.File == "" {
+		return nil, &response.BookFileNotFound
+	}
+
+	year, month, day := now.Date()
+	download := entity.Download{
+		Id:        uuid.New(),
+		UserId:    userId,
+		BookId:    bookId,
+		IpAddress: ipAddress,
+		UserAgent: userAgent,
+		CreatedAt: now,
+	}
+	claimed, err := s.downloadRepo.ClaimDownload(&download, time.Date(year, month, day, 0, 0, 0, 0, now.Location()), s.downloadLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claimed {
+		return nil, &response.DownloadLimitReached
+	}
+
+	// the claim goes first so parallel requests can not pass the cap, and is
+	// given back if the file can not be handed out
+	url, err := s.signPurchaserCopy(book, payment, userId, now)
+	if err != nil {
+		if releaseErr := s.downloadRepo.ReleaseDownload(download.Id); releaseErr != nil {
+			return nil, releaseErr
+		}
+		return nil, err
+	}
+
+	return &model.BookDownload{
+		Url:       url,
+		ExpiresAt: now.Add(time.Duration(s.downloadTTL) * time.Second),
+	}, nil
+}
+
+func (s *BookService) signPurchaserCopy(book entity.Book, payment *entity.Payment, userId uuid.UUID, now time.Time) (string, error) {
+	path, err := s.purchaserCopy(book, payment, userId, now)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Supabase.CreateSignedUrl(path, s.downloadTTL)
+}
+
+// purchaserCopy returns the storage path of the buyer's watermarked copy,
+// stamping and caching it on first download or after the file is replaced
+func (s *BookService) purchaserCopy(book entity.Book, payment *entity.Payment, userId uuid.UUID, now time.Time) (string, error) {
+	var cached entity.BookFileCopy
+	err := s.downloadRepo.GetFileCopy(&cached, payment.Id, book.Id)
+	if err == nil && cached.SourceFile == book.File {
+		return cached.Path, nil
+	}
+	if err != nil && err != &response.BookFileNotFound {
+		return "", err
+	}
+
+	var user entity.User
+	if err := s.userRepo.GetUser(&user, userId); err != nil {
+		return "", err
+	}
+
+	original, err := s.Supabase.DownloadPrivateFile(book.File)
+	if err != nil {
+		return "", err
+	}
+
+	stamp := ebook.Stamp{
+		Name:    user.Username,
+		Email:   user.Email,
+		OrderId: payment.Id.String(),
+		Date:    now,
+	}
+
+	var stamped []byte
+	var contentType string
+	switch ext := strings.ToLower(filepath.Ext(book.File)); ext {
+	case ".pdf":
+		stamped, err = s.ebook.WatermarkPdf(original, stamp)
+		contentType = model.BookFilePdf
+	case ".epub":
+		stamped, err = s.ebook.WatermarkEpub(original, stamp)
+		contentType = model.BookFileEpub
+	default:
+		err = ebook.ErrUnsupported
+	}
+	if err != nil {
+		return "", &response.WatermarkFailed
+	}
+
+	path := "watermarked/" + payment.Id.String() + "/" + uuid.NewString() + filepath.Ext(book.File)
+	if err := s.Supabase.UploadPrivateBytes(path, stamped, contentType); err != nil {
+		return "", err
+	}
+
+	if err := s.downloadRepo.SaveFileCopy(&entity.BookFileCopy{
+		PaymentId:  payment.Id,
+		BookId:     book.Id,
+		SourceFile: book.File,
+		Path:       path,
+		CreatedAt:  now,
+	}); err != nil {
+		return "", err
+	}
+
+	if cached.Path != "" {
+		if err := s.Supabase.DeletePrivateFiles([]string{cached.Path}); err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 1 {
+		return fallback
+	}
+	return value
 }
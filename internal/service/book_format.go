@@ -0,0 +1,102 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+var skuPrefixes = map[string]string{
+	entity.FormatEbook:     "EB",
+	entity.FormatPaperback: "PB",
+	entity.FormatHardcover: "HC",
+	entity.FormatAudiobook: "AB",
+}
+
+// formatSku derives the default SKU the same way the formats migration does
+func formatSku(format string, bookId uuid.UUID) string {
+	return skuPrefixes[format] + "-" + strings.ToUpper(strings.ReplaceAll(bookId.String(), "-", "")[:12])
+}
+
+type IBookFormatService interface {
+	GetBookFormats(bookId uuid.UUID) (*[]entity.BookFormat, error)
+	CreateFormat(bookId uuid.UUID, create model.CreateBookFormat) error
+	EditFormat(formatId uuid.UUID, edit model.EditBookFormat) error
+	DeleteFormat(formatId uuid.UUID) error
+}
+
+type BookFormatService struct {
+	formatRepo repository.IBookFormatRepository
+	bookRepo   repository.IBookRepository
+}
+
+func NewBookFormatService(formatRepo repository.IBookFormatRepository, bookRepo repository.IBookRepository) IBookFormatService {
+	return &BookFormatService{
+		formatRepo: formatRepo,
+		bookRepo:   bookRepo,
+	}
+}
+
+func (s *BookFormatService) GetBookFormats(bookId uuid.UUID) (*[]entity.BookFormat, error) {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return nil, err
+	}
+
+	var formats []entity.BookFormat
+	if err := s.formatRepo.GetBookFormats(&formats, bookId); err != nil {
+		return nil, err
+	}
+
+	return &formats, nil
+}
+
+func (s *BookFormatService) CreateFormat(bookId uuid.UUID, create model.CreateBookFormat) error {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return err
+	}
+
+	sku := create.Sku
+	if sku == "" {
+		sku = formatSku(create.Format, bookId)
+	}
+
+	return s.formatRepo.CreateFormat(&entity.BookFormat{
+		Id:        uuid.New(),
+		BookId:    bookId,
+		Format:    create.Format,
+		Sku:       sku,
+		Price:     create.Price,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *BookFormatService) EditFormat(formatId uuid.UUID, edit model.EditBookFormat) error {
+	var format entity.BookFormat
+	if err := s.formatRepo.GetFormat(&format, formatId); err != nil {
+		return err
+	}
+
+	if edit.Sku != "" {
+		format.Sku = edit.Sku
+	}
+	if edit.Price != 0 && edit.Price != format.Price {
+		// the ebook price is the book's price and is only edited through the book
+		if format.Format == entity.FormatEbook {
+			return &response.EbookPriceOnBook
+		}
+		format.Price = edit.Price
+	}
+
+	return s.formatRepo.EditFormat(&format)
+}
+
+func (s *BookFormatService) DeleteFormat(formatId uuid.UUID) error {
+	return s.formatRepo.DeleteFormat(formatId)
+}
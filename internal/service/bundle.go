@@ -0,0 +1,288 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+type IBundleService interface {
+	GetBundles() (*[]model.BundleResponse, error)
+	GetBundle(bundleId uuid.UUID) (*model.BundleResponse, error)
+	CreateBundle(create model.CreateBundle) error
+	EditBundle(edit model.EditBundle) error
+	DeleteBundle(bundleId uuid.UUID) error
+	AddBundleToCart(bundleId uuid.UUID, userId uuid.UUID) error
+}
+
+type BundleService struct {
+	bundleRepo  repository.IBundleRepository
+	bookRepo    repository.IBookRepository
+	formatRepo  repository.IBookFormatRepository
+	cartRepo    repository.ICartRepository
+	paymentRepo repository.IPaymentRepository
+	commentRepo repository.ICommentRepository
+	saleRepo    repository.ISaleRepository
+}
+
+func NewBundleService(bundleRepo repository.IBundleRepository, bookRepo repository.IBookRepository, formatRepo repository.IBookFormatRepository, cartRepo repository.ICartRepository, paymentRepo repository.IPaymentRepository, commentRepo repository.ICommentRepository, saleRepo repository.ISaleRepository) IBundleService {
+	return &BundleService{
+		bundleRepo:  bundleRepo,
+		bookRepo:    bookRepo,
+		formatRepo:  formatRepo,
+		cartRepo:    cartRepo,
+		paymentRepo: paymentRepo,
+		commentRepo: commentRepo,
+		saleRepo:    saleRepo,
+	}
+}
+
+func (s *BundleService) GetBundles() (*[]model.BundleResponse, error) {
+	var bundles []entity.Bundle
+	if err := s.bundleRepo.GetBundles(&bundles); err != nil {
+		return nil, err
+	}
+
+	responses, err := s.bundleResponses(bundles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses, nil
+}
+
+func (s *BundleService) GetBundle(bundleId uuid.UUID) (*model.BundleResponse, error) {
+	var bundle entity.Bundle
+	if err := s.bundleRepo.GetBundle(&bundle, bundleId); err != nil {
+		return nil, err
+	}
+
+	responses, err := s.bundleResponses([]entity.Bundle{bundle})
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses[0], nil
+}
+
+// bundleResponses prices the books of every bundle in one pass
+func (s *BundleService) bundleResponses(bundles []entity.Bundle) ([]model.BundleResponse, error) {
+	bundleIds := make([]uuid.UUID, len(bundles))
+	for i, bundle := range bundles {
+		bundleIds[i] = bundle.Id
+	}
+
+	var rows []model.BundleBookRow
+	if err := s.bundleRepo.GetBundleBooks(&rows, bundleIds); err != nil {
+		return nil, err
+	}
+
+	books := make([]model.BookResponse, len(rows))
+	for i, row := range rows {
+		books[i] = model.BookToBookResponse(row.Book)
+	}
+
+	if err := fillRatings(s.commentRepo, books); err != nil {
+		return nil, err
+	}
+
+	if err := fillPrices(s.saleRepo, books); err != nil {
+		return nil, err
+	}
+
+	byBundle := make(map[uuid.UUID][]int, len(bundles))
+	for i, row := range rows {
+		byBundle[row.BundleId] = append(byBundle[row.BundleId], i)
+	}
+
+	responses := make([]model.BundleResponse, len(bundles))
+	for i, bundle := range bundles {
+		bundleResponse := model.BundleResponse{
+			Bundle:    bundle,
+			Books:     []model.BookResponse{},
+			Available: true,
+		}
+
+		for _, n := range byBundle[bundle.Id] {
+			bundleResponse.Books = append(bundleResponse.Books, books[n])
+			bundleResponse.ListTotal += books[n].EffectivePrice
+			if rows[n].DeletedAt != nil {
+				bundleResponse.Available = false
+			}
+		}
+
+		bundleResponse.Savings = bundleSavings(bundleResponse.ListTotal, bundle.Price)
+		responses[i] = bundleResponse
+	}
+
+	return responses, nil
+}
+
+func (s *BundleService) CreateBundle(create model.CreateBundle) error {
+	books := make([]entity.BundleBook, len(create.BookIds))
+	bundleId := uuid.New()
+	for i, bookId := range create.BookIds {
+		var book entity.Book
+		if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+			return err
+		}
+
+		var format entity.BookFormat
+		if err := s.formatRepo.GetBookFormat(&format, bookId, entity.FormatEbook); err != nil {
+			return err
+		}
+
+		books[i] = entity.BundleBook{
+			BundleId: bundleId,
+			BookId:   bookId,
+			Position: i + 1,
+		}
+	}
+
+	return s.bundleRepo.CreateBundle(&entity.Bundle{
+		Id:          bundleId,
+		Name:        create.Name,
+		Description: create.Description,
+		Price:       create.Price,
+		CreatedAt:   time.Now(),
+	}, books)
+}
+
+func (s *BundleService) EditBundle(edit model.EditBundle) error {
+	var bundle entity.Bundle
+	if err := s.bundleRepo.GetBundle(&bundle, edit.Id); err != nil {
+		return err
+	}
+
+	if edit.Name != "" {
+		bundle.Name = edit.Name
+	}
+	if edit.Description != "" {
+		bundle.Description = edit.Description
+	}
+	if edit.Price != 0 {
+		bundle.Price = edit.Price
+	}
+
+	return s.bundleRepo.EditBundle(&bundle)
+}
+
+func (s *BundleService) DeleteBundle(bundleId uuid.UUID) error {
+	return s.bundleRepo.DeleteBundle(bundleId)
+}
+
+// AddBundleToCart only takes whole bundles; a user who already owns one of
+// the ebooks buys the rest individually
+func (s *BundleService) AddBundleToCart(bundleId uuid.UUID, userId uuid.UUID) error {
+	var bundle entity.Bundle
+	if err := s.bundleRepo.GetBundle(&bundle, bundleId); err != nil {
+		return err
+	}
+
+	var carts []entity.Cart
+	if err := s.cartRepo.GetBundleCarts(&carts, userId, bundleId); err != nil {
+		return err
+	}
+
+	if len(carts) > 0 {
+		return &response.BundleInCart
+	}
+
+	var rows []model.BundleBookRow
+	if err := s.bundleRepo.GetBundleBooks(&rows, []uuid.UUID{bundleId}); err != nil {
+		return err
+	}
+
+	formats := make([]entity.BookFormat, len(rows))
+	for i, row := range rows {
+		if row.DeletedAt != nil {
+			return &response.BundleUnavailable
+		}
+
+		err := s.formatRepo.GetBookFormat(&formats[i], row.Id, entity.FormatEbook)
+		if err == &response.FormatNotFound {
+			return &response.BundleUnavailable
+		}
+		if err != nil {
+			return err
+		}
+
+		owned, err := s.paymentRepo.CheckUserFormatPurchase(userId, formats[i].Id)
+		if err != nil {
+			return err
+		}
+
+		if owned {
+			return &response.AlreadyOwned
+		}
+	}
+
+	return s.cartRepo.AddBundleToCart(userId, bundleId, formats)
+}
+
+// priceCartBundle shows the bundle's cart items at their share of the bundle price
+func priceCartBundle(bundle *entity.Bundle, items []model.CartItem) model.CartBundle {
+	values := make(map[uuid.UUID]float64, len(items))
+	cartBundle := model.CartBundle{
+		BundleId: bundle.Id,
+		Name:     bundle.Name,
+		Price:    bundle.Price,
+		Items:    items,
+	}
+	for _, item := range items {
+		values[item.FormatId] = item.Subtotal
+		cartBundle.ListTotal += item.Subtotal
+	}
+
+	shares := bundleShares(bundle.Price, values)
+	for i := range items {
+		items[i].Subtotal = shares[items[i].FormatId]
+	}
+
+	cartBundle.Savings = bundleSavings(cartBundle.ListTotal, bundle.Price)
+	return cartBundle
+}
+
+// bundleShares splits the bundle price across its ebooks in proportion to
+// what each costs on its own, in whole rupiah, with the last format by id
+// taking the rounding so the shares always add up to the price
+func bundleShares(price float64, values map[uuid.UUID]float64) map[uuid.UUID]float64 {
+	formatIds := make([]uuid.UUID, 0, len(values))
+	var total float64
+	for formatId, value := range values {
+		formatIds = append(formatIds, formatId)
+		total += value
+	}
+	sort.Slice(formatIds, func(i, j int) bool {
+		return formatIds[i].String() < formatIds[j].String()
+	})
+
+	shares := make(map[uuid.UUID]float64, len(values))
+	remaining := price
+	for n, formatId := range formatIds {
+		share := price / float64(len(formatIds))
+		if total > 0 {
+			share = price * values[formatId] / total
+		}
+
+		share = math.Round(share)
+		if n == len(formatIds)-1 {
+			share = remaining
+		}
+
+		shares[formatId] = share
+		remaining -= share
+	}
+
+	return shares
+}
+
+func bundleSavings(listTotal float64, price float64) float64 {
+	return math.Max(listTotal-price, 0)
+}
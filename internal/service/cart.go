@@ -9,7 +9,7 @@ import (
 )
 
 type ICartService interface {
-	GetUserCart(carts *[]entity.Cart, UserId uuid.UUID) error
+	GetUserCart(UserId uuid.UUID) (*model.CartResponse, error)
 	GetCart(cart *entity.Cart, cartId uuid.UUID) error
 	AddToCart(add model.AddToCart, userId uuid.UUID) error
 	RemoveFromCart(cartId uuid.UUID) error
@@ -17,26 +17,91 @@ type ICartService interface {
 }
 
 type CartService struct {
-	cartRepo repository.ICartRepository
-	userRepo repository.IUserRepository
-	bookRepo repository.IBookRepository
+	cartRepo    repository.ICartRepository
+	userRepo    repository.IUserRepository
+	bookRepo    repository.IBookRepository
+	formatRepo  repository.IBookFormatRepository
+	paymentRepo repository.IPaymentRepository
+	saleRepo    repository.ISaleRepository
+	bundleRepo  repository.IBundleRepository
 }
 
-func NewCartService(cartRepo repository.ICartRepository, userRepo repository.IUserRepository, bookRepo repository.IBookRepository) ICartService {
+func NewCartService(cartRepo repository.ICartRepository, userRepo repository.IUserRepository, bookRepo repository.IBookRepository, formatRepo repository.IBookFormatRepository, paymentRepo repository.IPaymentRepository, saleRepo repository.ISaleRepository, bundleRepo repository.IBundleRepository) ICartService {
 	return &CartService{
-		cartRepo: cartRepo,
-		userRepo: userRepo,
-		bookRepo: bookRepo,
+		cartRepo:    cartRepo,
+		userRepo:    userRepo,
+		bookRepo:    bookRepo,
+		formatRepo:  formatRepo,
+		paymentRepo: paymentRepo,
+		saleRepo:    saleRepo,
+		bundleRepo:  bundleRepo,
 	}
 }
 
-func (s *CartService) GetUserCart(carts *[]entity.Cart, UserId uuid.UUID) error {
+func (s *CartService) GetUserCart(UserId uuid.UUID) (*model.CartResponse, error) {
 	var user entity.User
 	if err := s.userRepo.GetUser(&user, UserId); err != nil {
-		return err
+		return nil, err
+	}
+
+	var carts []entity.Cart
+	if err := s.cartRepo.GetUserCart(&carts, &user); err != nil {
+		return nil, err
+	}
+
+	bookIds := make([]uuid.UUID, len(carts))
+	for i, cart := range carts {
+		bookIds[i] = cart.BookId
 	}
 
-	return s.cartRepo.GetUserCart(carts, &user)
+	var prices []model.FormatPrice
+	if err := s.saleRepo.GetFormatPrices(&prices, bookIds); err != nil {
+		return nil, err
+	}
+
+	byFormat := make(map[uuid.UUID]model.FormatPrice, len(prices))
+	for _, price := range prices {
+		byFormat[price.FormatId] = price
+	}
+
+	cart := &model.CartResponse{
+		Items:   []model.CartItem{},
+		Bundles: []model.CartBundle{},
+	}
+	var bundleIds []uuid.UUID
+	bundled := map[uuid.UUID][]model.CartItem{}
+	for i := range carts {
+		price := byFormat[carts[i].FormatId]
+		item := model.CartItem{
+			Cart:     carts[i],
+			Price:    price,
+			Subtotal: float64(carts[i].Amount) * price.EffectivePrice,
+		}
+
+		if bundleId := carts[i].BundleId; bundleId != uuid.Nil {
+			if _, ok := bundled[bundleId]; !ok {
+				bundleIds = append(bundleIds, bundleId)
+			}
+			bundled[bundleId] = append(bundled[bundleId], item)
+			continue
+		}
+
+		cart.Items = append(cart.Items, item)
+		cart.Total += item.Subtotal
+	}
+
+	for _, bundleId := range bundleIds {
+		var bundle entity.Bundle
+		if err := s.bundleRepo.GetBundle(&bundle, bundleId); err != nil {
+			return nil, err
+		}
+
+		cartBundle := priceCartBundle(&bundle, bundled[bundleId])
+		cart.Bundles = append(cart.Bundles, cartBundle)
+		cart.Total += cartBundle.Price
+	}
+
+	return cart, nil
 }
 
 func (s *CartService) GetCart(cart *entity.Cart, cartId uuid.UUID) error {
@@ -54,7 +119,57 @@ func (s *CartService) AddToCart(add model.AddToCart, userId uuid.UUID) error {
 		return err
 	}
 
-	return s.cartRepo.AddToCart(&user, &book, add.Amount)
+	format, err := s.resolveFormat(add.BookId, add.FormatId)
+	if err != nil {
+		return err
+	}
+
+	if format.IsDigital() {
+		if add.Amount != 1 {
+			return &response.DigitalQuantity
+		}
+
+		owned, err := s.paymentRepo.CheckUserFormatPurchase(userId, format.Id)
+		if err != nil {
+			return err
+		}
+
+		if owned {
+			return &response.AlreadyOwned
+		}
+
+		var cart entity.Cart
+		err = s.cartRepo.GetOpenCart(&cart, userId, format.Id)
+		if err == nil {
+			return &response.DigitalQuantity
+		}
+		if err != &response.CartNotFound {
+			return err
+		}
+	}
+
+	return s.cartRepo.AddToCart(&user, format, add.Amount)
+}
+
+// resolveFormat defaults to the ebook when the client does not pick a format
+func (s *CartService) resolveFormat(bookId uuid.UUID, formatId uuid.UUID) (*entity.BookFormat, error) {
+	var format entity.BookFormat
+	if formatId == uuid.Nil {
+		if err := s.formatRepo.GetBookFormat(&format, bookId, entity.FormatEbook); err != nil {
+			return nil, err
+		}
+		return &format, nil
+	}
+
+	if err := s.formatRepo.GetFormat(&format, formatId); err != nil {
+		return nil, err
+	}
+
+	if format.BookId != bookId {
+		return nil, &response.FormatNotFound
+	}
+
+	return &format, nil
 }
 
 func (s *CartService) EditCart(edit model.EditCart, userId uuid.UUID) error {
@@ -71,6 +186,15 @@ func (s *CartService) EditCart(edit model.EditCart, userId uuid.UUID) error {
 		return &response.BadRequest
 	}
 
+	var format entity.BookFormat
+	if err := s.formatRepo.GetFormat(&format, cart.FormatId); err != nil {
+		return err
+	}
+
+	if format.IsDigital() && edit.Amount != 1 {
+		return &response.DigitalQuantity
+	}
+
 	return s.cartRepo.EditCart(&cart, edit.Amount)
 }
 
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+type ICategoryService interface {
+	GetCategoryTree() (*[]model.CategoryResponse, error)
+	GetCategory(categoryId uuid.UUID) (*model.CategoryResponse, error)
+	CreateCategory(create model.CreateCategory) error
+	EditCategory(edit model.EditCategory) error
+	DeleteCategory(categoryId uuid.UUID) error
+}
+
+type CategoryService struct {
+	categoryRepo repository.ICategoryRepository
+}
+
+func NewCategoryService(categoryRepo repository.ICategoryRepository) ICategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *CategoryService) GetCategoryTree() (*[]model.CategoryResponse, error) {
+	var categories []model.CategoryResponse
+	if err := s.categoryRepo.GetCategories(&categories); err != nil {
+		return nil, err
+	}
+
+	tree := model.BuildCategoryTree(categories)
+	return &tree, nil
+}
+
+func (s *CategoryService) GetCategory(categoryId uuid.UUID) (*model.CategoryResponse, error) {
+	tree, err := s.GetCategoryTree()
+	if err != nil {
+		return nil, err
+	}
+
+	if category := findCategory(*tree, categoryId); category != nil {
+		return category, nil
+	}
+
+	return nil, &response.CategoryNotFound
+}
+
+func findCategory(categories []model.CategoryResponse, categoryId uuid.UUID) *model.CategoryResponse {
+	for i := range categories {
+		if categories[i].Id == categoryId {
+			return &categories[i]
+		}
+
+		if found := findCategory(categories[i].Children, categoryId); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func (s *CategoryService) CreateCategory(create model.CreateCategory) error {
+	if create.Name == "" {
+		return &response.BadRequest
+	}
+
+	if create.ParentId != uuid.Nil {
+		var parent entity.Category
+		if err := s.categoryRepo.GetCategory(&parent, create.ParentId); err != nil {
+			return err
+		}
+	}
+
+	return s.categoryRepo.CreateCategory(&entity.Category{
+		Id:       uuid.New(),
+		ParentId: create.ParentId,
+		Name:     create.Name,
+	})
+}
+
+func (s *CategoryService) EditCategory(edit model.EditCategory) error {
+	var category entity.Category
+	if err := s.categoryRepo.GetCategory(&category, edit.Id); err != nil {
+		return err
+	}
+
+	if edit.Name != "" {
+		category.Name = edit.Name
+	}
+
+	if edit.ParentId != nil && *edit.ParentId != category.ParentId {
+		if *edit.ParentId != uuid.Nil {
+			subtree, err := s.categoryRepo.GetSubtreeIds(category.Id)
+			if err != nil {
+				return err
+			}
+
+			if slices.Contains(subtree, *edit.ParentId) {
+				return &response.CategoryCycle
+			}
+
+			var parent entity.Category
+			if err := s.categoryRepo.GetCategory(&parent, *edit.ParentId); err != nil {
+				return err
+			}
+		}
+
+		category.ParentId = *edit.ParentId
+	}
+
+	return s.categoryRepo.EditCategory(&category)
+}
+
+func (s *CategoryService) DeleteCategory(categoryId uuid.UUID) error {
+	hasChildren, err := s.categoryRepo.HasChildren(categoryId)
+	if err != nil {
+		return err
+	}
+
+	if hasChildren {
+		return &response.CategoryHasChildren
+	}
+
+	return s.categoryRepo.DeleteCategory(categoryId)
+}
@@ -2,32 +2,46 @@ package service
 
 import (
 	"errors"
+	"time"
 
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
 	"github.com/google/uuid"
 )
 
 type ICheckoutService interface {
 	GetUserCheckouts(userId uuid.UUID) (*[]entity.Checkout, error)
-	GetCheckoutCarts(checkoutId uuid.UUID) (*[]entity.Cart, error)
-	Checkout(checkoutReq model.CheckoutRequest, userId uuid.UUID, checkoutId uuid.UUID) (float64, error)
+	GetCheckoutCarts(checkoutId uuid.UUID) (*[]model.CheckoutCart, error)
+	Checkout(checkoutReq model.CheckoutRequest, userId uuid.UUID, checkoutId uuid.UUID) (*model.CheckoutSummary, error)
 }
 
 type CheckoutService struct {
-	checkoutRepo repository.ICheckoutRepository
-	cartRepo     repository.ICartRepository
-	bookRepo     repository.IBookRepository
-	userRepo     repository.IUserRepository
+	checkoutRepo     repository.ICheckoutRepository
+	cartRepo         repository.ICartRepository
+	bookRepo         repository.IBookRepository
+	userRepo         repository.IUserRepository
+	formatRepo       repository.IBookFormatRepository
+	paymentRepo      repository.IPaymentRepository
+	saleRepo         repository.ISaleRepository
+	bundleRepo       repository.IBundleRepository
+	shippingFee      float64
+	promotionService IPromotionService
 }
 
-func NewCheckoutService(checkoutRepo repository.ICheckoutRepository, cartRepo repository.ICartRepository, bookRepo repository.IBookRepository, userRepo repository.IUserRepository) ICheckoutService {
+func NewCheckoutService(checkoutRepo repository.ICheckoutRepository, cartRepo repository.ICartRepository, bookRepo repository.IBookRepository, userRepo repository.IUserRepository, formatRepo repository.IBookFormatRepository, paymentRepo repository.IPaymentRepository, saleRepo repository.ISaleRepository, bundleRepo repository.IBundleRepository, promotionService IPromotionService) ICheckoutService {
 	return &CheckoutService{
-		checkoutRepo: checkoutRepo,
-		cartRepo:     cartRepo,
-		bookRepo:     bookRepo,
-		userRepo:     userRepo,
+		checkoutRepo:     checkoutRepo,
+		cartRepo:         cartRepo,
+		bookRepo:         bookRepo,
+		userRepo:         userRepo,
+		formatRepo:       formatRepo,
+		paymentRepo:      paymentRepo,
+		saleRepo:         saleRepo,
+		bundleRepo:       bundleRepo,
+		shippingFee:      float64(envInt("SHIPPING_FEE", 15000)),
+		promotionService: promotionService,
 	}
 }
 
@@ -40,7 +54,7 @@ func (s *CheckoutService) GetUserCheckouts(userId uuid.UUID) (*[]entity.Checkout
 	return s.checkoutRepo.GetUserCheckouts(userId)
 }
 
-func (s *CheckoutService) GetCheckoutCarts(checkoutId uuid.UUID) (*[]entity.Cart, error) {
+func (s *CheckoutService) GetCheckoutCarts(checkoutId uuid.UUID) (*[]model.CheckoutCart, error) {
 	_, err := s.checkoutRepo.GetCheckout(checkoutId)
 	if err != nil {
 		return nil, err
@@ -49,43 +63,188 @@ func (s *CheckoutService) GetCheckoutCarts(checkoutId uuid.UUID) (*[]entity.Cart
 	return s.checkoutRepo.GetCheckoutCarts(checkoutId)
 }
 
-func (s *CheckoutService) Checkout(checkoutReq model.CheckoutRequest, userId uuid.UUID, checkoutId uuid.UUID) (totalPrice float64, err error) {
+func (s *CheckoutService) Checkout(checkoutReq model.CheckoutRequest, userId uuid.UUID, checkoutId uuid.UUID) (*model.CheckoutSummary, error) {
 	var user entity.User
 	if err := s.userRepo.GetUser(&user, userId); err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	for _, cart_id := range checkoutReq.CartsId {
+	var physical bool
+	carts := make([]entity.Cart, len(checkoutReq.CartsId))
+	lines := make([]model.CheckoutLine, len(checkoutReq.CartsId))
+	for i, cart_id := range checkoutReq.CartsId {
 		var cart entity.Cart
 		if err := s.cartRepo.GetCart(&cart, cart_id); err != nil {
-			return 0, err
+			return nil, err
 		}
 
 		if cart.CheckoutId != uuid.Nil {
-			return 0, errors.New("invalid input at " + cart_id.String() + " where it's already being checked out")
+			return nil, errors.New("invalid input at " + cart_id.String() + " where it's already being checked out")
 		}
 
 		var book entity.Book
 		if err := s.bookRepo.GetBook(&book, cart.BookId); err != nil {
-			return 0, err
+			return nil, err
 		}
 
 		if cart.UserId != userId {
-			return 0, errors.New("invalid input at " + cart_id.String() + " where it's not belong to the user")
+			return nil, errors.New("invalid input at " + cart_id.String() + " where it's not belong to the user")
+		}
+
+		var format entity.BookFormat
+		if err := s.formatRepo.GetFormat(&format, cart.FormatId); err != nil {
+			return nil, err
+		}
+
+		if format.IsDigital() {
+			if cart.Amount != 1 {
+				return nil, &response.DigitalQuantity
+			}
+
+			owned, err := s.paymentRepo.CheckUserFormatPurchase(userId, format.Id)
+			if err != nil {
+				return nil, err
+			}
+
+			if owned {
+				return nil, &response.AlreadyOwned
+			}
+		} else {
+			physical = true
 		}
 
-		totalPrice += (float64(cart.Amount) * book.Price)
+		var price model.FormatPrice
+		if err := s.saleRepo.GetFormatPrice(&price, format.Id); err != nil {
+			return nil, err
+		}
+
+		subtotal := float64(cart.Amount) * price.EffectivePrice
+		lines[i] = model.CheckoutLine{
+			CartId:     cart.Id,
+			BookId:     book.Id,
+			FormatId:   format.Id,
+			Title:      book.Title,
+			Format:     format.Format,
+			Amount:     cart.Amount,
+			ListPrice:  price.ListPrice,
+			UnitPrice:  price.EffectivePrice,
+			Subtotal:   subtotal,
+			Total:      subtotal,
+			Promotions: []string{},
+			PreOrder:   book.Unreleased(time.Now()),
+		}
+		cart.SaleId = price.SaleId
+		carts[i] = cart
 	}
 
-	if err := s.checkoutRepo.NewCheckout(checkoutId, userId); err != nil {
-		return 0, err
+	bundled := map[uuid.UUID][]int{}
+	for i, cart := range carts {
+		if cart.BundleId != uuid.Nil {
+			bundled[cart.BundleId] = append(bundled[cart.BundleId], i)
+		}
+	}
+
+	var savedOnBundles float64
+	for bundleId, indices := range bundled {
+		savings, err := s.priceBundle(userId, bundleId, indices, lines, carts)
+		if err != nil {
+			return nil, err
+		}
+		savedOnBundles += savings
+	}
+
+	applied, err := s.promotionService.ApplyPromotions(userId, checkoutReq.Code, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &model.CheckoutSummary{
+		CheckoutId:    checkoutId,
+		Lines:         lines,
+		Promotions:    applied,
+		BundleSavings: savedOnBundles,
+	}
+	for i, line := range lines {
+		carts[i].UnitPrice = line.UnitPrice
+		carts[i].Discount = line.Discount
+		summary.Subtotal += line.Subtotal
+		summary.Discount += line.Discount
+	}
+
+	checkout := &entity.Checkout{
+		Id:       checkoutId,
+		UserID:   userId,
+		Subtotal: summary.Subtotal,
+		Discount: summary.Discount,
+	}
+
+	if physical {
+		if checkoutReq.Shipping == nil {
+			return nil, &response.ShippingRequired
+		}
+
+		checkout.ShippingName = checkoutReq.Shipping.Name
+		checkout.ShippingPhone = checkoutReq.Shipping.Phone
+		checkout.ShippingAddress = checkoutReq.Shipping.Address
+		checkout.ShippingFee = s.shippingFee
+		summary.ShippingFee = s.shippingFee
 	}
 
-	for _, cart_id := range checkoutReq.CartsId {
-		if err := s.checkoutRepo.AddCheckoutId(cart_id, checkoutId); err != nil {
-			return 0, err
+	summary.Total = summary.Subtotal - summary.Discount + summary.ShippingFee
+
+	now := time.Now()
+	redemptions := make([]entity.PromotionRedemption, len(applied))
+	for i, promotion := range applied {
+		redemptions[i] = entity.PromotionRedemption{
+			PromotionId: promotion.Id,
+			CheckoutId:  checkoutId,
+			UserId:      userId,
+			Discount:    promotion.Discount,
+			CreatedAt:   now,
 		}
 	}
 
-	return totalPrice, nil
+	if err := s.checkoutRepo.CreateCheckout(checkout, carts, redemptions); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// priceBundle reprices a bundle's lines at their share of the bundle price.
+// The whole bundle has to be checked out at once, and flash sales do not
+// apply on top of it.
+func (s *CheckoutService) priceBundle(userId uuid.UUID, bundleId uuid.UUID, indices []int, lines []model.CheckoutLine, carts []entity.Cart) (float64, error) {
+	var bundle entity.Bundle
+	if err := s.bundleRepo.GetBundle(&bundle, bundleId); err != nil {
+		return 0, err
+	}
+
+	var open []entity.Cart
+	if err := s.cartRepo.GetBundleCarts(&open, userId, bundleId); err != nil {
+		return 0, err
+	}
+
+	if len(open) != len(indices) {
+		return 0, &response.BundleSplit
+	}
+
+	values := make(map[uuid.UUID]float64, len(indices))
+	var listTotal float64
+	for _, i := range indices {
+		values[lines[i].FormatId] = lines[i].Subtotal
+		listTotal += lines[i].Subtotal
+	}
+
+	shares := bundleShares(bundle.Price, values)
+	for _, i := range indices {
+		share := shares[lines[i].FormatId]
+		lines[i].UnitPrice = share
+		lines[i].Subtotal = share
+		lines[i].Total = share
+		lines[i].BundleId = bundleId
+		carts[i].SaleId = uuid.Nil
+	}
+
+	return bundleSavings(listTotal, bundle.Price), nil
 }
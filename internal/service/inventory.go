@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+type IInventoryService interface {
+	GetInventories(req model.InventoryReq) (*[]model.InventoryResponse, error)
+	GetInventory(formatId uuid.UUID) (*model.InventoryResponse, error)
+	GetAdjustments(formatId uuid.UUID) (*[]entity.StockAdjustment, error)
+	AdjustStock(formatId uuid.UUID, adminId uuid.UUID, adjust model.AdjustStock) error
+	SetLowStockThreshold(formatId uuid.UUID, threshold int) error
+	ReleaseStaleReservations(ttl time.Duration) error
+}
+
+type InventoryService struct {
+	inventoryRepo repository.IInventoryRepository
+	bookRepo      repository.IBookRepository
+	formatRepo    repository.IBookFormatRepository
+}
+
+func NewInventoryService(inventoryRepo repository.IInventoryRepository, bookRepo repository.IBookRepository, formatRepo repository.IBookFormatRepository) IInventoryService {
+	return &InventoryService{
+		inventoryRepo: inventoryRepo,
+		bookRepo:      bookRepo,
+		formatRepo:    formatRepo,
+	}
+}
+
+func (s *InventoryService) GetInventories(req model.InventoryReq) (*[]model.InventoryResponse, error) {
+	var inventories []model.InventoryResponse
+	if err := s.inventoryRepo.GetInventories(&inventories, req); err != nil {
+		return nil, err
+	}
+
+	return &inventories, nil
+}
+
+func (s *InventoryService) GetInventory(formatId uuid.UUID) (*model.InventoryResponse, error) {
+	var inventory model.InventoryResponse
+	if err := s.inventoryRepo.GetInventory(&inventory, formatId); err != nil {
+		return nil, err
+	}
+
+	return &inventory, nil
+}
+
+func (s *InventoryService) GetAdjustments(formatId uuid.UUID) (*[]entity.StockAdjustment, error) {
+	var adjustments []entity.StockAdjustment
+	if err := s.inventoryRepo.GetAdjustments(&adjustments, formatId); err != nil {
+		return nil, err
+	}
+
+	return &adjustments, nil
+}
+
+func (s *InventoryService) AdjustStock(formatId uuid.UUID, adminId uuid.UUID, adjust model.AdjustStock) error {
+	var format entity.BookFormat
+	if err := s.formatRepo.GetFormat(&format, formatId); err != nil {
+		return err
+	}
+
+	if format.IsDigital() {
+		return &response.NotPhysicalFormat
+	}
+
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, format.BookId); err != nil {
+		return err
+	}
+
+	return s.inventoryRepo.AdjustStock(&entity.StockAdjustment{
+		Id:        uuid.New(),
+		BookId:    format.BookId,
+		FormatId:  formatId,
+		Delta:     adjust.Delta,
+		Reason:    adjust.Reason,
+		AdminId:   adminId,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *InventoryService) SetLowStockThreshold(formatId uuid.UUID, threshold int) error {
+	return s.inventoryRepo.SetLowStockThreshold(formatId, threshold)
+}
+
+// ReleaseStaleReservations frees stock held by checkouts whose payment was
+// never created or never completed within ttl
+func (s *InventoryService) ReleaseStaleReservations(ttl time.Duration) error {
+	checkoutIds, err := s.inventoryRepo.GetStaleCheckouts(time.Now().Add(-ttl))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, checkoutId := range checkoutIds {
+		if err := s.inventoryRepo.ReleaseReservations(checkoutId); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/smtp"
+	"github.com/google/uuid"
+)
+
+type INotificationService interface {
+	GetPreferences(userId uuid.UUID) ([]model.NotificationPreference, error)
+	UpdatePreferences(userId uuid.UUID, req model.UpdatePreferences) error
+	CheckUnsubscribe(token string, category string) error
+	Unsubscribe(token string, category string) error
+	Notify(userId uuid.UUID, category string, title string, body string) error
+	NotifyOnce(userId uuid.UUID, deliveryKey string, category string, title string, body string) error
+	GetNotifications(userId uuid.UUID, req model.NotificationReq) (*[]entity.Notification, error)
+	ReadNotification(notificationId uuid.UUID, userId uuid.UUID) error
+}
+
+type NotificationService struct {
+	notificationRepo repository.INotificationRepository
+	userRepo         repository.IUserRepository
+	smtp             *smtp.SMTPClient
+	appUrl           string
+}
+
+func NewNotificationService(notificationRepo repository.INotificationRepository, userRepo repository.IUserRepository, smtp *smtp.SMTPClient) INotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		smtp:             smtp,
+		appUrl:           os.Getenv("APP_URL"),
+	}
+}
+
+func (s *NotificationService) GetPreferences(userId uuid.UUID) ([]model.NotificationPreference, error) {
+	var stored []entity.NotificationPreference
+	if err := s.notificationRepo.GetPreferences(&stored, userId); err != nil {
+		return nil, err
+	}
+
+	return model.MergePreferences(stored), nil
+}
+
+func (s *NotificationService) UpdatePreferences(userId uuid.UUID, req model.UpdatePreferences) error {
+	preferences := make([]entity.NotificationPreference, len(req.Preferences))
+	for i, pref := range req.Preferences {
+		if !model.IsValidPreference(pref.Category, pref.Channel) {
+			return &response.InvalidPreference
+		}
+
+		if model.IsMandatory(pref.Category, pref.Channel) && !pref.Enabled {
+			return &response.MandatoryNotification
+		}
+
+		preferences[i] = entity.NotificationPreference{
+			UserId:   userId,
+			Category: pref.Category,
+			Channel:  pref.Channel,
+			Enabled:  pref.Enabled,
+		}
+	}
+
+	return s.notificationRepo.UpsertPreferences(preferences)
+}
+
+// CheckUnsubscribe validates an unsubscribe link without changing anything
+func (s *NotificationService) CheckUnsubscribe(token string, category string) error {
+	_, err := s.unsubscribeUser(token, category)
+	return err
+}
+
+func (s *NotificationService) Unsubscribe(token string, category string) error {
+	userId, err := s.unsubscribeUser(token, category)
+	if err != nil {
+		return err
+	}
+
+	return s.notificationRepo.UpsertPreferences([]entity.NotificationPreference{{
+		UserId:   userId,
+		Category: category,
+		Channel:  entity.ChannelEmail,
+		Enabled:  false,
+	}})
+}
+
+func (s *NotificationService) unsubscribeUser(token string, category string) (uuid.UUID, error) {
+	if !model.IsValidPreference(category, entity.ChannelEmail) {
+		return uuid.Nil, &response.InvalidPreference
+	}
+
+	if model.IsMandatory(category, entity.ChannelEmail) {
+		return uuid.Nil, &response.MandatoryNotification
+	}
+
+	return s.notificationRepo.GetUserByUnsubscribeToken(token)
+}
+
+// every notification to a user should go through here so their preferences are respected
+func (s *NotificationService) Notify(userId uuid.UUID, category string, title string, body string) error {
+	return s.notify(userId, "", category, title, body)
+}
+
+// NotifyOnce is for jobs that retry a notification until it succeeds. Channels
+// are recorded under deliveryKey as they go out, so a retry after a failed email
+// only resends the email. The record is dropped once every channel is delivered.
+func (s *NotificationService) NotifyOnce(userId uuid.UUID, deliveryKey string, category string, title string, body string) error {
+	if err := s.notify(userId, deliveryKey, category, title, body); err != nil {
+		return err
+	}
+
+	return s.notificationRepo.ClearDeliveries(userId, deliveryKey)
+}
+
+func (s *NotificationService) notify(userId uuid.UUID, deliveryKey string, category string, title string, body string) error {
+	var user entity.User
+	if err := s.userRepo.GetUser(&user, userId); err != nil {
+		return err
+	}
+
+	preferences, err := s.GetPreferences(userId)
+	if err != nil {
+		return err
+	}
+
+	delivered := []string{}
+	if deliveryKey != "" {
+		if err := s.notificationRepo.GetDeliveredChannels(&delivered, userId, deliveryKey); err != nil {
+			return err
+		}
+	}
+
+	if model.IsEnabled(preferences, category, entity.ChannelInApp) && !slices.Contains(delivered, entity.ChannelInApp) {
+		if err := s.notificationRepo.CreateNotification(&entity.Notification{
+			Id:        uuid.New(),
+			UserId:    userId,
+			Category:  category,
+			Title:     title,
+			Body:      body,
+			CreatedAt: time.Now(),
+		}, deliveryKey); err != nil {
+			return err
+		}
+	}
+
+	if !model.IsEnabled(preferences, category, entity.ChannelEmail) || slices.Contains(delivered, entity.ChannelEmail) {
+		return nil
+	}
+
+	if err := s.sendEmail(user, category, title, body); err != nil {
+		return err
+	}
+
+	if deliveryKey == "" {
+		return nil
+	}
+
+	return s.notificationRepo.MarkDelivered(userId, deliveryKey, entity.ChannelEmail)
+}
+
+func (s *NotificationService) sendEmail(user entity.User, category string, title string, body string) error {
+	if model.IsMandatory(category, entity.ChannelEmail) {
+		return s.smtp.SendEmail(user.Email, title, body)
+	}
+
+	unsubscribeUrl, err := s.unsubscribeUrl(user.Id, category)
+	if err != nil {
+		return err
+	}
+
+	return s.smtp.SendUnsubscribableEmail(user.Email, title, body, unsubscribeUrl)
+}
+
+func (s *NotificationService) unsubscribeUrl(userId uuid.UUID, category string) (string, error) {
+	newToken, err := generateRandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := s.notificationRepo.GetUnsubscribeToken(userId, newToken)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("token", token)
+	query.Set("category", category)
+
+	return fmt.Sprintf("%s/api/v1/notifications/unsubscribe?%s", s.appUrl, query.Encode()), nil
+}
+
+func (s *NotificationService) GetNotifications(userId uuid.UUID, req model.NotificationReq) (*[]entity.Notification, error) {
+	notifications := []entity.Notification{}
+	if err := s.notificationRepo.GetNotifications(&notifications, userId, req.Page, req.PageSize); err != nil {
+		return nil, err
+	}
+
+	return &notifications, nil
+}
+
+func (s *NotificationService) ReadNotification(notificationId uuid.UUID, userId uuid.UUID) error {
+	return s.notificationRepo.ReadNotification(notificationId, userId)
+}
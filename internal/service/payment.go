@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"math"
 	"time"
 
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
@@ -14,7 +15,7 @@ import (
 
 type IPaymentService interface {
 	GetPayment(paymentId uuid.UUID) (*entity.Payment, error)
-	CreatePayment(userId uuid.UUID, checkoutId uuid.UUID, totalPrice float64) (*snap.Response, error)
+	CreatePayment(userId uuid.UUID, checkoutId uuid.UUID, summary *model.CheckoutSummary) (*snap.Response, error)
 	UpdatePaymentStatus(PaymentDetails map[string]any) error
 	CheckUserBookPurchase(userId uuid.UUID, bookId uuid.UUID) (*bool, error)
 	GetPayments(req model.PaymentReq) ([]entity.Payment, error)
@@ -23,20 +24,24 @@ type IPaymentService interface {
 }
 
 type PaymentService struct {
-	paymentRepo repository.IPaymentRepository
-	userRepo    repository.IUserRepository
-	bookRepo    repository.IBookRepository
-	midtrans    midtrans.IMidtrans
-	chekoutRepo repository.ICheckoutRepository
+	paymentRepo   repository.IPaymentRepository
+	userRepo      repository.IUserRepository
+	bookRepo      repository.IBookRepository
+	midtrans      midtrans.IMidtrans
+	chekoutRepo   repository.ICheckoutRepository
+	inventoryRepo repository.IInventoryRepository
+	preorderRepo  repository.IPreorderRepository
 }
 
-func NewPaymentService(paymentRepo repository.IPaymentRepository, midtrans midtrans.IMidtrans, userRepo repository.IUserRepository, bookRepo repository.IBookRepository, chekoutRepo repository.ICheckoutRepository) IPaymentService {
+func NewPaymentService(paymentRepo repository.IPaymentRepository, midtrans midtrans.IMidtrans, userRepo repository.IUserRepository, bookRepo repository.IBookRepository, chekoutRepo repository.ICheckoutRepository, inventoryRepo repository.IInventoryRepository, preorderRepo repository.IPreorderRepository) IPaymentService {
 	return &PaymentService{
-		paymentRepo: paymentRepo,
-		midtrans:    midtrans,
-		userRepo:    userRepo,
-		bookRepo:    bookRepo,
-		chekoutRepo: chekoutRepo,
+		paymentRepo:   paymentRepo,
+		midtrans:      midtrans,
+		userRepo:      userRepo,
+		bookRepo:      bookRepo,
+		chekoutRepo:   chekoutRepo,
+		inventoryRepo: inventoryRepo,
+		preorderRepo:  preorderRepo,
 	}
 }
 
@@ -44,7 +49,7 @@ func (s *PaymentService) GetPayment(paymentId uuid.UUID) (*entity.Payment, error
 	return s.paymentRepo.GetPayment(paymentId)
 }
 
-func (s *PaymentService) CreatePayment(userId uuid.UUID, checkoutId uuid.UUID, totalPrice float64) (*snap.Response, error) {
+func (s *PaymentService) CreatePayment(userId uuid.UUID, checkoutId uuid.UUID, summary *model.CheckoutSummary) (*snap.Response, error) {
 	paymentId := uuid.New()
 
 	var user entity.User
@@ -52,8 +57,10 @@ func (s *PaymentService) CreatePayment(userId uuid.UUID, checkoutId uuid.UUID, t
 		return nil, err
 	}
 
+	// the stored total is the rounded amount midtrans charges, not the summary's
+	items := paymentItems(summary)
 	var snapRes *snap.Response
-	snapRes, err := s.midtrans.NewTransactionToken(paymentId.String(), int64(totalPrice), &user)
+	snapRes, err := s.midtrans.NewTransactionToken(paymentId.String(), items, &user)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +79,7 @@ func (s *PaymentService) CreatePayment(userId uuid.UUID, checkoutId uuid.UUID, t
 		Token:      token,
 		UserId:     userId,
 		CheckoutId: checkoutId,
-		TotalPrice: totalPrice,
+		TotalPrice: float64(midtrans.GrossAmount(items)),
 		StatusId:   0,
 		CreatedAt:  time.Now(),
 	}); err != nil {
@@ -82,6 +89,40 @@ func (s *PaymentService) CreatePayment(userId uuid.UUID, checkoutId uuid.UUID, t
 	return snapRes, nil
 }
 
+// paymentItems lists the checkout lines at their unit price, with the
+// combined discount and the shipping fee as separate items
+func paymentItems(summary *model.CheckoutSummary) []midtrans.Item {
+	items := make([]midtrans.Item, 0, len(summary.Lines)+2)
+	for _, line := range summary.Lines {
+		items = append(items, midtrans.Item{
+			Id:    line.FormatId.String(),
+			Name:  line.Title + " (" + line.Format + ")",
+			Price: int64(math.Round(line.UnitPrice)),
+			Qty:   int32(line.Amount),
+		})
+	}
+
+	if summary.Discount > 0 {
+		items = append(items, midtrans.Item{
+			Id:    "DISCOUNT",
+			Name:  "Discount",
+			Price: -int64(math.Round(summary.Discount)),
+			Qty:   1,
+		})
+	}
+
+	if summary.ShippingFee > 0 {
+		items = append(items, midtrans.Item{
+			Id:    "SHIPPING",
+			Name:  "Shipping",
+			Price: int64(math.Round(summary.ShippingFee)),
+			Qty:   1,
+		})
+	}
+
+	return items
+}
+
 func (s *PaymentService) UpdatePaymentStatus(PaymentDetails map[string]any) error {
 	paymentIDs, ok := PaymentDetails["order_id"].(string)
 	if !ok {
@@ -93,7 +134,8 @@ func (s *PaymentService) UpdatePaymentStatus(PaymentDetails map[string]any) erro
 		return err
 	}
 
-	if payment, err := s.paymentRepo.GetPayment(paymentId); err != nil || payment == nil {
+	payment, err := s.paymentRepo.GetPayment(paymentId)
+	if err != nil || payment == nil {
 		return err
 	}
 
@@ -132,12 +174,60 @@ func (s *PaymentService) UpdatePaymentStatus(PaymentDetails map[string]any) erro
 		}
 	}
 
+	if err := s.settleStock(payment.CheckoutId, status, fraud); err != nil {
+		return err
+	}
+
+	if status == "settlement" || status == "capture" && fraud == "accept" {
+		return s.refundCancelledPreorders(paymentId, payment.CheckoutId)
+	}
+
+	return nil
+}
+
+// refundCancelledPreorders refunds pre-orders cancelled while their payment was
+// still pending, since the rest of the checkout may be paid for and can not be
+// cancelled with them. The pre-order id is the refund key, so retried
+// notifications do not refund twice.
+func (s *PaymentService) refundCancelledPreorders(paymentId uuid.UUID, checkoutId uuid.UUID) error {
+	var preorders []model.PreorderResponse
+	if err := s.preorderRepo.GetUnrefunded(&preorders, checkoutId); err != nil {
+		return err
+	}
+
+	for _, preorder := range preorders {
+		if preorder.LineTotal <= 0 {
+			continue
+		}
+
+		if err := s.midtrans.Refund(paymentId.String(), preorder.Id.String(), int64(math.Round(preorder.LineTotal)), preorder.CancelReason); err != nil {
+			return err
+		}
+
+		if err := s.preorderRepo.SetRefund(preorder.Id, preorder.LineTotal); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settleStock deducts reserved copies once money is in and frees them when the
+// payment fails; a challenged capture keeps its reservation until resolved
+func (s *PaymentService) settleStock(checkoutId uuid.UUID, status any, fraud any) error {
+	switch {
+	case status == "settlement", status == "capture" && fraud == "accept":
+		return s.inventoryRepo.CommitReservations(checkoutId)
+	case status == "deny", status == "cancel", status == "expire":
+		return s.inventoryRepo.ReleaseReservations(checkoutId)
+	}
+
 	return nil
 }
 
 func (s *PaymentService) CheckUserBookPurchase(userId uuid.UUID, bookId uuid.UUID) (*bool, error) {
 	var book entity.Book
-	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+	if err := s.bookRepo.GetBookWithDeleted(&book, bookId); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/midtrans"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+type IPreorderService interface {
+	GetUserPreorders(userId uuid.UUID) (*[]model.PreorderResponse, error)
+	GetBookPreorders(bookId uuid.UUID) (*[]model.PreorderResponse, error)
+	CancelPreorder(preorderId uuid.UUID, adminId uuid.UUID, cancel model.CancelPreorder) error
+	ProcessPreorders() error
+}
+
+type PreorderService struct {
+	preorderRepo        repository.IPreorderRepository
+	bookRepo            repository.IBookRepository
+	midtrans            midtrans.IMidtrans
+	notificationService INotificationService
+}
+
+func NewPreorderService(preorderRepo repository.IPreorderRepository, bookRepo repository.IBookRepository, midtrans midtrans.IMidtrans, notificationService INotificationService) IPreorderService {
+	return &PreorderService{
+		preorderRepo:        preorderRepo,
+		bookRepo:            bookRepo,
+		midtrans:            midtrans,
+		notificationService: notificationService,
+	}
+}
+
+func (s *PreorderService) GetUserPreorders(userId uuid.UUID) (*[]model.PreorderResponse, error) {
+	var preorders []model.PreorderResponse
+	if err := s.preorderRepo.GetUserPreorders(&preorders, userId); err != nil {
+		return nil, err
+	}
+
+	return &preorders, nil
+}
+
+func (s *PreorderService) GetBookPreorders(bookId uuid.UUID) (*[]model.PreorderResponse, error) {
+	var book entity.Book
+	if err := s.bookRepo.GetBookWithDeleted(&book, bookId); err != nil {
+		return nil, err
+	}
+
+	var preorders []model.PreorderResponse
+	if err := s.preorderRepo.GetBookPreorders(&preorders, bookId); err != nil {
+		return nil, err
+	}
+
+	return &preorders, nil
+}
+
+// CancelPreorder refunds the pre-ordered line before closing it, so a failed
+// refund leaves the pre-order open to retry. An unpaid one is refunded when its
+// payment settles.
+func (s *PreorderService) CancelPreorder(preorderId uuid.UUID, adminId uuid.UUID, cancel model.CancelPreorder) error {
+	var preorder model.PreorderResponse
+	if err := s.preorderRepo.GetPreorder(&preorder, preorderId); err != nil {
+		return err
+	}
+
+	if preorder.Status != entity.PreorderOpen {
+		return &response.PreorderClosed
+	}
+
+	var refund float64
+	if preorder.Paid {
+		refund = preorder.LineTotal
+		if err := s.midtrans.Refund(preorder.PaymentId.String(), preorder.Id.String(), int64(math.Round(refund)), cancel.Reason); err != nil {
+			return err
+		}
+	}
+
+	if err := s.preorderRepo.CancelPreorder(preorderId, refund, cancel.Reason, adminId); err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("Pre-order cancelled: %s", preorder.Title)
+	body := fmt.Sprintf("Your pre-order of %s was cancelled: %s.", preorder.Title, cancel.Reason)
+	if refund > 0 {
+		body += fmt.Sprintf(" Rp%.0f has been refunded to your original payment method.", refund)
+	}
+
+	return s.notificationService.Notify(preorder.UserId, entity.NotificationOrderUpdates, title, body)
+}
+
+// ProcessPreorders tells customers about moved release dates and, once a book
+// is out, that their copy is ready. Downloads unlock on the release date itself.
+func (s *PreorderService) ProcessPreorders() error {
+	var errs []error
+
+	var rescheduled []model.PreorderNotice
+	if err := s.preorderRepo.GetRescheduled(&rescheduled); err != nil {
+		return err
+	}
+
+	for _, notice := range rescheduled {
+		title := fmt.Sprintf("New release date: %s", notice.Title)
+		body := fmt.Sprintf("%s, which you pre-ordered, will now be released on %s.", notice.Title, notice.ReleaseDate)
+		if err := s.notificationService.NotifyOnce(notice.UserId, fmt.Sprintf("preorder-date:%s:%s", notice.PreorderId, notice.ReleaseDate), entity.NotificationOrderUpdates, title, body); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := s.preorderRepo.SetReleaseDate(notice.PreorderId, notice.ReleaseDate); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var released []model.PreorderNotice
+	if err := s.preorderRepo.GetReleased(&released); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	for _, notice := range released {
+		title := fmt.Sprintf("Your pre-order is here: %s", notice.Title)
+		body := fmt.Sprintf("%s has been released and is now ready for you.", notice.Title)
+		if err := s.notificationService.NotifyOnce(notice.UserId, "preorder-released:"+notice.PreorderId.String(), entity.NotificationOrderUpdates, title, body); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := s.preorderRepo.MarkFulfilled(notice.PreorderId); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
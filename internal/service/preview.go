@@ -0,0 +1,216 @@
+package service
+
+import (
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/ebook"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/supabase"
+	"github.com/google/uuid"
+)
+
+type IPreviewService interface {
+	SetSample(bookId uuid.UUID, set model.SetBookSample) error
+	UploadSampleFile(bookId uuid.UUID, file *multipart.FileHeader) error
+	DeleteSample(bookId uuid.UUID) error
+	RebuildSample(bookId uuid.UUID) error
+	GetPreview(bookId uuid.UUID, userId uuid.UUID) (*model.BookDownload, error)
+	GetConversions(days int) (*[]model.SampleConversion, error)
+}
+
+type PreviewService struct {
+	bookRepo    repository.IBookRepository
+	sampleRepo  repository.ISampleRepository
+	Supabase    supabase.ISupabase
+	ebook       ebook.IEbook
+	downloadTTL int
+}
+
+func NewPreviewService(bookRepo repository.IBookRepository, sampleRepo repository.ISampleRepository, Supabase supabase.ISupabase, ebook ebook.IEbook) IPreviewService {
+	return &PreviewService{
+		bookRepo:    bookRepo,
+		sampleRepo:  sampleRepo,
+		Supabase:    Supabase,
+		ebook:       ebook,
+		downloadTTL: envInt("DOWNLOAD_URL_TTL", 300),
+	}
+}
+
+func (s *PreviewService) SetSample(bookId uuid.UUID, set model.SetBookSample) error {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return err
+	}
+
+	return s.buildSample(book, set.Mode, set.Count)
+}
+
+// buildSample cuts the sample out of the book's current file and stores it
+func (s *PreviewService) buildSample(book entity.Book, mode string, count int) error {
+	if book.File == "" {
+		return &response.BookFileNotFound
+	}
+
+	original, err := s.Supabase.DownloadPrivateFile(book.File)
+	if err != nil {
+		return err
+	}
+
+	var sample []byte
+	var contentType string
+	switch ext := strings.ToLower(filepath.Ext(book.File)); {
+	case ext == ".pdf" && mode == entity.SamplePages:
+		sample, err = s.ebook.SamplePdf(original, count)
+		contentType = model.BookFilePdf
+	case ext == ".epub" && mode == entity.SampleChapters:
+		sample, err = s.ebook.SampleEpub(original, count)
+		contentType = model.BookFileEpub
+	default:
+		return &response.BadRequest
+	}
+	if err != nil {
+		return &response.BadRequest
+	}
+
+	path := "samples/" + book.Id.String() + "/" + uuid.NewString() + filepath.Ext(book.File)
+	if err := s.Supabase.UploadPrivateBytes(path, sample, contentType); err != nil {
+		return err
+	}
+
+	return s.replaceSample(&entity.BookSample{
+		BookId:     book.Id,
+		Mode:       mode,
+		Count:      count,
+		Path:       path,
+		SourceFile: book.File,
+		UpdatedAt:  time.Now(),
+	})
+}
+
+func (s *PreviewService) UploadSampleFile(bookId uuid.UUID, file *multipart.FileHeader) error {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return err
+	}
+
+	path, err := s.Supabase.UploadPrivateFile(file, "samples/"+bookId.String())
+	if err != nil {
+		return err
+	}
+
+	return s.replaceSample(&entity.BookSample{
+		BookId:    bookId,
+		Mode:      entity.SampleFile,
+		Path:      path,
+		UpdatedAt: time.Now(),
+	})
+}
+
+func (s *PreviewService) replaceSample(sample *entity.BookSample) error {
+	var previous entity.BookSample
+	err := s.sampleRepo.GetSample(&previous, sample.BookId)
+	if err != nil && err != &response.SampleNotFound {
+		return err
+	}
+
+	if err := s.sampleRepo.SaveSample(sample); err != nil {
+		return err
+	}
+
+	if previous.Path != "" && previous.Path != sample.Path {
+		return s.Supabase.DeletePrivateFiles([]string{previous.Path})
+	}
+
+	return nil
+}
+
+func (s *PreviewService) DeleteSample(bookId uuid.UUID) error {
+	path, err := s.sampleRepo.DeleteSample(bookId)
+	if err != nil {
+		return err
+	}
+
+	return s.Supabase.DeletePrivateFiles([]string{path})
+}
+
+// RebuildSample recuts a sample taken from the book file after the file is
+// replaced. A sample that can not be cut from the new file is dropped rather
+// than left showing the old one.
+func (s *PreviewService) RebuildSample(bookId uuid.UUID) error {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return err
+	}
+
+	var sample entity.BookSample
+	err := s.sampleRepo.GetSample(&sample, bookId)
+	if err == &response.SampleNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if sample.Mode == entity.SampleFile || sample.SourceFile == book.File {
+		return nil
+	}
+
+	err = s.buildSample(book, sample.Mode, sample.Count)
+	if err == &response.BadRequest {
+		return s.DeleteSample(bookId)
+	}
+	return err
+}
+
+// GetPreview signs the stored sample for any signed-in user. Samples are only
+// built by admin actions, so previews never write or delete objects.
+func (s *PreviewService) GetPreview(bookId uuid.UUID, userId uuid.UUID) (*model.BookDownload, error) {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return nil, err
+	}
+
+	var sample entity.BookSample
+	if err := s.sampleRepo.GetSample(&sample, bookId); err != nil {
+		return nil, err
+	}
+
+	url, err := s.Supabase.CreateSignedUrl(sample.Path, s.downloadTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.sampleRepo.CreatePreview(&entity.Preview{
+		Id:        uuid.New(),
+		UserId:    userId,
+		BookId:    bookId,
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &model.BookDownload{
+		Url:       url,
+		ExpiresAt: now.Add(time.Duration(s.downloadTTL) * time.Second),
+	}, nil
+}
+
+func (s *PreviewService) GetConversions(days int) (*[]model.SampleConversion, error) {
+	if days < 1 {
+		days = 30
+	}
+
+	var conversions []model.SampleConversion
+	if err := s.sampleRepo.GetConversions(&conversions, time.Now().AddDate(0, 0, -days)); err != nil {
+		return nil, err
+	}
+
+	return &conversions, nil
+}
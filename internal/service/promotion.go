@@ -0,0 +1,368 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+type IPromotionService interface {
+	GetPromotions(req model.PromotionReq) (*[]model.PromotionResponse, error)
+	GetPromotion(promotionId uuid.UUID) (*model.PromotionResponse, error)
+	CreatePromotion(create model.CreatePromotion) error
+	EndPromotion(promotionId uuid.UUID) error
+	ApplyPromotions(userId uuid.UUID, code string, lines []model.CheckoutLine) ([]model.AppliedPromotion, error)
+}
+
+type PromotionService struct {
+	promotionRepo repository.IPromotionRepository
+	bookRepo      repository.IBookRepository
+	categoryRepo  repository.ICategoryRepository
+}
+
+func NewPromotionService(promotionRepo repository.IPromotionRepository, bookRepo repository.IBookRepository, categoryRepo repository.ICategoryRepository) IPromotionService {
+	return &PromotionService{
+		promotionRepo: promotionRepo,
+		bookRepo:      bookRepo,
+		categoryRepo:  categoryRepo,
+	}
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (s *PromotionService) GetPromotions(req model.PromotionReq) (*[]model.PromotionResponse, error) {
+	var promotions []model.PromotionResponse
+	if err := s.promotionRepo.GetPromotions(&promotions, req); err != nil {
+		return nil, err
+	}
+
+	return &promotions, nil
+}
+
+func (s *PromotionService) GetPromotion(promotionId uuid.UUID) (*model.PromotionResponse, error) {
+	var promotion model.PromotionResponse
+	if err := s.promotionRepo.GetPromotion(&promotion, promotionId); err != nil {
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+func (s *PromotionService) CreatePromotion(create model.CreatePromotion) error {
+	if create.Kind == entity.PromotionPercentage && create.Value > 100 {
+		return &response.BadRequest
+	}
+
+	switch create.Scope {
+	case entity.PromotionScopeAll:
+		if create.BookId != uuid.Nil || create.CategoryId != uuid.Nil {
+			return &response.BadRequest
+		}
+	case entity.PromotionScopeBook:
+		if create.BookId == uuid.Nil || create.CategoryId != uuid.Nil {
+			return &response.BadRequest
+		}
+
+		var book entity.Book
+		if err := s.bookRepo.GetBook(&book, create.BookId); err != nil {
+			return err
+		}
+	case entity.PromotionScopeCategory:
+		if create.CategoryId == uuid.Nil || create.BookId != uuid.Nil {
+			return &response.BadRequest
+		}
+
+		var category entity.Category
+		if err := s.categoryRepo.GetCategory(&category, create.CategoryId); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	startsAt := now
+	if create.StartsAt != nil {
+		startsAt = *create.StartsAt
+	}
+
+	if create.EndsAt != nil && !create.EndsAt.After(startsAt) {
+		return &response.BadRequest
+	}
+
+	var code *string
+	if normalized := normalizeCode(create.Code); normalized != "" {
+		code = &normalized
+	}
+
+	return s.promotionRepo.CreatePromotion(&entity.Promotion{
+		Id:             uuid.New(),
+		Code:           code,
+		Name:           create.Name,
+		Kind:           create.Kind,
+		Value:          create.Value,
+		Scope:          create.Scope,
+		BookId:         create.BookId,
+		CategoryId:     create.CategoryId,
+		MinSpend:       create.MinSpend,
+		MinQuantity:    create.MinQuantity,
+		MaxUses:        create.MaxUses,
+		MaxUsesPerUser: create.MaxUsesPerUser,
+		StartsAt:       startsAt,
+		EndsAt:         create.EndsAt,
+		CreatedAt:      now,
+	})
+}
+
+func (s *PromotionService) EndPromotion(promotionId uuid.UUID) error {
+	return s.promotionRepo.EndPromotion(promotionId)
+}
+
+// ApplyPromotions discounts the lines in place. Automatic promotions do not
+// stack, only the one saving the most applies; a code is applied on top of
+// it against the already discounted lines.
+func (s *PromotionService) ApplyPromotions(userId uuid.UUID, code string, lines []model.CheckoutLine) ([]model.AppliedPromotion, error) {
+	now := time.Now()
+	scope := &promotionScope{categoryRepo: s.categoryRepo, books: map[uuid.UUID][]uuid.UUID{}}
+	applied := []model.AppliedPromotion{}
+
+	var automatic []entity.Promotion
+	if err := s.promotionRepo.GetAutomaticPromotions(&automatic, now); err != nil {
+		return nil, err
+	}
+
+	var best *entity.Promotion
+	var bestDiscounts []float64
+	var bestTotal float64
+	for i := range automatic {
+		promotion := &automatic[i]
+
+		available, err := s.withinLimits(promotion, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		if !available {
+			continue
+		}
+
+		discounts, total, err := scope.discounts(promotion, lines)
+		if err != nil {
+			return nil, err
+		}
+
+		if total > bestTotal {
+			best, bestDiscounts, bestTotal = promotion, discounts, total
+		}
+	}
+
+	if best != nil {
+		applied = append(applied, applyDiscounts(best, lines, bestDiscounts, bestTotal))
+	}
+
+	if code = normalizeCode(code); code == "" {
+		return applied, nil
+	}
+
+	var promotion entity.Promotion
+	if err := s.promotionRepo.GetPromotionByCode(&promotion, code, now); err != nil {
+		return nil, err
+	}
+
+	available, err := s.withinLimits(&promotion, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !available {
+		return nil, &response.PromotionExhausted
+	}
+
+	discounts, total, err := scope.discounts(&promotion, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	if total == 0 {
+		return nil, &response.PromotionNotApplicable
+	}
+
+	return append(applied, applyDiscounts(&promotion, lines, discounts, total)), nil
+}
+
+// withinLimits is a best-effort check; the checkout transaction enforces it
+func (s *PromotionService) withinLimits(promotion *entity.Promotion, userId uuid.UUID) (bool, error) {
+	if promotion.MaxUses == nil && promotion.MaxUsesPerUser == nil {
+		return true, nil
+	}
+
+	total, user, err := s.promotionRepo.CountRedemptions(promotion.Id, userId)
+	if err != nil {
+		return false, err
+	}
+
+	if promotion.MaxUses != nil && total >= *promotion.MaxUses {
+		return false, nil
+	}
+
+	return promotion.MaxUsesPerUser == nil || user < *promotion.MaxUsesPerUser, nil
+}
+
+func applyDiscounts(promotion *entity.Promotion, lines []model.CheckoutLine, discounts []float64, total float64) model.AppliedPromotion {
+	for i, discount := range discounts {
+		if discount == 0 {
+			continue
+		}
+
+		lines[i].Discount += discount
+		lines[i].Total -= discount
+		lines[i].Promotions = append(lines[i].Promotions, promotion.Name)
+	}
+
+	return model.AppliedPromotion{
+		Id:       promotion.Id,
+		Code:     promotion.Code,
+		Name:     promotion.Name,
+		Discount: total,
+	}
+}
+
+// promotionScope caches category lookups for one checkout
+type promotionScope struct {
+	categoryRepo repository.ICategoryRepository
+	books        map[uuid.UUID][]uuid.UUID
+}
+
+func (p *promotionScope) matches(promotion *entity.Promotion, bookId uuid.UUID, subtree []uuid.UUID) (bool, error) {
+	switch promotion.Scope {
+	case entity.PromotionScopeBook:
+		return promotion.BookId == bookId, nil
+	case entity.PromotionScopeCategory:
+		categoryIds, ok := p.books[bookId]
+		if !ok {
+			var categories []entity.Category
+			if err := p.categoryRepo.GetBookCategories(&categories, bookId); err != nil {
+				return false, err
+			}
+
+			for _, category := range categories {
+				categoryIds = append(categoryIds, category.Id)
+			}
+			p.books[bookId] = categoryIds
+		}
+
+		return slices.ContainsFunc(categoryIds, func(id uuid.UUID) bool {
+			return slices.Contains(subtree, id)
+		}), nil
+	}
+
+	return true, nil
+}
+
+// discounts works out what the promotion takes off each line, rounded to
+// whole rupiah. Fixed amounts are split across eligible lines by value.
+func (p *promotionScope) discounts(promotion *entity.Promotion, lines []model.CheckoutLine) ([]float64, float64, error) {
+	var subtree []uuid.UUID
+	if promotion.Scope == entity.PromotionScopeCategory {
+		ids, err := p.categoryRepo.GetSubtreeIds(promotion.CategoryId)
+		if err != nil {
+			return nil, 0, err
+		}
+		subtree = ids
+	}
+
+	var eligible []int
+	var subtotal float64
+	var quantity int
+	for i, line := range lines {
+		// bundles are already discounted
+		if line.Total <= 0 || line.BundleId != uuid.Nil {
+			continue
+		}
+
+		ok, err := p.matches(promotion, line.BookId, subtree)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if ok {
+			eligible = append(eligible, i)
+			subtotal += line.Total
+			quantity += line.Amount
+		}
+	}
+
+	if len(eligible) == 0 || subtotal < promotion.MinSpend || quantity < promotion.MinQuantity {
+		return nil, 0, nil
+	}
+
+	discounts := make([]float64, len(lines))
+	var total float64
+	switch promotion.Kind {
+	case entity.PromotionPercentage:
+		for _, i := range eligible {
+			discounts[i] = math.Round(lines[i].Total * promotion.Value / 100)
+			total += discounts[i]
+		}
+	case entity.PromotionFixed:
+		totals := make([]float64, len(eligible))
+		for n, i := range eligible {
+			totals[n] = lines[i].Total
+		}
+
+		for n, share := range splitFixed(math.Min(promotion.Value, subtotal), totals) {
+			discounts[eligible[n]] = share
+			total += share
+		}
+	}
+
+	return discounts, total, nil
+}
+
+// splitFixed spreads a fixed discount over line totals by value in whole
+// rupiah. Shares are floored so they never add up to more than the amount, and
+// the rupiah left over go to the first lines with room for them.
+func splitFixed(amount float64, totals []float64) []float64 {
+	var subtotal float64
+	for _, total := range totals {
+		subtotal += total
+	}
+
+	shares := make([]float64, len(totals))
+	if subtotal <= 0 {
+		return shares
+	}
+
+	amount = math.Floor(math.Min(amount, subtotal))
+	remaining := amount
+	for i, total := range totals {
+		shares[i] = math.Floor(amount * total / subtotal)
+		remaining -= shares[i]
+	}
+
+	// a rupiah each in line order; flooring leaves less than one per line, but a
+	// line with no room left passes its rupiah on to the next round
+	for remaining > 0 {
+		given := false
+		for i, total := range totals {
+			if remaining > 0 && total-shares[i] >= 1 {
+				shares[i]++
+				remaining--
+				given = true
+			}
+		}
+
+		if !given {
+			break
+		}
+	}
+
+	return shares
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitFixed(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount float64
+		totals []float64
+		want   []float64
+	}{
+		{"many equal lines", 3, []float64{100, 100, 100, 100, 100, 100}, []float64{1, 1, 1, 0, 0, 0}},
+		{"split by value", 100, []float64{300, 100}, []float64{75, 25}},
+		{"uneven split", 10, []float64{100, 100, 100}, []float64{4, 3, 3}},
+		{"amount above subtotal", 500, []float64{100, 50}, []float64{100, 50}},
+		{"small last line", 99, []float64{1000, 1}, []float64{99, 0}},
+		{"fractional amount", 10.7, []float64{50, 50}, []float64{5, 5}},
+		{"no value", 10, []float64{0, 0}, []float64{0, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := splitFixed(test.amount, test.totals)
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("splitFixed(%v, %v) = %v, want %v", test.amount, test.totals, got, test.want)
+			}
+
+			var sum float64
+			for i, share := range got {
+				if share < 0 || share > test.totals[i] {
+					t.Errorf("share %d = %v is outside 0..%v", i, share, test.totals[i])
+				}
+				sum += share
+			}
+			if sum > test.amount {
+				t.Errorf("shares add up to %v, more than %v", sum, test.amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"mime/multipart"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/supabase"
+	"github.com/google/uuid"
+)
+
+type IPublisherService interface {
+	GetPublishers() (*[]entity.Publisher, error)
+	GetPublisher(publisherId uuid.UUID) (*model.PublisherResponse, error)
+	CreatePublisher(create model.CreatePublisher) error
+	EditPublisher(edit model.EditPublisher) error
+	DeletePublisher(publisherId uuid.UUID) error
+	UploadPublisherLogo(file *multipart.FileHeader, userId uuid.UUID) (string, error)
+}
+
+type PublisherService struct {
+	publisherRepo repository.IPublisherRepository
+	uploadRepo    repository.IUploadRepository
+	Supabase      supabase.ISupabase
+}
+
+func NewPublisherService(publisherRepo repository.IPublisherRepository, uploadRepo repository.IUploadRepository, Supabase supabase.ISupabase) IPublisherService {
+	return &PublisherService{
+		publisherRepo: publisherRepo,
+		uploadRepo:    uploadRepo,
+		Supabase:      Supabase,
+	}
+}
+
+func (s *PublisherService) GetPublishers() (*[]entity.Publisher, error) {
+	var publishers []entity.Publisher
+	if err := s.publisherRepo.GetPublishers(&publishers); err != nil {
+		return nil, err
+	}
+
+	return &publishers, nil
+}
+
+func (s *PublisherService) GetPublisher(publisherId uuid.UUID) (*model.PublisherResponse, error) {
+	var publisher entity.Publisher
+	if err := s.publisherRepo.GetPublisher(&publisher, publisherId); err != nil {
+		return nil, err
+	}
+
+	var books []entity.Book
+	if err := s.publisherRepo.GetPublisherBooks(&books, publisherId); err != nil {
+		return nil, err
+	}
+
+	booksResponse := make([]model.BookResponse, len(books))
+	for i, book := range books {
+		booksResponse[i] = model.BookToBookResponse(book)
+	}
+
+	return &model.PublisherResponse{
+		Publisher: publisher,
+		Books:     booksResponse,
+	}, nil
+}
+
+func (s *PublisherService) CreatePublisher(create model.CreatePublisher) error {
+	if err := s.publisherRepo.CreatePublisher(&entity.Publisher{
+		Id:          uuid.New(),
+		Name:        create.Name,
+		Description: create.Description,
+		Logo:        create.Logo,
+	}); err != nil {
+		return err
+	}
+
+	return s.uploadRepo.ReferenceUpload(create.Logo)
+}
+
+func (s *PublisherService) EditPublisher(edit model.EditPublisher) error {
+	var publisher entity.Publisher
+	if err := s.publisherRepo.GetPublisher(&publisher, edit.Id); err != nil {
+		return err
+	}
+
+	oldLogo := publisher.Logo
+	if edit.Name != "" {
+		publisher.Name = edit.Name
+	}
+	if edit.Description != "" {
+		publisher.Description = edit.Description
+	}
+	if edit.Logo != "" {
+		publisher.Logo = edit.Logo
+	}
+
+	if err := s.publisherRepo.EditPublisher(&publisher); err != nil {
+		return err
+	}
+
+	if publisher.Logo != oldLogo {
+		if err := s.uploadRepo.ReferenceUpload(publisher.Logo); err != nil {
+			return err
+		}
+
+		if err := s.uploadRepo.SupersedeUpload(oldLogo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *PublisherService) DeletePublisher(publisherId uuid.UUID) error {
+	var publisher entity.Publisher
+	if err := s.publisherRepo.GetPublisher(&publisher, publisherId); err != nil {
+		return err
+	}
+
+	if err := s.publisherRepo.DeletePublisher(publisherId); err != nil {
+		return err
+	}
+
+	return s.uploadRepo.SupersedeUpload(publisher.Logo)
+}
+
+func (s *PublisherService) UploadPublisherLogo(file *multipart.FileHeader, userId uuid.UUID) (string, error) {
+	path, url, err := s.Supabase.UploadFile(file, "publisher")
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.uploadRepo.CreateUpload(&entity.Upload{
+		Id:        uuid.New(),
+		OwnerId:   userId,
+		Purpose:   "publisher",
+		Path:      path,
+		Url:       url,
+		Status:    entity.UploadPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
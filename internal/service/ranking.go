@@ -0,0 +1,112 @@
+package service
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+)
+
+type IRankingService interface {
+	RefreshRankings() error
+	GetBestsellers(window string, limit int) (*[]model.RankedBookResponse, error)
+	GetTrending(limit int) (*[]model.RankedBookResponse, error)
+}
+
+type RankingService struct {
+	rankingRepo repository.IRankingRepository
+	commentRepo repository.ICommentRepository
+	saleRepo    repository.ISaleRepository
+	size        int
+	halfLife    time.Duration
+}
+
+func NewRankingService(rankingRepo repository.IRankingRepository, commentRepo repository.ICommentRepository, saleRepo repository.ISaleRepository) IRankingService {
+	return &RankingService{
+		rankingRepo: rankingRepo,
+		commentRepo: commentRepo,
+		saleRepo:    saleRepo,
+		size:        envInt("RANKING_SIZE", 100),
+		halfLife:    time.Duration(envInt("TRENDING_HALF_LIFE", 48)) * time.Hour,
+	}
+}
+
+var bestsellerLists = map[string]string{
+	model.Window7Days:   entity.RankingBestseller7d,
+	model.Window30Days:  entity.RankingBestseller30d,
+	model.WindowAllTime: entity.RankingBestsellerAll,
+}
+
+func (s *RankingService) RefreshRankings() error {
+	now := time.Now()
+	weekAgo := now.AddDate(0, 0, -7)
+	monthAgo := now.AddDate(0, 0, -30)
+
+	if err := s.rankingRepo.RefreshBestsellers(entity.RankingBestseller7d, &weekAgo, s.size); err != nil {
+		return err
+	}
+
+	if err := s.rankingRepo.RefreshBestsellers(entity.RankingBestseller30d, &monthAgo, s.size); err != nil {
+		return err
+	}
+
+	if err := s.rankingRepo.RefreshBestsellers(entity.RankingBestsellerAll, nil, s.size); err != nil {
+		return err
+	}
+
+	return s.rankingRepo.RefreshTrending(s.halfLife, s.size)
+}
+
+func (s *RankingService) GetBestsellers(window string, limit int) (*[]model.RankedBookResponse, error) {
+	if window == "" {
+		window = model.Window7Days
+	}
+
+	list, ok := bestsellerLists[window]
+	if !ok {
+		return nil, &response.BadRequest
+	}
+
+	return s.getRanking(list, limit)
+}
+
+func (s *RankingService) GetTrending(limit int) (*[]model.RankedBookResponse, error) {
+	return s.getRanking(entity.RankingTrending, limit)
+}
+
+func (s *RankingService) getRanking(list string, limit int) (*[]model.RankedBookResponse, error) {
+	if limit < 1 || limit > s.size {
+		limit = 10
+	}
+
+	var rankings []model.BookRanking
+	if err := s.rankingRepo.GetRanking(&rankings, list, limit); err != nil {
+		return nil, err
+	}
+
+	books := make([]model.BookResponse, len(rankings))
+	for i, ranking := range rankings {
+		books[i] = model.BookToBookResponse(ranking.Book)
+	}
+
+	if err := fillRatings(s.commentRepo, books); err != nil {
+		return nil, err
+	}
+
+	if err := fillPrices(s.saleRepo, books); err != nil {
+		return nil, err
+	}
+
+	ranked := make([]model.RankedBookResponse, len(rankings))
+	for i, ranking := range rankings {
+		ranked[i] = model.RankedBookResponse{
+			BookResponse: books[i],
+			Position:     ranking.Position,
+			Score:        ranking.Score,
+		}
+	}
+
+	return &ranked, nil
+}
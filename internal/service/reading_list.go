@@ -0,0 +1,170 @@
+package service
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+type IReadingListService interface {
+	GetReadingLists(userId uuid.UUID) (*[]model.ReadingListSummary, error)
+	GetReadingList(listId uuid.UUID, userId uuid.UUID) (*model.ReadingListResponse, error)
+	CreateReadingList(userId uuid.UUID, create model.CreateReadingList) error
+	EditReadingList(userId uuid.UUID, edit model.EditReadingList) error
+	DeleteReadingList(listId uuid.UUID, userId uuid.UUID) error
+	AddBook(listId uuid.UUID, userId uuid.UUID, add model.AddReadingListBook) error
+	RemoveBook(listId uuid.UUID, userId uuid.UUID, bookId uuid.UUID) error
+}
+
+type ReadingListService struct {
+	readingListRepo repository.IReadingListRepository
+	userRepo        repository.IUserRepository
+	bookRepo        repository.IBookRepository
+	commentRepo     repository.ICommentRepository
+	saleRepo        repository.ISaleRepository
+}
+
+func NewReadingListService(readingListRepo repository.IReadingListRepository, userRepo repository.IUserRepository, bookRepo repository.IBookRepository, commentRepo repository.ICommentRepository, saleRepo repository.ISaleRepository) IReadingListService {
+	return &ReadingListService{
+		readingListRepo: readingListRepo,
+		userRepo:        userRepo,
+		bookRepo:        bookRepo,
+		commentRepo:     commentRepo,
+		saleRepo:        saleRepo,
+	}
+}
+
+func (s *ReadingListService) GetReadingLists(userId uuid.UUID) (*[]model.ReadingListSummary, error) {
+	var lists []model.ReadingListSummary
+	if err := s.readingListRepo.GetUserReadingLists(&lists, userId, false); err != nil {
+		return nil, err
+	}
+
+	return &lists, nil
+}
+
+// GetReadingList shows a list to its owner, and to anyone else while it is
+// public and its owner's profile is not private
+func (s *ReadingListService) GetReadingList(listId uuid.UUID, userId uuid.UUID) (*model.ReadingListResponse, error) {
+	var list entity.ReadingList
+	if err := s.readingListRepo.GetReadingList(&list, listId); err != nil {
+		return nil, err
+	}
+
+	if list.UserId != userId {
+		var owner entity.User
+		if err := s.userRepo.GetUser(&owner, list.UserId); err != nil {
+			return nil, err
+		}
+
+		if !list.IsPublic || owner.IsPrivate {
+			return nil, &response.ReadingListNotFound
+		}
+	}
+
+	var rows []model.ReadingListBookRow
+	if err := s.readingListRepo.GetReadingListBooks(&rows, listId); err != nil {
+		return nil, err
+	}
+
+	books := make([]model.BookResponse, len(rows))
+	for i, row := range rows {
+		books[i] = model.BookToBookResponse(row.Book)
+	}
+
+	if err := fillRatings(s.commentRepo, books); err != nil {
+		return nil, err
+	}
+
+	if err := fillPrices(s.saleRepo, books); err != nil {
+		return nil, err
+	}
+
+	entries := make([]model.ReadingListEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = model.ReadingListEntry{
+			Book:    books[i],
+			AddedAt: row.AddedAt,
+		}
+	}
+
+	return &model.ReadingListResponse{
+		ReadingList: list,
+		Books:       entries,
+	}, nil
+}
+
+func (s *ReadingListService) CreateReadingList(userId uuid.UUID, create model.CreateReadingList) error {
+	now := time.Now()
+	return s.readingListRepo.CreateReadingList(&entity.ReadingList{
+		Id:          uuid.New(),
+		UserId:      userId,
+		Name:        create.Name,
+		Description: create.Description,
+		IsPublic:    create.IsPublic,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+func (s *ReadingListService) EditReadingList(userId uuid.UUID, edit model.EditReadingList) error {
+	list, err := s.ownList(edit.Id, userId)
+	if err != nil {
+		return err
+	}
+
+	if edit.Name != "" {
+		list.Name = edit.Name
+	}
+	if edit.Description != "" {
+		list.Description = edit.Description
+	}
+	if edit.IsPublic != nil {
+		list.IsPublic = *edit.IsPublic
+	}
+
+	return s.readingListRepo.EditReadingList(list)
+}
+
+func (s *ReadingListService) DeleteReadingList(listId uuid.UUID, userId uuid.UUID) error {
+	return s.readingListRepo.DeleteReadingList(listId, userId)
+}
+
+func (s *ReadingListService) AddBook(listId uuid.UUID, userId uuid.UUID, add model.AddReadingListBook) error {
+	if _, err := s.ownList(listId, userId); err != nil {
+		return err
+	}
+
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, add.BookId); err != nil {
+		return err
+	}
+
+	return s.readingListRepo.AddReadingListBook(listId, add.BookId)
+}
+
+func (s *ReadingListService) RemoveBook(listId uuid.UUID, userId uuid.UUID, bookId uuid.UUID) error {
+	if _, err := s.ownList(listId, userId); err != nil {
+		return err
+	}
+
+	return s.readingListRepo.RemoveReadingListBook(listId, bookId)
+}
+
+// ownList loads a list of the user's; someone else's list is reported missing
+func (s *ReadingListService) ownList(listId uuid.UUID, userId uuid.UUID) (*entity.ReadingList, error) {
+	var list entity.ReadingList
+	if err := s.readingListRepo.GetReadingList(&list, listId); err != nil {
+		return nil, err
+	}
+
+	if list.UserId != userId {
+		return nil, &response.ReadingListNotFound
+	}
+
+	return &list, nil
+}
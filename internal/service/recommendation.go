@@ -0,0 +1,145 @@
+package service
+
+import (
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/google/uuid"
+)
+
+type IRecommendationService interface {
+	RefreshSimilarities() error
+	GetRelatedBooks(bookId uuid.UUID, limit int) (*[]model.BookResponse, error)
+	GetRecommendedBooks(userId uuid.UUID, limit int) (*[]model.BookResponse, error)
+}
+
+type RecommendationService struct {
+	recommendationRepo repository.IRecommendationRepository
+	bookRepo           repository.IBookRepository
+	commentRepo        repository.ICommentRepository
+	saleRepo           repository.ISaleRepository
+	neighbours         int
+}
+
+func NewRecommendationService(recommendationRepo repository.IRecommendationRepository, bookRepo repository.IBookRepository, commentRepo repository.ICommentRepository, saleRepo repository.ISaleRepository) IRecommendationService {
+	return &RecommendationService{
+		recommendationRepo: recommendationRepo,
+		bookRepo:           bookRepo,
+		commentRepo:        commentRepo,
+		saleRepo:           saleRepo,
+		neighbours:         envInt("RECOMMENDATION_NEIGHBOURS", 20),
+	}
+}
+
+func (s *RecommendationService) RefreshSimilarities() error {
+	return s.recommendationRepo.RefreshSimilarities(s.neighbours)
+}
+
+func (s *RecommendationService) GetRelatedBooks(bookId uuid.UUID, limit int) (*[]model.BookResponse, error) {
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return nil, err
+	}
+
+	var related []entity.Book
+	if err := s.recommendationRepo.GetRelatedBooks(&related, bookId, limit); err != nil {
+		return nil, err
+	}
+
+	books, err := s.fallback(related, []uuid.UUID{bookId}, []uuid.UUID{bookId}, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toResponses(books)
+}
+
+func (s *RecommendationService) GetRecommendedBooks(userId uuid.UUID, limit int) (*[]model.BookResponse, error) {
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	var recommended []entity.Book
+	if err := s.recommendationRepo.GetRecommendedBooks(&recommended, userId, limit); err != nil {
+		return nil, err
+	}
+
+	seeds, err := s.recommendationRepo.GetUserSeeds(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := s.fallback(recommended, seeds, seeds, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// users with no history at all still get the catalog's best
+	if len(books) < limit {
+		var results []model.BookSearchResult
+		search := model.BookSearch{Page: 1, PageSize: limit + len(books), Sort: model.SortTopRated}
+		if err := s.bookRepo.SearchBooks(&results, search); err != nil {
+			return nil, err
+		}
+
+		seen := make(map[uuid.UUID]bool, len(books)+len(seeds))
+		for _, id := range seeds {
+			seen[id] = true
+		}
+		for _, book := range books {
+			seen[book.Id] = true
+		}
+
+		for _, result := range results {
+			if len(books) == limit {
+				break
+			}
+			if !seen[result.Id] {
+				books = append(books, result.Book)
+			}
+		}
+	}
+
+	return s.toResponses(books)
+}
+
+// fallback tops up books from the same authors and categories as seedIds when
+// purchase data alone does not fill the limit
+func (s *RecommendationService) fallback(books []entity.Book, seedIds []uuid.UUID, excludeIds []uuid.UUID, limit int) ([]entity.Book, error) {
+	if len(books) >= limit || len(seedIds) == 0 {
+		return books, nil
+	}
+
+	exclude := append([]uuid.UUID{}, excludeIds...)
+	for _, book := range books {
+		exclude = append(exclude, book.Id)
+	}
+
+	var similar []entity.Book
+	if err := s.recommendationRepo.GetSimilarBooks(&similar, seedIds, exclude, limit-len(books)); err != nil {
+		return nil, err
+	}
+
+	return append(books, similar...), nil
+}
+
+func (s *RecommendationService) toResponses(books []entity.Book) (*[]model.BookResponse, error) {
+	booksResponse := make([]model.BookResponse, len(books))
+	for i, book := range books {
+		booksResponse[i] = model.BookToBookResponse(book)
+	}
+
+	if err := fillRatings(s.commentRepo, booksResponse); err != nil {
+		return nil, err
+	}
+
+	if err := fillPrices(s.saleRepo, booksResponse); err != nil {
+		return nil, err
+	}
+
+	return &booksResponse, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+type ISaleService interface {
+	GetBookSales(bookId uuid.UUID) (*[]entity.Sale, error)
+	CreateSale(bookId uuid.UUID, adminId uuid.UUID, create model.CreateSale) error
+	EndSale(saleId uuid.UUID) error
+	GetPriceHistory(bookId uuid.UUID, req model.PriceHistoryReq) (*[]entity.PriceHistory, error)
+	RecordPrices() error
+}
+
+type SaleService struct {
+	saleRepo   repository.ISaleRepository
+	bookRepo   repository.IBookRepository
+	formatRepo repository.IBookFormatRepository
+}
+
+func NewSaleService(saleRepo repository.ISaleRepository, bookRepo repository.IBookRepository, formatRepo repository.IBookFormatRepository) ISaleService {
+	return &SaleService{
+		saleRepo:   saleRepo,
+		bookRepo:   bookRepo,
+		formatRepo: formatRepo,
+	}
+}
+
+func (s *SaleService) GetBookSales(bookId uuid.UUID) (*[]entity.Sale, error) {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return nil, err
+	}
+
+	var sales []entity.Sale
+	if err := s.saleRepo.GetBookSales(&sales, bookId); err != nil {
+		return nil, err
+	}
+
+	return &sales, nil
+}
+
+func (s *SaleService) CreateSale(bookId uuid.UUID, adminId uuid.UUID, create model.CreateSale) error {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return err
+	}
+
+	var format entity.BookFormat
+	if create.FormatId == uuid.Nil {
+		if err := s.formatRepo.GetBookFormat(&format, bookId, entity.FormatEbook); err != nil {
+			return err
+		}
+	} else {
+		if err := s.formatRepo.GetFormat(&format, create.FormatId); err != nil {
+			return err
+		}
+
+		if format.BookId != bookId {
+			return &response.FormatNotFound
+		}
+	}
+
+	if create.SalePrice >= format.Price {
+		return &response.InvalidSale
+	}
+
+	if !create.EndsAt.After(time.Now()) {
+		return &response.BadRequest
+	}
+
+	return s.saleRepo.CreateSale(&entity.Sale{
+		Id:        uuid.New(),
+		BookId:    bookId,
+		FormatId:  format.Id,
+		SalePrice: create.SalePrice,
+		StartsAt:  create.StartsAt,
+		EndsAt:    create.EndsAt,
+		Quantity:  create.Quantity,
+		CreatedBy: adminId,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *SaleService) EndSale(saleId uuid.UUID) error {
+	return s.saleRepo.EndSale(saleId)
+}
+
+func (s *SaleService) GetPriceHistory(bookId uuid.UUID, req model.PriceHistoryReq) (*[]entity.PriceHistory, error) {
+	var book entity.Book
+	if err := s.bookRepo.GetBook(&book, bookId); err != nil {
+		return nil, err
+	}
+
+	var history []entity.PriceHistory
+	if err := s.saleRepo.GetPriceHistory(&history, bookId, req); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+// RecordPrices runs on a schedule for sales that start, end or sell out on their own
+func (s *SaleService) RecordPrices() error {
+	return s.saleRepo.RecordPrices()
+}
+
+// fillPrices resolves the ebook price of a page of books in one query
+func fillPrices(saleRepo repository.ISaleRepository, books []model.BookResponse) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	bookIds := make([]uuid.UUID, len(books))
+	for i, book := range books {
+		bookIds[i] = book.Id
+	}
+
+	var prices []model.FormatPrice
+	if err := saleRepo.GetFormatPrices(&prices, bookIds); err != nil {
+		return err
+	}
+
+	byBook := make(map[uuid.UUID]model.FormatPrice, len(books))
+	for _, price := range prices {
+		if price.Format == entity.FormatEbook {
+			byBook[price.BookId] = price
+		}
+	}
+
+	for i := range books {
+		applyPrice(&books[i], byBook[books[i].Id])
+	}
+
+	return nil
+}
+
+func applyPrice(book *model.BookResponse, price model.FormatPrice) {
+	if price.FormatId == uuid.Nil {
+		book.EffectivePrice = book.Price
+		book.LowestPrice30d = book.Price
+		return
+	}
+
+	book.EffectivePrice = price.EffectivePrice
+	book.SaleEndsAt = price.SaleEndsAt
+	book.LowestPrice30d = price.LowestPrice30d
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
+	"github.com/google/uuid"
+)
+
+type ISeriesService interface {
+	GetAllSeries() (*[]entity.Series, error)
+	GetSeries(seriesId uuid.UUID) (*model.SeriesResponse, error)
+	CreateSeries(create model.CreateSeries) error
+	EditSeries(edit model.EditSeries) error
+	DeleteSeries(seriesId uuid.UUID) error
+	SetSeriesBooks(seriesId uuid.UUID, set model.SetSeriesBooks) error
+	AddSeriesToCart(seriesId uuid.UUID, userId uuid.UUID) (*model.SeriesCartResult, error)
+}
+
+type SeriesService struct {
+	seriesRepo  repository.ISeriesRepository
+	bookRepo    repository.IBookRepository
+	commentRepo repository.ICommentRepository
+	saleRepo    repository.ISaleRepository
+	formatRepo  repository.IBookFormatRepository
+	paymentRepo repository.IPaymentRepository
+	cartRepo    repository.ICartRepository
+}
+
+func NewSeriesService(seriesRepo repository.ISeriesRepository, bookRepo repository.IBookRepository, commentRepo repository.ICommentRepository, saleRepo repository.ISaleRepository, formatRepo repository.IBookFormatRepository, paymentRepo repository.IPaymentRepository, cartRepo repository.ICartRepository) ISeriesService {
+	return &SeriesService{
+		seriesRepo:  seriesRepo,
+		bookRepo:    bookRepo,
+		commentRepo: commentRepo,
+		saleRepo:    saleRepo,
+		formatRepo:  formatRepo,
+		paymentRepo: paymentRepo,
+		cartRepo:    cartRepo,
+	}
+}
+
+func (s *SeriesService) GetAllSeries() (*[]entity.Series, error) {
+	var series []entity.Series
+	if err := s.seriesRepo.GetAllSeries(&series); err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+func (s *SeriesService) GetSeries(seriesId uuid.UUID) (*model.SeriesResponse, error) {
+	var series entity.Series
+	if err := s.seriesRepo.GetSeries(&series, seriesId); err != nil {
+		return nil, err
+	}
+
+	var rows []model.SeriesBookRow
+	if err := s.seriesRepo.GetSeriesBooks(&rows, seriesId); err != nil {
+		return nil, err
+	}
+
+	books := make([]model.BookResponse, len(rows))
+	for i, row := range rows {
+		books[i] = model.BookToBookResponse(row.Book)
+	}
+
+	if err := fillRatings(s.commentRepo, books); err != nil {
+		return nil, err
+	}
+
+	if err := fillPrices(s.saleRepo, books); err != nil {
+		return nil, err
+	}
+
+	volumes := make([]model.SeriesVolume, len(rows))
+	for i, row := range rows {
+		volumes[i] = model.SeriesVolume{
+			Position: row.Position,
+			Label:    row.Label,
+			Book:     books[i],
+		}
+	}
+
+	return &model.SeriesResponse{
+		Series: series,
+		Books:  volumes,
+	}, nil
+}
+
+func (s *SeriesService) CreateSeries(create model.CreateSeries) error {
+	return s.seriesRepo.CreateSeries(&entity.Series{
+		Id:          uuid.New(),
+		Name:        create.Name,
+		Description: create.Description,
+		CreatedAt:   time.Now(),
+	})
+}
+
+func (s *SeriesService) EditSeries(edit model.EditSeries) error {
+	var series entity.Series
+	if err := s.seriesRepo.GetSeries(&series, edit.Id); err != nil {
+		return err
+	}
+
+	if edit.Name != "" {
+		series.Name = edit.Name
+	}
+	if edit.Description != "" {
+		series.Description = edit.Description
+	}
+
+	return s.seriesRepo.EditSeries(&series)
+}
+
+func (s *SeriesService) DeleteSeries(seriesId uuid.UUID) error {
+	return s.seriesRepo.DeleteSeries(seriesId)
+}
+
+func (s *SeriesService) SetSeriesBooks(seriesId uuid.UUID, set model.SetSeriesBooks) error {
+	var series entity.Series
+	if err := s.seriesRepo.GetSeries(&series, seriesId); err != nil {
+		return err
+	}
+
+	books := make([]entity.SeriesBook, len(set.Books))
+	for i, entry := range set.Books {
+		books[i] = entity.SeriesBook{
+			SeriesId: seriesId,
+			BookId:   entry.BookId,
+			Position: i + 1,
+			Label:    entry.Label,
+		}
+	}
+
+	return s.seriesRepo.SetSeriesBooks(seriesId, books)
+}
+
+// AddSeriesToCart puts the ebook of every volume in the cart, in reading
+// order, skipping volumes without an ebook and ones already owned or in the
+// cart; the rest are added together or not at all
+func (s *SeriesService) AddSeriesToCart(seriesId uuid.UUID, userId uuid.UUID) (*model.SeriesCartResult, error) {
+	var series entity.Series
+	if err := s.seriesRepo.GetSeries(&series, seriesId); err != nil {
+		return nil, err
+	}
+
+	var rows []model.SeriesBookRow
+	if err := s.seriesRepo.GetSeriesBooks(&rows, seriesId); err != nil {
+		return nil, err
+	}
+
+	result := &model.SeriesCartResult{
+		Added:   []uuid.UUID{},
+		Skipped: []uuid.UUID{},
+	}
+	var formats []entity.BookFormat
+	for _, row := range rows {
+		var format entity.BookFormat
+		err := s.formatRepo.GetBookFormat(&format, row.Id, entity.FormatEbook)
+		if err == &response.FormatNotFound {
+			result.Skipped = append(result.Skipped, row.Id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		owned, err := s.paymentRepo.CheckUserFormatPurchase(userId, format.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		var cart entity.Cart
+		err = s.cartRepo.GetOpenCart(&cart, userId, format.Id)
+		if err != nil && err != &response.CartNotFound {
+			return nil, err
+		}
+
+		if owned || err == nil {
+			result.Skipped = append(result.Skipped, row.Id)
+			continue
+		}
+
+		formats = append(formats, format)
+		result.Added = append(result.Added, row.Id)
+	}
+
+	if err := s.cartRepo.AddFormatsToCart(userId, formats); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/bcrypt"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/ebook"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/jwt"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/midtrans"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/smtp"
@@ -10,23 +11,62 @@ import (
 )
 
 type Service struct {
-	UserService     IUserService
-	AuthService     IAuthService
-	BookService     IBookService
-	CartService     ICartService
-	CommentService  ICommentService
-	CheckoutService ICheckoutService
-	PaymentService  IPaymentService
+	UserService           IUserService
+	AuthService           IAuthService
+	BookService           IBookService
+	CartService           ICartService
+	CommentService        ICommentService
+	CheckoutService       ICheckoutService
+	PaymentService        IPaymentService
+	UploadService         IUploadService
+	NotificationService   INotificationService
+	CategoryService       ICategoryService
+	AuthorService         IAuthorService
+	PublisherService      IPublisherService
+	PreviewService        IPreviewService
+	RecommendationService IRecommendationService
+	RankingService        IRankingService
+	WishlistService       IWishlistService
+	InventoryService      IInventoryService
+	BookFormatService     IBookFormatService
+	PromotionService      IPromotionService
+	SaleService           ISaleService
+	PreorderService       IPreorderService
+	SeriesService         ISeriesService
+	BundleService         IBundleService
+	ReadingListService    IReadingListService
 }
 
-func NewService(repository *repository.Repository, bcrypt bcrypt.IBcrypt, jwt jwt.IJwt, smtp *smtp.SMTPClient, midtrans midtrans.IMidtrans, supabase supabase.ISupabase) *Service {
+func NewService(repository *repository.Repository, bcrypt bcrypt.IBcrypt, jwt jwt.IJwt, smtp *smtp.SMTPClient, midtrans midtrans.IMidtrans, supabase supabase.ISupabase, ebook ebook.IEbook) *Service {
+	notificationService := NewNotificationService(repository.NotificationRepository, repository.UserRepository, smtp)
+	promotionService := NewPromotionService(repository.PromotionRepository, repository.BookRepository, repository.CategoryRepository)
+	previewService := NewPreviewService(repository.BookRepository, repository.SampleRepository, supabase, ebook)
+	cartService := NewCartService(repository.CartRepository, repository.UserRepository, repository.BookRepository, repository.BookFormatRepository, repository.PaymentRepository, repository.SaleRepository, repository.BundleRepository)
+
 	return &Service{
-		UserService:     NewUserService(repository.UserRepository, repository.CartRepository, repository.PaymentRepository, repository.AuthRepository, repository.CheckoutRepository, repository.CommentRepository, supabase),
-		AuthService:     NewAuthService(repository.AuthRepository, repository.UserRepository, bcrypt, jwt, smtp),
-		BookService:     NewBookService(repository.BookRepository, repository.CartRepository, repository.CommentRepository, supabase),
-		CartService:     NewCartService(repository.CartRepository, repository.UserRepository, repository.BookRepository),
-		CommentService:  NewCommentService(repository.CommentRepository, repository.UserRepository),
-		CheckoutService: NewCheckoutService(repository.CheckoutRepository, repository.CartRepository, repository.BookRepository, repository.UserRepository),
-		PaymentService:  NewPaymentService(repository.PaymentRepository, midtrans, repository.UserRepository, repository.BookRepository, repository.CheckoutRepository),
+		UserService:           NewUserService(repository.UserRepository, repository.CartRepository, repository.PaymentRepository, repository.AuthRepository, repository.CheckoutRepository, repository.CommentRepository, repository.ReadingListRepository, repository.UploadRepository, supabase),
+		AuthService:           NewAuthService(repository.AuthRepository, repository.UserRepository, bcrypt, jwt, smtp),
+		BookService:           NewBookService(repository.BookRepository, repository.CartRepository, repository.CommentRepository, repository.UploadRepository, repository.CategoryRepository, repository.AuthorRepository, repository.PublisherRepository, repository.BookRevisionRepository, repository.PaymentRepository, repository.DownloadRepository, repository.UserRepository, repository.BookFormatRepository, repository.SaleRepository, repository.SeriesRepository, previewService, supabase, ebook),
+		CartService:           cartService,
+		CommentService:        NewCommentService(repository.CommentRepository, repository.UserRepository),
+		CheckoutService:       NewCheckoutService(repository.CheckoutRepository, repository.CartRepository, repository.BookRepository, repository.UserRepository, repository.BookFormatRepository, repository.PaymentRepository, repository.SaleRepository, repository.BundleRepository, promotionService),
+		PaymentService:        NewPaymentService(repository.PaymentRepository, midtrans, repository.UserRepository, repository.BookRepository, repository.CheckoutRepository, repository.InventoryRepository, repository.PreorderRepository),
+		UploadService:         NewUploadService(repository.UploadRepository, supabase),
+		NotificationService:   notificationService,
+		CategoryService:       NewCategoryService(repository.CategoryRepository),
+		AuthorService:         NewAuthorService(repository.AuthorRepository, repository.UploadRepository, supabase),
+		PublisherService:      NewPublisherService(repository.PublisherRepository, repository.UploadRepository, supabase),
+		PreviewService:        previewService,
+		RecommendationService: NewRecommendationService(repository.RecommendationRepository, repository.BookRepository, repository.CommentRepository, repository.SaleRepository),
+		RankingService:        NewRankingService(repository.RankingRepository, repository.CommentRepository, repository.SaleRepository),
+		WishlistService:       NewWishlistService(repository.WishlistRepository, repository.CommentRepository, repository.SaleRepository, notificationService, cartService),
+		InventoryService:      NewInventoryService(repository.InventoryRepository, repository.BookRepository, repository.BookFormatRepository),
+		BookFormatService:     NewBookFormatService(repository.BookFormatRepository, repository.BookRepository),
+		PromotionService:      promotionService,
+		SaleService:           NewSaleService(repository.SaleRepository, repository.BookRepository, repository.BookFormatRepository),
+		SeriesService:         NewSeriesService(repository.SeriesRepository, repository.BookRepository, repository.CommentRepository, repository.SaleRepository, repository.BookFormatRepository, repository.PaymentRepository, repository.CartRepository),
+		BundleService:         NewBundleService(repository.BundleRepository, repository.BookRepository, repository.BookFormatRepository, repository.CartRepository, repository.PaymentRepository, repository.CommentRepository, repository.SaleRepository),
+		ReadingListService:    NewReadingListService(repository.ReadingListRepository, repository.UserRepository, repository.BookRepository, repository.CommentRepository, repository.SaleRepository),
+		PreorderService:       NewPreorderService(repository.PreorderRepository, repository.BookRepository, midtrans, notificationService),
 	}
 }
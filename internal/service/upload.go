@@ -0,0 +1,51 @@
+package service
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/supabase"
+	"github.com/google/uuid"
+)
+
+type IUploadService interface {
+	SweepUploads(gracePeriod time.Duration) error
+}
+
+type UploadService struct {
+	uploadRepo repository.IUploadRepository
+	supabase   supabase.ISupabase
+}
+
+func NewUploadService(uploadRepo repository.IUploadRepository, supabase supabase.ISupabase) IUploadService {
+	return &UploadService{
+		uploadRepo: uploadRepo,
+		supabase:   supabase,
+	}
+}
+
+// removes objects that were never referenced or have been replaced, once they are older than the grace period
+func (s *UploadService) SweepUploads(gracePeriod time.Duration) error {
+	var uploads []entity.Upload
+	if err := s.uploadRepo.GetExpiredUploads(&uploads, time.Now().Add(-gracePeriod)); err != nil {
+		return err
+	}
+
+	if len(uploads) == 0 {
+		return nil
+	}
+
+	paths := make([]string, len(uploads))
+	uploadIds := make([]uuid.UUID, len(uploads))
+	for i, upload := range uploads {
+		paths[i] = upload.Path
+		uploadIds[i] = upload.Id
+	}
+
+	if err := s.supabase.DeleteFiles(paths); err != nil {
+		return err
+	}
+
+	return s.uploadRepo.DeleteUploads(uploadIds)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"mime/multipart"
+	"time"
 
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
@@ -13,32 +14,37 @@ import (
 type IUserService interface {
 	GetProfiles(profiles *[]model.Profile, profilesReq model.ProfilesReq) error
 	GetProfile(profile *model.Profile, userId uuid.UUID) error
+	GetPublicProfile(userId uuid.UUID, req model.PublicProfileReq) (*model.PublicProfile, error)
 	GetUserById(user *entity.User, userId uuid.UUID) (err error)
 	UpdateRole(userProfile *model.RoleUpdate) error
 	EditProfile(edit *model.EditProfile) error
 	DeleteUser(userId uuid.UUID) error
-	UploadProfilePicture(file *multipart.FileHeader) (string, error)
+	UploadProfilePicture(file *multipart.FileHeader, userId uuid.UUID) (string, error)
 }
 
 type UserService struct {
-	UserRepository     repository.IUserRepository
-	CartRepository     repository.ICartRepository
-	PaymentRepository  repository.IPaymentRepository
-	AuthRepository     repository.IAuthRepository
-	CheckoutRepository repository.ICheckoutRepository
-	CommentRepository  repository.ICommentRepository
-	Supabase           supabase.ISupabase
+	UserRepository        repository.IUserRepository
+	CartRepository        repository.ICartRepository
+	PaymentRepository     repository.IPaymentRepository
+	AuthRepository        repository.IAuthRepository
+	CheckoutRepository    repository.ICheckoutRepository
+	CommentRepository     repository.ICommentRepository
+	ReadingListRepository repository.IReadingListRepository
+	UploadRepository      repository.IUploadRepository
+	Supabase              supabase.ISupabase
 }
 
-func NewUserService(userRepository repository.IUserRepository, cartRepository repository.ICartRepository, paymentRepository repository.IPaymentRepository, authRepository repository.IAuthRepository, checkoutRepository repository.ICheckoutRepository, CommentRepository repository.ICommentRepository, Supabase supabase.ISupabase) IUserService {
+func NewUserService(userRepository repository.IUserRepository, cartRepository repository.ICartRepository, paymentRepository repository.IPaymentRepository, authRepository repository.IAuthRepository, checkoutRepository repository.ICheckoutRepository, CommentRepository repository.ICommentRepository, readingListRepository repository.IReadingListRepository, uploadRepository repository.IUploadRepository, Supabase supabase.ISupabase) IUserService {
 	return &UserService{
-		UserRepository:     userRepository,
-		CartRepository:     cartRepository,
-		PaymentRepository:  paymentRepository,
-		AuthRepository:     authRepository,
-		CheckoutRepository: checkoutRepository,
-		CommentRepository:  CommentRepository,
-		Supabase:           Supabase,
+		UserRepository:        userRepository,
+		CartRepository:        cartRepository,
+		PaymentRepository:     paymentRepository,
+		AuthRepository:        authRepository,
+		CheckoutRepository:    checkoutRepository,
+		CommentRepository:     CommentRepository,
+		ReadingListRepository: readingListRepository,
+		UploadRepository:      uploadRepository,
+		Supabase:              Supabase,
 	}
 }
 
@@ -76,6 +82,36 @@ func (s *UserService) GetProfile(profile *model.Profile, userId uuid.UUID) error
 	return nil
 }
 
+func (s *UserService) GetPublicProfile(userId uuid.UUID, req model.PublicProfileReq) (*model.PublicProfile, error) {
+	var user entity.User
+	if err := s.UserRepository.GetUser(&user, userId); err != nil {
+		return nil, err
+	}
+
+	if user.IsPrivate {
+		profile := model.UserToPublicProfile(user, 0, nil, nil)
+		return &profile, nil
+	}
+
+	var booksReviewed int
+	if err := s.CommentRepository.CountBooksReviewed(&booksReviewed, userId); err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.CommentRepository.GetUserReviews(userId, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := []model.ReadingListSummary{}
+	if err := s.ReadingListRepository.GetUserReadingLists(&lists, userId, true); err != nil {
+		return nil, err
+	}
+
+	profile := model.UserToPublicProfile(user, booksReviewed, *reviews, lists)
+	return &profile, nil
+}
+
 func (s *UserService) UpdateRole(userProfile *model.RoleUpdate) error {
 	err := s.UserRepository.UpdateRole(userProfile.Id, userProfile.RoleId)
 	if err != nil {
@@ -100,10 +136,24 @@ func (s *UserService) EditProfile(edit *model.EditProfile) error {
 		edit.ProfilePicture = user.ProfilePicture
 	}
 
+	if edit.IsPrivate == nil {
+		edit.IsPrivate = &user.IsPrivate
+	}
+
 	if err := s.UserRepository.EditUser(edit); err != nil {
 		return err
 	}
 
+	if edit.ProfilePicture != user.ProfilePicture {
+		if err := s.UploadRepository.ReferenceUpload(edit.ProfilePicture); err != nil {
+			return err
+		}
+
+		if err := s.UploadRepository.SupersedeUpload(user.ProfilePicture); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -143,10 +193,31 @@ func (s *UserService) DeleteUser(userId uuid.UUID) error {
 		return err
 	}
 
+	if err := s.UploadRepository.SupersedeUpload(user.ProfilePicture); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (s *UserService) UploadProfilePicture(file *multipart.FileHeader) (string, error) {
-	url, err := s.Supabase.UploadFile(file, "profile")
-	return url, err
+func (s *UserService) UploadProfilePicture(file *multipart.FileHeader, userId uuid.UUID) (string, error) {
+	path, url, err := s.Supabase.UploadFile(file, "profile")
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.UploadRepository.CreateUpload(&entity.Upload{
+		Id:        uuid.New(),
+		OwnerId:   userId,
+		Purpose:   "profile",
+		Path:      path,
+		Url:       url,
+		Status:    entity.UploadPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		return "", err
+	}
+
+	return url, nil
 }
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
+	"github.com/google/uuid"
+)
+
+type IWishlistService interface {
+	GetWishlist(userId uuid.UUID) (*[]model.WishlistResponse, error)
+	AddToWishlist(userId uuid.UUID, bookId uuid.UUID) error
+	RemoveFromWishlist(userId uuid.UUID, bookId uuid.UUID) error
+	MoveToCart(userId uuid.UUID, bookId uuid.UUID, formatId uuid.UUID) error
+	SendAlerts() error
+}
+
+type WishlistService struct {
+	wishlistRepo        repository.IWishlistRepository
+	commentRepo         repository.ICommentRepository
+	saleRepo            repository.ISaleRepository
+	notificationService INotificationService
+	cartService         ICartService
+}
+
+func NewWishlistService(wishlistRepo repository.IWishlistRepository, commentRepo repository.ICommentRepository, saleRepo repository.ISaleRepository, notificationService INotificationService, cartService ICartService) IWishlistService {
+	return &WishlistService{
+		wishlistRepo:        wishlistRepo,
+		commentRepo:         commentRepo,
+		saleRepo:            saleRepo,
+		notificationService: notificationService,
+		cartService:         cartService,
+	}
+}
+
+func (s *WishlistService) GetWishlist(userId uuid.UUID) (*[]model.WishlistResponse, error) {
+	var items []model.WishlistItem
+	if err := s.wishlistRepo.GetWishlist(&items, userId); err != nil {
+		return nil, err
+	}
+
+	books := make([]model.BookResponse, len(items))
+	for i, item := range items {
+		books[i] = model.BookToBookResponse(item.Book)
+	}
+
+	if err := fillRatings(s.commentRepo, books); err != nil {
+		return nil, err
+	}
+
+	if err := fillPrices(s.saleRepo, books); err != nil {
+		return nil, err
+	}
+
+	wishlist := make([]model.WishlistResponse, len(items))
+	for i, item := range items {
+		wishlist[i] = model.WishlistResponse{
+			Book:    books[i],
+			AddedAt: item.AddedAt,
+		}
+	}
+
+	return &wishlist, nil
+}
+
+func (s *WishlistService) AddToWishlist(userId uuid.UUID, bookId uuid.UUID) error {
+	return s.wishlistRepo.AddToWishlist(userId, bookId)
+}
+
+func (s *WishlistService) RemoveFromWishlist(userId uuid.UUID, bookId uuid.UUID) error {
+	return s.wishlistRepo.RemoveFromWishlist(userId, bookId)
+}
+
+// MoveToCart goes through the cart rules, so an owned ebook stays on the wishlist
+func (s *WishlistService) MoveToCart(userId uuid.UUID, bookId uuid.UUID, formatId uuid.UUID) error {
+	add := model.AddToCart{
+		BookId:   bookId,
+		FormatId: formatId,
+		Amount:   1,
+	}
+	if err := s.cartService.AddToCart(add, userId); err != nil {
+		return err
+	}
+
+	return s.wishlistRepo.RemoveFromWishlist(userId, bookId)
+}
+
+// SendAlerts notifies wishlisters of price drops below the price they last saw
+// and of pre-announced books that are now released. A failed notification is
+// left pending and retried on the next run.
+func (s *WishlistService) SendAlerts() error {
+	var errs []error
+
+	var drops []model.WishlistAlert
+	if err := s.wishlistRepo.GetPriceDrops(&drops); err != nil {
+		return err
+	}
+
+	for _, drop := range drops {
+		title := fmt.Sprintf("Price drop: %s", drop.Title)
+		body := fmt.Sprintf("%s from your wishlist is now Rp%.0f, down from Rp%.0f.", drop.Title, drop.Price, drop.AlertPrice)
+		if err := s.notificationService.NotifyOnce(drop.UserId, fmt.Sprintf("price-drop:%s:%.0f", drop.BookId, drop.Price), entity.NotificationPriceDrops, title, body); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := s.wishlistRepo.SetAlertPrice(drop.UserId, drop.BookId, drop.Price); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := s.wishlistRepo.RaiseAlertPrices(); err != nil {
+		errs = append(errs, err)
+	}
+
+	var releases []model.WishlistAlert
+	if err := s.wishlistRepo.GetReleases(&releases); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	for _, release := range releases {
+		title := fmt.Sprintf("Now available: %s", release.Title)
+		body := fmt.Sprintf("%s from your wishlist has been released.", release.Title)
+		if err := s.notificationService.NotifyOnce(release.UserId, "release:"+release.BookId.String(), entity.NotificationNewReleases, title, body); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := s.wishlistRepo.MarkReleaseAlerted(release.UserId, release.BookId); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
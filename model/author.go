@@ -0,0 +1,52 @@
+package model
+
+import (
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
+
+type AuthorReq struct {
+	Page     int    `json:"page" validate:"required,min=1"`
+	PageSize int    `json:"page_size" validate:"required,min=1"`
+	Search   string `json:"search"`
+}
+
+type CreateAuthor struct {
+	Name  string `json:"name" validate:"required,lte=255"`
+	Bio   string `json:"bio"`
+	Photo string `json:"photo" validate:"omitempty,url"`
+}
+
+type EditAuthor struct {
+	Id    uuid.UUID `json:"id" validate:"required"`
+	Name  string    `json:"name" validate:"omitempty,lte=255"`
+	Bio   string    `json:"bio"`
+	Photo string    `json:"photo" validate:"omitempty,url"`
+}
+
+type BookAuthorReq struct {
+	AuthorId uuid.UUID `json:"author_id" validate:"required"`
+	Role     string    `json:"role" validate:"omitempty,oneof=author editor translator"`
+}
+
+type BookAuthorResponse struct {
+	Id       uuid.UUID `json:"id" db:"id"`
+	Name     string    `json:"name" db:"name"`
+	Role     string    `json:"role" db:"role"`
+	Position int       `json:"position" db:"position"`
+}
+
+type AuthorBook struct {
+	entity.Book
+	Role string `db:"role"`
+}
+
+type AuthorBookResponse struct {
+	BookResponse
+	Role string `json:"role"`
+}
+
+type AuthorResponse struct {
+	entity.Author
+	Books []AuthorBookResponse `json:"books"`
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
 	"github.com/google/uuid"
 )
@@ -10,43 +12,114 @@ type BookReq struct {
 	PageSize int `json:"page_size" validate:"required,min=1"`
 }
 
+const (
+	SortRelevance   = "relevance"
+	SortNewest      = "newest"
+	SortPriceAsc    = "price_asc"
+	SortPriceDesc   = "price_desc"
+	SortTitle       = "title"
+	SortRating      = "rating"
+	SortBestselling = "bestselling"
+	SortTopRated    = "top_rated"
+)
+
 type BookSearch struct {
-	Page        int    `json:"page" validate:"required,min=1"`
-	PageSize    int    `json:"page_size" validate:"required,min=1"`
-	SearchParam string `json:"search_param" validate:"required,min=1"`
+	Page         int       `json:"page" validate:"required,min=1"`
+	PageSize     int       `json:"page_size" validate:"required,min=1"`
+	SearchParam  string    `json:"search_param"`
+	Author       string    `json:"author"`
+	MinPrice     float64   `json:"min_price" validate:"omitempty,min=0"`
+	MaxPrice     float64   `json:"max_price" validate:"omitempty,min=0,gtefield=MinPrice"`
+	ReleasedFrom string    `json:"released_from" validate:"omitempty,datetime=2006-01-02"`
+	ReleasedTo   string    `json:"released_to" validate:"omitempty,datetime=2006-01-02"`
+	MinRating    float64   `json:"min_rating" validate:"omitempty,min=0,max=5"`
+	CategoryId   uuid.UUID `json:"category_id"`
+	Sort         string    `json:"sort" validate:"omitempty,oneof=relevance newest price_asc price_desc title rating bestselling top_rated"`
+}
+
+type FacetCount struct {
+	Value string `json:"value" db:"value"`
+	Count int    `json:"count" db:"count"`
+}
+
+type BookFacets struct {
+	Authors     []FacetCount `json:"authors"`
+	PriceRanges []FacetCount `json:"price_ranges"`
+	Ratings     []FacetCount `json:"ratings"`
 }
 
 type CreateBook struct {
-	Title        string  `json:"title" validate:"required,gte=5"`
-	Description  string  `json:"description" validate:"required,gte=10"`
-	Introduction string  `json:"introduction" validate:"required,gte=10"`
-	Image        string  `json:"image" validate:"required,url"`
-	File         string  `jsob:"file"`
-	Author       string  `json:"author" validate:"required,gte=5"`
-	ReleaseDate  string  `json:"release_date" validate:"required,datetime=2006-01-02"`
-	Price        float64 `json:"price" validate:"required,min=1000"`
+	Title        string          `json:"title" validate:"required,gte=5"`
+	Description  string          `json:"description" validate:"required,gte=10"`
+	Introduction string          `json:"introduction" validate:"required,gte=10"`
+	Image        string          `json:"image" validate:"required,url"`
+	Author       string          `json:"author" validate:"required,gte=5"`
+	ReleaseDate  string          `json:"release_date" validate:"required,datetime=2006-01-02"`
+	Price        float64         `json:"price" validate:"required,min=1000"`
+	CategoryIds  []uuid.UUID     `json:"category_ids"`
+	Authors      []BookAuthorReq `json:"authors"`
+	PublisherId  uuid.UUID       `json:"publisher_id"`
 }
 
 type BookResponse struct {
-	Id           uuid.UUID `json:"id"`
-	Title        string    `json:"title"`
-	Description  string    `json:"description"`
-	Introduction string    `json:"introduction"`
-	Image        string    `json:"image"`
-	Author       string    `json:"author"`
-	ReleaseDate  string    `json:"release_date"`
-	Price        float64   `json:"price"`
+	Id             uuid.UUID            `json:"id"`
+	Title          string               `json:"title"`
+	Description    string               `json:"description"`
+	Introduction   string               `json:"introduction"`
+	Image          string               `json:"image"`
+	Author         string               `json:"author"`
+	ReleaseDate    string               `json:"release_date"`
+	Price          float64              `json:"price"`
+	EffectivePrice float64              `json:"effective_price"`
+	SaleEndsAt     *time.Time           `json:"sale_ends_at,omitempty"`
+	LowestPrice30d float64              `json:"lowest_price_30d"`
+	HasFile        bool                 `json:"has_file"`
+	Rating         RatingSummary        `json:"rating"`
+	Headline       string               `json:"headline,omitempty"`
+	Categories     []entity.Category    `json:"categories,omitempty"`
+	Authors        []BookAuthorResponse `json:"authors,omitempty"`
+	Publisher      *entity.Publisher    `json:"publisher,omitempty"`
+	Formats        []FormatPrice        `json:"formats,omitempty"`
+	Series         []SeriesLink         `json:"series,omitempty"`
+}
+
+type RatingSummary struct {
+	Average   float64     `json:"average"`
+	Count     int         `json:"count"`
+	Histogram map[int]int `json:"histogram"`
+}
+
+type BookSearchResponse struct {
+	Books      []BookResponse `json:"books"`
+	Total      int            `json:"total"`
+	Facets     *BookFacets    `json:"facets,omitempty"`
+	DidYouMean string         `json:"did_you_mean,omitempty"`
+}
+
+type BookSuggestion struct {
+	Value  string     `json:"value" db:"value"`
+	Kind   string     `json:"kind" db:"kind"`
+	BookId *uuid.UUID `json:"book_id,omitempty" db:"book_id"`
+}
+
+type BookSearchResult struct {
+	entity.Book
+	Rank     float64 `db:"rank"`
+	Headline string  `db:"headline"`
 }
 
 type EditBook struct {
-	Id           uuid.UUID `json:"id" db:"id" validate:"required"`
-	Title        string    `json:"title" db:"title" validate:"omitempty,gte=5"`
-	Description  string    `json:"description" db:"description" validate:"omitempty,gte=10"`
-	Introduction string    `json:"introduction" db:"introduction" validate:"omitempty,gte=10"`
-	Image        string    `json:"image" db:"image" validate:"omitempty,url"`
-	Author       string    `json:"author" db:"author" validate:"omitempty,gte=5"`
-	ReleaseDate  string    `json:"release_date" db:"release_date" validate:"omitempty,datetime=2006-01-02"` //todo make a validator for date //note: sorry, i override yours
-	Price        float64   `json:"price" db:"price" validate:"omitempty,min=1000"`
+	Id           uuid.UUID       `json:"id" db:"id" validate:"required"`
+	Title        string          `json:"title" db:"title" validate:"omitempty,gte=5"`
+	Description  string          `json:"description" db:"description" validate:"omitempty,gte=10"`
+	Introduction string          `json:"introduction" db:"introduction" validate:"omitempty,gte=10"`
+	Image        string          `json:"image" db:"image" validate:"omitempty,url"`
+	Author       string          `json:"author" db:"author" validate:"omitempty,gte=5"`
+	ReleaseDate  string          `json:"release_date" db:"release_date" validate:"omitempty,datetime=2006-01-02"` //todo make a validator for date //note: sorry, i override yours
+	Price        float64         `json:"price" db:"price" validate:"omitempty,min=1000"`
+	CategoryIds  []uuid.UUID     `json:"category_ids" db:"-"`
+	Authors      []BookAuthorReq `json:"authors" db:"-"`
+	PublisherId  *uuid.UUID      `json:"publisher_id" db:"-"`
 }
 
 func BookToBookResponse(book entity.Book) BookResponse {
@@ -59,5 +132,27 @@ func BookToBookResponse(book entity.Book) BookResponse {
 		Author:       book.Author,
 		ReleaseDate:  book.ReleaseDate,
 		Price:        book.Price,
+		HasFile:      book.File != "",
+		Rating:       RatingToRatingSummary(entity.BookRating{BookId: book.Id}),
+	}
+}
+
+func SearchResultToBookResponse(result BookSearchResult) BookResponse {
+	bookResponse := BookToBookResponse(result.Book)
+	bookResponse.Headline = result.Headline
+	return bookResponse
+}
+
+func RatingToRatingSummary(rating entity.BookRating) RatingSummary {
+	return RatingSummary{
+		Average: rating.Average,
+		Count:   rating.RatingCount,
+		Histogram: map[int]int{
+			1: rating.Star1,
+			2: rating.Star2,
+			3: rating.Star3,
+			4: rating.Star4,
+			5: rating.Star5,
+		},
 	}
 }
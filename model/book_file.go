@@ -0,0 +1,57 @@
+package model
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"time"
+)
+
+const (
+	BookFilePdf  = "application/pdf"
+	BookFileEpub = "application/epub+zip"
+)
+
+type BookFile struct {
+	File *multipart.FileHeader `form:"file" validate:"required,book_file_type,book_file_size"`
+}
+
+type BookDownload struct {
+	Url       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GetBookFileType sniffs the upload; an epub is a zip whose first entry is
+// an uncompressed "mimetype" file, so it is told apart from other zips here
+func GetBookFileType(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+
+	defer src.Close()
+
+	buffer := make([]byte, 512)
+	n, err := src.Read(buffer)
+	if err != nil {
+		return "", err
+	}
+	buffer = buffer[:n]
+
+	if bytes.HasPrefix(buffer, []byte("PK\x03\x04")) && len(buffer) >= 58 &&
+		string(buffer[30:38]) == "mimetype" && string(buffer[38:58]) == BookFileEpub {
+		return BookFileEpub, nil
+	}
+
+	return http.DetectContentType(buffer), nil
+}
+
+// BookDraft is a CreateBook prefilled from a book file's own metadata.
+// Credits that match an existing author or publisher are linked by id, the
+// rest are listed so they can be created first.
+type BookDraft struct {
+	Book               CreateBook `json:"book"`
+	Isbn               string     `json:"isbn,omitempty"`
+	UnmatchedAuthors   []string   `json:"unmatched_authors"`
+	UnmatchedPublisher string     `json:"unmatched_publisher,omitempty"`
+}
@@ -0,0 +1,12 @@
+package model
+
+type CreateBookFormat struct {
+	Format string  `json:"format" validate:"required,oneof=ebook paperback hardcover audiobook"`
+	Sku    string  `json:"sku" validate:"omitempty,max=64"`
+	Price  float64 `json:"price" validate:"required,min=1000"`
+}
+
+type EditBookFormat struct {
+	Sku   string  `json:"sku" validate:"omitempty,max=64"`
+	Price float64 `json:"price" validate:"omitempty,min=1000"`
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+)
+
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type BookRevisionResponse struct {
+	Id             uuid.UUID      `json:"id" db:"id"`
+	BookId         uuid.UUID      `json:"book_id" db:"book_id"`
+	EditorId       uuid.UUID      `json:"editor_id" db:"editor_id"`
+	EditorUsername *string        `json:"editor_username" db:"editor_username"`
+	Changes        types.JSONText `json:"changes" db:"changes"`
+	RevertsId      uuid.UUID      `json:"reverts_id" db:"reverts_id"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
+
+type RevertBook struct {
+	RevisionId uuid.UUID `json:"revision_id" validate:"required"`
+}
+
+// DiffBook lists the audited fields an edit changes on a book; categoryIds
+// and authors are the book's current links
+func DiffBook(book entity.Book, categoryIds []uuid.UUID, authors []BookAuthorReq, edit EditBook) map[string]FieldChange {
+	changes := map[string]FieldChange{}
+	diff := func(field string, old, new any) {
+		if old != new {
+			changes[field] = FieldChange{Old: old, New: new}
+		}
+	}
+
+	diff("title", book.Title, edit.Title)
+	diff("description", book.Description, edit.Description)
+	diff("introduction", book.Introduction, edit.Introduction)
+	diff("image", book.Image, edit.Image)
+	diff("author", book.Author, edit.Author)
+	diff("release_date", dateOnly(book.ReleaseDate), dateOnly(edit.ReleaseDate))
+	diff("price", book.Price, edit.Price)
+
+	if edit.PublisherId != nil && *edit.PublisherId != book.PublisherId {
+		changes["publisher_id"] = FieldChange{Old: nullableId(book.PublisherId), New: nullableId(*edit.PublisherId)}
+	}
+
+	// categories are a set, credits are ordered
+	if edit.CategoryIds != nil {
+		old, new := sortedIds(categoryIds), sortedIds(edit.CategoryIds)
+		if !reflect.DeepEqual(old, new) {
+			changes["category_ids"] = FieldChange{Old: old, New: new}
+		}
+	}
+
+	if edit.Authors != nil {
+		old, new := creditList(authors), creditList(edit.Authors)
+		if !reflect.DeepEqual(old, new) {
+			changes["authors"] = FieldChange{Old: old, New: new}
+		}
+	}
+
+	return changes
+}
+
+// RevertEdit builds the edit that puts back the old values of a revision
+func RevertEdit(bookId uuid.UUID, changes map[string]FieldChange) EditBook {
+	edit := EditBook{Id: bookId}
+	for field, change := range changes {
+		switch field {
+		case "title":
+			edit.Title, _ = change.Old.(string)
+		case "description":
+			edit.Description, _ = change.Old.(string)
+		case "introduction":
+			edit.Introduction, _ = change.Old.(string)
+		case "image":
+			edit.Image, _ = change.Old.(string)
+		case "author":
+			edit.Author, _ = change.Old.(string)
+		case "release_date":
+			edit.ReleaseDate, _ = change.Old.(string)
+		case "price":
+			edit.Price, _ = change.Old.(float64)
+		case "publisher_id":
+			publisherId := uuid.Nil
+			if old, ok := change.Old.(string); ok {
+				publisherId, _ = uuid.Parse(old)
+			}
+			edit.PublisherId = &publisherId
+		case "category_ids":
+			edit.CategoryIds = []uuid.UUID{}
+			decodeChange(change.Old, &edit.CategoryIds)
+		case "authors":
+			edit.Authors = []BookAuthorReq{}
+			decodeChange(change.Old, &edit.Authors)
+		}
+	}
+	return edit
+}
+
+// decodeChange turns a value read back from the changes json into its type
+func decodeChange(value any, target any) {
+	data, err := json.Marshal(value)
+	if err == nil {
+		json.Unmarshal(data, target)
+	}
+}
+
+func sortedIds(ids []uuid.UUID) []string {
+	strs := make([]string, 0, len(ids))
+	seen := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			strs = append(strs, id.String())
+		}
+	}
+	sort.Strings(strs)
+	return strs
+}
+
+// creditList fills in the default role so an unchanged list compares equal
+func creditList(authors []BookAuthorReq) []BookAuthorReq {
+	credits := make([]BookAuthorReq, len(authors))
+	for i, author := range authors {
+		credits[i] = author
+		if credits[i].Role == "" {
+			credits[i].Role = entity.RoleAuthor
+		}
+	}
+	return credits
+}
+
+func dateOnly(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
+}
+
+func nullableId(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
+
+// CreateBundle lists the books in display order; bundles are ebook only
+type CreateBundle struct {
+	Name        string      `json:"name" validate:"required,lte=255"`
+	Description string      `json:"description"`
+	Price       float64     `json:"price" validate:"required,gt=0"`
+	BookIds     []uuid.UUID `json:"book_ids" validate:"min=2,unique,dive,required"`
+}
+
+type EditBundle struct {
+	Id          uuid.UUID `json:"id" validate:"required"`
+	Name        string    `json:"name" validate:"omitempty,lte=255"`
+	Description string    `json:"description"`
+	Price       float64   `json:"price" validate:"omitempty,gt=0"`
+}
+
+// BundleResponse compares the bundle price with buying every ebook on its own
+// at today's prices. Available is false once any of the books is trashed.
+type BundleResponse struct {
+	entity.Bundle
+	Books     []BookResponse `json:"books"`
+	ListTotal float64        `json:"list_total"`
+	Savings   float64        `json:"savings"`
+	Available bool           `json:"available"`
+}
+
+type BundleBookRow struct {
+	entity.Book
+	BundleId uuid.UUID `db:"bundle_id"`
+}
+
+type CartBundle struct {
+	BundleId  uuid.UUID  `json:"bundle_id"`
+	Name      string     `json:"name"`
+	Price     float64    `json:"price"`
+	ListTotal float64    `json:"list_total"`
+	Savings   float64    `json:"savings"`
+	Items     []CartItem `json:"items"`
+}
@@ -1,6 +1,9 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
 
 type CartParam struct {
 	Id     uuid.UUID `json:"id" validate:"uuid"`
@@ -8,11 +11,27 @@ type CartParam struct {
 }
 
 type AddToCart struct {
-	BookId uuid.UUID `json:"book_id" validate:"required,uuid"`
-	Amount int       `json:"amount" validate:"required,min=1"`
+	BookId   uuid.UUID `json:"book_id" validate:"required,uuid"`
+	FormatId uuid.UUID `json:"format_id"`
+	Amount   int       `json:"amount" validate:"required,min=1"`
 }
 
 type EditCart struct {
 	CartId uuid.UUID `json:"cart_id" validate:"required,uuid"`
 	Amount int       `json:"amount" validate:"required"`
 }
+
+// CartItem prices an open cart at the format's current effective price
+type CartItem struct {
+	entity.Cart
+	Price    FormatPrice `json:"price"`
+	Subtotal float64     `json:"subtotal"`
+}
+
+// CartResponse keeps bundles apart from the loose items; a bundle's items
+// are priced at their share of the bundle price
+type CartResponse struct {
+	Items   []CartItem   `json:"items"`
+	Bundles []CartBundle `json:"bundles"`
+	Total   float64      `json:"total"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "github.com/google/uuid"
+
+type CategoryResponse struct {
+	Id        uuid.UUID          `json:"id" db:"id"`
+	ParentId  uuid.UUID          `json:"parent_id" db:"parent_id"`
+	Name      string             `json:"name" db:"name"`
+	BookCount int                `json:"book_count" db:"book_count"`
+	Children  []CategoryResponse `json:"children"`
+}
+
+type CreateCategory struct {
+	Name     string    `json:"name" validate:"required,lte=64"`
+	ParentId uuid.UUID `json:"parent_id"`
+}
+
+type EditCategory struct {
+	Id       uuid.UUID  `json:"id" validate:"required"`
+	Name     string     `json:"name" validate:"omitempty,lte=64"`
+	ParentId *uuid.UUID `json:"parent_id"`
+}
+
+// nests a flat list of categories under their parents, roots are those without one
+func BuildCategoryTree(categories []CategoryResponse) []CategoryResponse {
+	children := make(map[uuid.UUID][]CategoryResponse)
+	for _, category := range categories {
+		children[category.ParentId] = append(children[category.ParentId], category)
+	}
+
+	var attach func(parentId uuid.UUID) []CategoryResponse
+	attach = func(parentId uuid.UUID) []CategoryResponse {
+		nodes := children[parentId]
+		for i := range nodes {
+			nodes[i].Children = attach(nodes[i].Id)
+		}
+
+		if nodes == nil {
+			return []CategoryResponse{}
+		}
+		return nodes
+	}
+
+	return attach(uuid.Nil)
+}
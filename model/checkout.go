@@ -1,7 +1,78 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+	"github.com/midtrans/midtrans-go/snap"
+)
 
 type CheckoutRequest struct {
-	CartsId []uuid.UUID `json:"carts_id" validate:"required"`
+	CartsId  []uuid.UUID      `json:"carts_id" validate:"required"`
+	Shipping *ShippingAddress `json:"shipping" validate:"omitempty"`
+	Code     string           `json:"code" validate:"omitempty,max=64"`
+}
+
+// ShippingAddress is required once a checkout holds a physical format
+type ShippingAddress struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Phone   string `json:"phone" validate:"required,max=32"`
+	Address string `json:"address" validate:"required,max=1000"`
+}
+
+// CheckoutCart resolves the purchased book even after it has been trashed
+type CheckoutCart struct {
+	entity.Cart
+	BookTitle     string     `json:"book_title" db:"book_title"`
+	BookImage     string     `json:"book_image" db:"book_image"`
+	BookPrice     float64    `json:"book_price" db:"book_price"`
+	Format        string     `json:"format" db:"format"`
+	BundleName    string     `json:"bundle_name,omitempty" db:"bundle_name"`
+	FormatPrice   float64    `json:"format_price" db:"format_price"`
+	BookDeletedAt *time.Time `json:"book_deleted_at,omitempty" db:"book_deleted_at"`
+}
+
+// CheckoutLine is the priced breakdown of one cart at checkout
+type CheckoutLine struct {
+	CartId     uuid.UUID `json:"cart_id"`
+	BookId     uuid.UUID `json:"book_id"`
+	FormatId   uuid.UUID `json:"format_id"`
+	Title      string    `json:"title"`
+	Format     string    `json:"format"`
+	Amount     int       `json:"amount"`
+	ListPrice  float64   `json:"list_price"`
+	UnitPrice  float64   `json:"unit_price"`
+	Subtotal   float64   `json:"subtotal"`
+	Discount   float64   `json:"discount"`
+	Total      float64   `json:"total"`
+	Promotions []string  `json:"promotions"`
+	PreOrder   bool      `json:"pre_order"`
+	BundleId   uuid.UUID `json:"bundle_id"`
+}
+
+type AppliedPromotion struct {
+	Id       uuid.UUID `json:"id"`
+	Code     *string   `json:"code"`
+	Name     string    `json:"name"`
+	Discount float64   `json:"discount"`
+}
+
+type CheckoutSummary struct {
+	CheckoutId  uuid.UUID          `json:"checkout_id"`
+	Lines       []CheckoutLine     `json:"lines"`
+	Promotions  []AppliedPromotion `json:"promotions"`
+	Subtotal    float64            `json:"subtotal"`
+	Discount    float64            `json:"discount"`
+	ShippingFee float64            `json:"shipping_fee"`
+	Total       float64            `json:"total"`
+
+	// BundleSavings is already reflected in the bundle lines' unit prices
+	BundleSavings float64 `json:"bundle_savings"`
+}
+
+// CheckoutResponse keeps the snap token fields at the top level
+type CheckoutResponse struct {
+	*snap.Response
+	Checkout *CheckoutSummary `json:"checkout"`
 }
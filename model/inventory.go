@@ -0,0 +1,31 @@
+package model
+
+import "github.com/google/uuid"
+
+type InventoryReq struct {
+	Page         int  `json:"page" validate:"required,min=1"`
+	PageSize     int  `json:"page_size" validate:"required,min=1"`
+	LowStockOnly bool `json:"low_stock_only"`
+}
+
+type AdjustStock struct {
+	Delta  int    `json:"delta" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type SetLowStockThreshold struct {
+	LowStockThreshold *int `json:"low_stock_threshold" validate:"required,min=0"`
+}
+
+type InventoryResponse struct {
+	FormatId          uuid.UUID `json:"format_id" db:"format_id"`
+	BookId            uuid.UUID `json:"book_id" db:"book_id"`
+	Title             string    `json:"title" db:"title"`
+	Format            string    `json:"format" db:"format"`
+	Sku               string    `json:"sku" db:"sku"`
+	OnHand            int       `json:"on_hand" db:"on_hand"`
+	Reserved          int       `json:"reserved" db:"reserved"`
+	Available         int       `json:"available" db:"available"`
+	LowStockThreshold int       `json:"low_stock_threshold" db:"low_stock_threshold"`
+	LowStock          bool      `json:"low_stock" db:"low_stock"`
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"slices"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+)
+
+var (
+	NotificationCategories = []string{
+		entity.NotificationSecurity,
+		entity.NotificationOrderUpdates,
+		entity.NotificationMarketing,
+		entity.NotificationNewReleases,
+		entity.NotificationPriceDrops,
+	}
+	NotificationChannels = []string{entity.ChannelEmail, entity.ChannelInApp}
+)
+
+type NotificationReq struct {
+	Page     int `json:"page" validate:"required,min=1"`
+	PageSize int `json:"page_size" validate:"required,min=1"`
+}
+
+type NotificationPreference struct {
+	Category  string `json:"category" validate:"required"`
+	Channel   string `json:"channel" validate:"required"`
+	Enabled   bool   `json:"enabled"`
+	Mandatory bool   `json:"mandatory"`
+}
+
+type UpdatePreferences struct {
+	Preferences []NotificationPreference `json:"preferences" validate:"required,dive"`
+}
+
+// security emails can not be turned off, everything else can
+func IsMandatory(category, channel string) bool {
+	return category == entity.NotificationSecurity && channel == entity.ChannelEmail
+}
+
+func IsValidPreference(category, channel string) bool {
+	return slices.Contains(NotificationCategories, category) && slices.Contains(NotificationChannels, channel)
+}
+
+// marketing is opt-in, every other category is on until the user turns it off
+func defaultPreference(category string) bool {
+	return category != entity.NotificationMarketing
+}
+
+func MergePreferences(stored []entity.NotificationPreference) []NotificationPreference {
+	enabled := make(map[string]bool)
+	for _, pref := range stored {
+		enabled[pref.Category+"/"+pref.Channel] = pref.Enabled
+	}
+
+	preferences := make([]NotificationPreference, 0, len(NotificationCategories)*len(NotificationChannels))
+	for _, category := range NotificationCategories {
+		for _, channel := range NotificationChannels {
+			value, ok := enabled[category+"/"+channel]
+			if !ok {
+				value = defaultPreference(category)
+			}
+
+			mandatory := IsMandatory(category, channel)
+			preferences = append(preferences, NotificationPreference{
+				Category:  category,
+				Channel:   channel,
+				Enabled:   value || mandatory,
+				Mandatory: mandatory,
+			})
+		}
+	}
+
+	return preferences
+}
+
+func IsEnabled(preferences []NotificationPreference, category, channel string) bool {
+	for _, pref := range preferences {
+		if pref.Category == category && pref.Channel == channel {
+			return pref.Enabled
+		}
+	}
+	return false
+}
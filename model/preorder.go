@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
+
+type CancelPreorder struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type PreorderResponse struct {
+	entity.Preorder
+	Title     string    `json:"title" db:"title"`
+	Format    string    `json:"format" db:"format"`
+	Amount    int       `json:"amount" db:"amount"`
+	LineTotal float64   `json:"line_total" db:"line_total"`
+	PaymentId uuid.UUID `json:"payment_id" db:"payment_id"`
+	Paid      bool      `json:"paid" db:"paid"`
+}
+
+// PreorderNotice is one pending email for a paid pre-order
+type PreorderNotice struct {
+	PreorderId  uuid.UUID `db:"id"`
+	UserId      uuid.UUID `db:"user_id"`
+	BookId      uuid.UUID `db:"book_id"`
+	Title       string    `db:"title"`
+	ReleaseDate string    `db:"release_date"`
+}
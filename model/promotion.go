@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
+
+// CreatePromotion leaves Code empty for automatic promotions
+type CreatePromotion struct {
+	Code           string     `json:"code" validate:"omitempty,max=64"`
+	Name           string     `json:"name" validate:"required,max=255"`
+	Kind           string     `json:"kind" validate:"required,oneof=percentage fixed"`
+	Value          float64    `json:"value" validate:"required,gt=0"`
+	Scope          string     `json:"scope" validate:"required,oneof=all book category"`
+	BookId         uuid.UUID  `json:"book_id"`
+	CategoryId     uuid.UUID  `json:"category_id"`
+	MinSpend       float64    `json:"min_spend" validate:"min=0"`
+	MinQuantity    int        `json:"min_quantity" validate:"min=0"`
+	MaxUses        *int       `json:"max_uses" validate:"omitempty,min=1"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user" validate:"omitempty,min=1"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+}
+
+type PromotionReq struct {
+	Page       int  `json:"page" validate:"required,min=1"`
+	PageSize   int  `json:"page_size" validate:"required,min=1"`
+	ActiveOnly bool `json:"active_only"`
+}
+
+type PromotionResponse struct {
+	entity.Promotion
+	Uses int `json:"uses" db:"uses"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
+
+type CreatePublisher struct {
+	Name        string `json:"name" validate:"required,lte=255"`
+	Description string `json:"description"`
+	Logo        string `json:"logo" validate:"omitempty,url"`
+}
+
+type EditPublisher struct {
+	Id          uuid.UUID `json:"id" validate:"required"`
+	Name        string    `json:"name" validate:"omitempty,lte=255"`
+	Description string    `json:"description"`
+	Logo        string    `json:"logo" validate:"omitempty,url"`
+}
+
+type PublisherResponse struct {
+	entity.Publisher
+	Books []BookResponse `json:"books"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+
+const (
+	Window7Days   = "7d"
+	Window30Days  = "30d"
+	WindowAllTime = "all"
+)
+
+type BookRanking struct {
+	entity.Book
+	Position int     `db:"position"`
+	Score    float64 `db:"score"`
+}
+
+type RankedBookResponse struct {
+	BookResponse
+	Position int     `json:"position"`
+	Score    float64 `json:"score"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
+
+type CreateReadingList struct {
+	Name        string `json:"name" validate:"required,lte=255"`
+	Description string `json:"description"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+type EditReadingList struct {
+	Id          uuid.UUID `json:"id" validate:"required"`
+	Name        string    `json:"name" validate:"omitempty,lte=255"`
+	Description string    `json:"description"`
+	IsPublic    *bool     `json:"is_public"`
+}
+
+type AddReadingListBook struct {
+	BookId uuid.UUID `json:"book_id" validate:"required"`
+}
+
+// ReadingListSummary is a list without its books, as shown on a profile
+type ReadingListSummary struct {
+	entity.ReadingList
+	BookCount int `json:"book_count" db:"book_count"`
+}
+
+type ReadingListBookRow struct {
+	entity.Book
+	AddedAt time.Time `db:"added_at"`
+}
+
+type ReadingListEntry struct {
+	Book    BookResponse `json:"book"`
+	AddedAt time.Time    `json:"added_at"`
+}
+
+type ReadingListResponse struct {
+	entity.ReadingList
+	Books []ReadingListEntry `json:"books"`
+}
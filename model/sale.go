@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateSale targets the book's ebook when FormatId is empty
+type CreateSale struct {
+	FormatId  uuid.UUID `json:"format_id"`
+	SalePrice float64   `json:"sale_price" validate:"required,gt=0"`
+	StartsAt  time.Time `json:"starts_at" validate:"required"`
+	EndsAt    time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	Quantity  *int      `json:"quantity" validate:"omitempty,min=1"`
+}
+
+type PriceHistoryReq struct {
+	FormatId uuid.UUID `json:"format_id"`
+	Days     int       `json:"days" validate:"min=1,max=365"`
+}
+
+// FormatPrice resolves what a format sells for right now
+type FormatPrice struct {
+	FormatId       uuid.UUID  `json:"format_id" db:"format_id"`
+	BookId         uuid.UUID  `json:"book_id" db:"book_id"`
+	Format         string     `json:"format" db:"format"`
+	ListPrice      float64    `json:"list_price" db:"list_price"`
+	EffectivePrice float64    `json:"effective_price" db:"effective_price"`
+	SaleId         uuid.UUID  `json:"sale_id" db:"sale_id"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at,omitempty" db:"sale_ends_at"`
+	SaleRemaining  *int       `json:"sale_remaining,omitempty" db:"sale_remaining"`
+	LowestPrice30d float64    `json:"lowest_price_30d" db:"lowest_price_30d"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "github.com/google/uuid"
+
+type SetBookSample struct {
+	Mode  string `json:"mode" validate:"required,oneof=pages chapters"`
+	Count int    `json:"count" validate:"required,min=1,max=100"`
+}
+
+type SampleConversion struct {
+	BookId      uuid.UUID `json:"book_id" db:"book_id"`
+	Title       string    `json:"title" db:"title"`
+	Previewers  int       `json:"previewers" db:"previewers"`
+	Conversions int       `json:"conversions" db:"conversions"`
+	Rate        float64   `json:"rate" db:"rate"`
+}
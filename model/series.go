@@ -0,0 +1,98 @@
+package model
+
+import (
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
+
+type CreateSeries struct {
+	Name        string `json:"name" validate:"required,lte=255"`
+	Description string `json:"description"`
+}
+
+type EditSeries struct {
+	Id          uuid.UUID `json:"id" validate:"required"`
+	Name        string    `json:"name" validate:"omitempty,lte=255"`
+	Description string    `json:"description"`
+}
+
+// SetSeriesBooks replaces the membership; the list order is the reading order
+type SetSeriesBooks struct {
+	Books []SeriesEntry `json:"books" validate:"dive"`
+}
+
+type SeriesEntry struct {
+	BookId uuid.UUID `json:"book_id" validate:"required"`
+	Label  string    `json:"label" validate:"max=64"`
+}
+
+type SeriesVolume struct {
+	Position int          `json:"position"`
+	Label    string       `json:"label"`
+	Book     BookResponse `json:"book"`
+}
+
+type SeriesResponse struct {
+	entity.Series
+	Books []SeriesVolume `json:"books"`
+}
+
+type SeriesBookRow struct {
+	entity.Book
+	Position int    `db:"position"`
+	Label    string `db:"label"`
+}
+
+type SeriesNeighbour struct {
+	BookId uuid.UUID `json:"book_id"`
+	Title  string    `json:"title"`
+	Label  string    `json:"label"`
+}
+
+// SeriesLink places a book within one of its series
+type SeriesLink struct {
+	SeriesId uuid.UUID        `json:"series_id"`
+	Name     string           `json:"name"`
+	Position int              `json:"position"`
+	Label    string           `json:"label"`
+	Previous *SeriesNeighbour `json:"previous"`
+	Next     *SeriesNeighbour `json:"next"`
+}
+
+type SeriesLinkRow struct {
+	SeriesId      uuid.UUID `db:"series_id"`
+	Name          string    `db:"name"`
+	Position      int       `db:"position"`
+	Label         string    `db:"label"`
+	PreviousId    uuid.UUID `db:"previous_id"`
+	PreviousTitle string    `db:"previous_title"`
+	PreviousLabel string    `db:"previous_label"`
+	NextId        uuid.UUID `db:"next_id"`
+	NextTitle     string    `db:"next_title"`
+	NextLabel     string    `db:"next_label"`
+}
+
+func SeriesLinkRowToSeriesLink(row SeriesLinkRow) SeriesLink {
+	link := SeriesLink{
+		SeriesId: row.SeriesId,
+		Name:     row.Name,
+		Position: row.Position,
+		Label:    row.Label,
+	}
+
+	if row.PreviousId != uuid.Nil {
+		link.Previous = &SeriesNeighbour{BookId: row.PreviousId, Title: row.PreviousTitle, Label: row.PreviousLabel}
+	}
+	if row.NextId != uuid.Nil {
+		link.Next = &SeriesNeighbour{BookId: row.NextId, Title: row.NextTitle, Label: row.NextLabel}
+	}
+
+	return link
+}
+
+// SeriesCartResult lists the volumes added and those skipped as already
+// owned, already in the cart or not sold as an ebook
+type SeriesCartResult struct {
+	Added   []uuid.UUID `json:"added"`
+	Skipped []uuid.UUID `json:"skipped"`
+}
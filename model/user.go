@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
 	"github.com/google/uuid"
 )
@@ -10,12 +12,39 @@ type ProfilesReq struct {
 	PageSize int `json:"page_size" validate:"required,min=1"`
 }
 
+type PublicProfileReq struct {
+	Page     int `json:"page" validate:"required,min=1"`
+	PageSize int `json:"page_size" validate:"required,min=1"`
+}
+
 type Profile struct {
-	Id             uuid.UUID `json:"id" db:"id"`
-	Username       string    `json:"username" db:"username"`
-	Email          string    `json:"email" db:"email"`
-	RoleId         int       `json:"roleId" db:"role_id"`
-	ProfilePicture string    `json:"profilePicture" db:"profile_picture"`
+	Id             uuid.UUID  `json:"id" db:"id"`
+	Username       string     `json:"username" db:"username"`
+	Email          string     `json:"email" db:"email"`
+	RoleId         int        `json:"roleId" db:"role_id"`
+	ProfilePicture string     `json:"profilePicture" db:"profile_picture"`
+	IsPrivate      bool       `json:"isPrivate" db:"is_private"`
+	MemberSince    *time.Time `json:"memberSince,omitempty" db:"created_at"`
+}
+
+type PublicProfile struct {
+	Id             uuid.UUID            `json:"id"`
+	Username       string               `json:"username"`
+	ProfilePicture string               `json:"profilePicture"`
+	IsPrivate      bool                 `json:"isPrivate"`
+	MemberSince    *time.Time           `json:"memberSince,omitempty"`
+	BooksReviewed  int                  `json:"booksReviewed"`
+	Reviews        []UserReview         `json:"reviews"`
+	ReadingLists   []ReadingListSummary `json:"readingLists"`
+}
+
+type UserReview struct {
+	Id        uuid.UUID `json:"id" db:"id"`
+	BookId    uuid.UUID `json:"book_id" db:"book_id"`
+	BookTitle string    `json:"book_title" db:"book_title"`
+	Comment   string    `json:"comment" db:"comment"`
+	Rating    int       `json:"rating" db:"rating"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
 type RoleUpdate struct {
@@ -27,6 +56,7 @@ type EditProfile struct {
 	Id             uuid.UUID `json:"id" db:"id" validate:"required,uuid"`
 	Username       string    `json:"username" db:"username" validate:"omitempty,lte=32"`
 	ProfilePicture string    `json:"profilePicture" db:"profile_picture" validate:"omitempty,url"`
+	IsPrivate      *bool     `json:"isPrivate" db:"is_private"`
 }
 
 func UserToProfile(user entity.User) Profile {
@@ -36,5 +66,31 @@ func UserToProfile(user entity.User) Profile {
 		Email:          user.Email,
 		RoleId:         user.RoleId,
 		ProfilePicture: user.ProfilePicture,
+		IsPrivate:      user.IsPrivate,
+		MemberSince:    user.CreatedAt,
+	}
+}
+
+// private profiles only expose what is already visible next to their comments
+func UserToPublicProfile(user entity.User, booksReviewed int, reviews []UserReview, lists []ReadingListSummary) PublicProfile {
+	if user.IsPrivate {
+		return PublicProfile{
+			Id:             user.Id,
+			Username:       user.Username,
+			ProfilePicture: user.ProfilePicture,
+			IsPrivate:      true,
+			Reviews:        []UserReview{},
+			ReadingLists:   []ReadingListSummary{},
+		}
+	}
+
+	return PublicProfile{
+		Id:             user.Id,
+		Username:       user.Username,
+		ProfilePicture: user.ProfilePicture,
+		MemberSince:    user.CreatedAt,
+		BooksReviewed:  booksReviewed,
+		Reviews:        reviews,
+		ReadingLists:   lists,
 	}
 }
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
+	"github.com/google/uuid"
+)
+
+type AddToWishlist struct {
+	BookId uuid.UUID `json:"book_id" validate:"required"`
+}
+
+type WishlistItem struct {
+	entity.Book
+	AddedAt time.Time `db:"added_at"`
+}
+
+type WishlistResponse struct {
+	Book    BookResponse `json:"book"`
+	AddedAt time.Time    `json:"added_at"`
+}
+
+// WishlistAlert is one pending notification for a wishlisted book
+type WishlistAlert struct {
+	UserId     uuid.UUID `db:"user_id"`
+	BookId     uuid.UUID `db:"book_id"`
+	Title      string    `db:"title"`
+	AlertPrice float64   `db:"alert_price"`
+	Price      float64   `db:"price"`
+}
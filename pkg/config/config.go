@@ -9,11 +9,13 @@ import (
 	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/repository"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/service"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/bcrypt"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/ebook"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/jwt"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/logger"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/middleware"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/midtrans"
 	monitoring "github.com/AgungAryansyah/filkompedia-be-insecure/pkg/prometheus"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/scheduler"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/smtp"
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/supabase"
 	val "github.com/AgungAryansyah/filkompedia-be-insecure/pkg/validator"
@@ -44,12 +46,17 @@ func StartUp(config *Config) {
 	promMetrics := monitoring.Start()
 	logrus := logger.SetupLogger()
 	supabase := supabase.New()
+	ebook := ebook.New()
 
 	validator := validator.New()
 	val.RegisterValidator(validator)
 
 	repository := repository.NewRepository(config.DB, config.Redis)
-	service := service.NewService(repository, bcrypt, jwt, smtp, midtrans, supabase)
+	service := service.NewService(repository, bcrypt, jwt, smtp, midtrans, supabase, ebook)
+
+	scheduler := scheduler.New(logrus)
+	registerJobs(scheduler, service)
+	scheduler.Start()
 
 	middleware := middleware.Init(jwt, service, promMetrics, logrus)
 
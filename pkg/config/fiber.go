@@ -1,18 +1,31 @@
 package config
 
 import (
+	"regexp"
+
 	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
+// book files are capped at 100MB by the book_file_size validator
+const bookFileBodyLimit = 101 * 1024 * 1024
+
+var bookFileRoute = regexp.MustCompile(`^/api/v1/books/[^/]+/file$`)
+
 func StartFiber() *fiber.App {
 	app := fiber.New(
 		fiber.Config{
 			ErrorHandler: CustomErrorHandler,
+			// the server has to accept book files; limitBody holds every
+			// other route to the default, so bodies are streamed until checked
+			BodyLimit:         bookFileBodyLimit,
+			StreamRequestBody: true,
 		},
 	)
 
+	app.Use(limitBody)
+
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:5173, https://filkompedia.yogarn.my.id, https://api.sandbox.midtrans.com, http://10.34.100.139:5173",
 		AllowCredentials: true,
@@ -24,6 +37,21 @@ func StartFiber() *fiber.App {
 	return app
 }
 
+// limitBody keeps fiber's default body limit on every route but the book file upload
+func limitBody(ctx *fiber.Ctx) error {
+	if ctx.Method() == fiber.MethodPost && bookFileRoute.MatchString(ctx.Path()) {
+		return ctx.Next()
+	}
+
+	// chunked bodies have no length to check up front
+	length := ctx.Request().Header.ContentLength()
+	if length > fiber.DefaultBodyLimit || length == -1 {
+		return fiber.ErrRequestEntityTooLarge
+	}
+
+	return ctx.Next()
+}
+
 func CustomErrorHandler(ctx *fiber.Ctx, err error) error {
 	code, message := response.GetErrorInfo(err)
 
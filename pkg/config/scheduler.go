@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/AgungAryansyah/filkompedia-be-insecure/internal/service"
+	"github.com/AgungAryansyah/filkompedia-be-insecure/pkg/scheduler"
+)
+
+// reads a duration in minutes from env, falling back when it is unset or invalid
+func envMinutes(key string, fallback time.Duration) time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || minutes < 1 {
+		return fallback
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+func registerJobs(scheduler scheduler.IScheduler, service *service.Service) {
+	uploadGracePeriod := envMinutes("UPLOAD_GRACE_PERIOD", 24*time.Hour)
+	scheduler.Every("sweep uploads", envMinutes("UPLOAD_SWEEP_INTERVAL", time.Hour), func() error {
+		return service.UploadService.SweepUploads(uploadGracePeriod)
+	})
+
+	scheduler.Every("refresh recommendations", envMinutes("RECOMMENDATION_REFRESH_INTERVAL", 6*time.Hour), func() error {
+		return service.RecommendationService.RefreshSimilarities()
+	})
+
+	scheduler.Every("refresh rankings", envMinutes("RANKING_REFRESH_INTERVAL", 30*time.Minute), func() error {
+		return service.RankingService.RefreshRankings()
+	})
+
+	scheduler.Every("wishlist alerts", envMinutes("WISHLIST_ALERT_INTERVAL", 15*time.Minute), func() error {
+		return service.WishlistService.SendAlerts()
+	})
+
+	scheduler.Every("process preorders", envMinutes("PREORDER_INTERVAL", time.Hour), func() error {
+		return service.PreorderService.ProcessPreorders()
+	})
+
+	scheduler.Every("record prices", envMinutes("PRICE_HISTORY_INTERVAL", time.Minute), func() error {
+		return service.SaleService.RecordPrices()
+	})
+
+	reservationTtl := envMinutes("STOCK_RESERVATION_TTL", 24*time.Hour)
+	scheduler.Every("release stale reservations", envMinutes("STOCK_RESERVATION_SWEEP_INTERVAL", 30*time.Minute), func() error {
+		return service.InventoryService.ReleaseStaleReservations(reservationTtl)
+	})
+}
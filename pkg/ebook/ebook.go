@@ -0,0 +1,36 @@
+package ebook
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrUnsupported = errors.New("ebook: unsupported file")
+
+// Stamp identifies the purchaser a delivered copy belongs to
+type Stamp struct {
+	Name    string
+	Email   string
+	OrderId string
+	Date    time.Time
+}
+
+func (s Stamp) Line() string {
+	return fmt.Sprintf("Licensed to %s <%s> - order %s - %s", s.Name, s.Email, s.OrderId, s.Date.Format("2006-01-02"))
+}
+
+type IEbook interface {
+	WatermarkPdf(data []byte, stamp Stamp) ([]byte, error)
+	WatermarkEpub(data []byte, stamp Stamp) ([]byte, error)
+	ExtractPdf(data []byte) (*Metadata, error)
+	ExtractEpub(data []byte) (*Metadata, error)
+	SamplePdf(data []byte, pages int) ([]byte, error)
+	SampleEpub(data []byte, chapters int) ([]byte, error)
+}
+
+type ebook struct{}
+
+func New() IEbook {
+	return &ebook{}
+}
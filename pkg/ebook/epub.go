@@ -0,0 +1,150 @@
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const colophonId = "filkompedia-license"
+
+var rootfilePattern = regexp.MustCompile(`full-path="([^"]+)"`)
+
+// Epub copies the archive entry by entry, adding a colophon page at the end
+// of the spine and the licence details to the package metadata
+func (e *ebook) WatermarkEpub(data []byte, stamp Stamp) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	opfPath, err := findRootfile(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	writer := zip.NewWriter(&out)
+
+	for _, file := range reader.File {
+		if file.Name != opfPath {
+			if err := writer.Copy(file); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		opf, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		stamped, err := stampOpf(string(opf), stamp)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := writeZipFile(writer, file.Name, []byte(stamped)); err != nil {
+			return nil, err
+		}
+	}
+
+	colophonPath := path.Join(path.Dir(opfPath), colophonId+".xhtml")
+	if err := writeZipFile(writer, colophonPath, []byte(colophon(stamp))); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func findRootfile(reader *zip.Reader) (string, error) {
+	for _, file := range reader.File {
+		if file.Name != "META-INF/container.xml" {
+			continue
+		}
+
+		container, err := readZipFile(file)
+		if err != nil {
+			return "", err
+		}
+
+		match := rootfilePattern.FindSubmatch(container)
+		if match == nil {
+			break
+		}
+
+		return string(match[1]), nil
+	}
+
+	return "", ErrUnsupported
+}
+
+func stampOpf(opf string, stamp Stamp) (string, error) {
+	if !strings.Contains(opf, "</metadata>") || !strings.Contains(opf, "</manifest>") || !strings.Contains(opf, "</spine>") {
+		return "", ErrUnsupported
+	}
+
+	metadata := fmt.Sprintf(
+		`<dc:rights>%s</dc:rights><meta name="filkompedia:licensee" content="%s"/><meta name="filkompedia:order" content="%s"/>`,
+		html.EscapeString(stamp.Line()),
+		html.EscapeString(stamp.Name+" <"+stamp.Email+">"),
+		html.EscapeString(stamp.OrderId),
+	)
+	if !strings.Contains(opf, "xmlns:dc=") {
+		metadata = strings.Replace(metadata, "<dc:rights>", `<dc:rights xmlns:dc="http://purl.org/dc/elements/1.1/">`, 1)
+	}
+
+	opf = strings.Replace(opf, "</metadata>", metadata+"</metadata>", 1)
+	opf = strings.Replace(opf, "</manifest>", `<item id="`+colophonId+`" href="`+colophonId+`.xhtml" media-type="application/xhtml+xml"/></manifest>`, 1)
+	opf = strings.Replace(opf, "</spine>", `<itemref idref="`+colophonId+`"/></spine>`, 1)
+	return opf, nil
+}
+
+func colophon(stamp Stamp) string {
+	return `<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>Licence</title></head>
+<body>
+<section>
+<h1>Licence</h1>
+<p>This copy was licensed to ` + html.EscapeString(stamp.Name) + ` (` + html.EscapeString(stamp.Email) + `).</p>
+<p>Order ` + html.EscapeString(stamp.OrderId) + `, delivered ` + stamp.Date.Format("2 January 2006") + `.</p>
+<p>It is for personal use only and must not be redistributed.</p>
+</section>
+</body>
+</html>
+`
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	return io.ReadAll(src)
+}
+
+func writeZipFile(writer *zip.Writer, name string, data []byte) error {
+	dst, err := writer.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = dst.Write(data)
+	return err
+}
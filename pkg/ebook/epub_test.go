@@ -0,0 +1,81 @@
+package ebook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStampOpf(t *testing.T) {
+	stamp := Stamp{Name: "Budi & <Co>", Email: "budi@example.com", OrderId: "order-1", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	opf := `<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		`<metadata><dc:title>A Book</dc:title></metadata>` +
+		`<manifest><item id="c1" href="c1.xhtml" media-type="application/xhtml+xml"/></manifest>` +
+		`<spine><itemref idref="c1"/></spine></package>`
+
+	tests := []struct {
+		name    string
+		opf     string
+		err     error
+		want    []string
+		missing []string
+	}{
+		{
+			name: "adds the licence and the colophon",
+			opf:  opf,
+			want: []string{
+				`<dc:rights>Licensed to Budi &amp; &lt;Co&gt; &lt;budi@example.com&gt; - order order-1 - 2024-05-01</dc:rights>`,
+				`<meta name="filkompedia:order" content="order-1"/></metadata>`,
+				`<item id="filkompedia-license" href="filkompedia-license.xhtml" media-type="application/xhtml+xml"/></manifest>`,
+				`<itemref idref="c1"/><itemref idref="filkompedia-license"/></spine>`,
+			},
+			missing: []string{"Budi & <Co>"},
+		},
+		{
+			name: "declares the dc namespace when the package does not",
+			opf:  strings.Replace(opf, ` xmlns:dc="http://purl.org/dc/elements/1.1/"`, "", 1),
+			want: []string{`<dc:rights xmlns:dc="http://purl.org/dc/elements/1.1/">`},
+		},
+		{
+			name: "missing metadata",
+			opf:  strings.Replace(opf, "</metadata>", "", 1),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "missing manifest",
+			opf:  strings.Replace(opf, "</manifest>", "", 1),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "missing spine",
+			opf:  strings.Replace(opf, "</spine>", "", 1),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "empty",
+			opf:  "",
+			err:  ErrUnsupported,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stamped, err := stampOpf(test.opf, stamp)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("err = %v, want %v", err, test.err)
+			}
+
+			for _, marker := range test.want {
+				if !strings.Contains(stamped, marker) {
+					t.Errorf("stamped opf is missing %q:\n%s", marker, stamped)
+				}
+			}
+			for _, marker := range test.missing {
+				if strings.Contains(stamped, marker) {
+					t.Errorf("stamped opf should not contain %q", marker)
+				}
+			}
+		})
+	}
+}
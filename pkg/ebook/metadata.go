@@ -0,0 +1,316 @@
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// Metadata is what could be read from a book file; fields it lacks are empty
+type Metadata struct {
+	Title       string
+	Creators    []string
+	Publisher   string
+	Date        string
+	Description string
+	Isbn        string
+	Cover       []byte
+	CoverExt    string
+}
+
+type opfPackage struct {
+	Metadata struct {
+		Titles   []string `xml:"title"`
+		Creators []struct {
+			Name string `xml:",chardata"`
+			Role string `xml:"role,attr"`
+		} `xml:"creator"`
+		Publisher   string   `xml:"publisher"`
+		Dates       []string `xml:"date"`
+		Description string   `xml:"description"`
+		Identifiers []struct {
+			Value  string `xml:",chardata"`
+			Scheme string `xml:"scheme,attr"`
+		} `xml:"identifier"`
+		Metas []struct {
+			Name    string `xml:"name,attr"`
+			Content string `xml:"content,attr"`
+		} `xml:"meta"`
+	} `xml:"metadata"`
+	Items []struct {
+		Id         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+}
+
+var (
+	tagPattern     = regexp.MustCompile(`<[^>]*>`)
+	isbnPattern    = regexp.MustCompile(`^(97[89])?\d{9}[\dX]$`)
+	pdfDatePattern = regexp.MustCompile(`^D:(\d{4})(\d{2})?(\d{2})?`)
+)
+
+func (e *ebook) ExtractEpub(data []byte) (*Metadata, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	opfPath, err := findRootfile(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	opf, err := readZipPath(reader, opfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg opfPackage
+	if err := xml.Unmarshal(opf, &pkg); err != nil {
+		return nil, ErrUnsupported
+	}
+
+	metadata := &Metadata{
+		Publisher:   strings.TrimSpace(pkg.Metadata.Publisher),
+		Description: plainText(pkg.Metadata.Description),
+	}
+
+	if len(pkg.Metadata.Titles) > 0 {
+		metadata.Title = strings.TrimSpace(pkg.Metadata.Titles[0])
+	}
+
+	for _, creator := range pkg.Metadata.Creators {
+		if creator.Role == "" || creator.Role == "aut" {
+			metadata.Creators = append(metadata.Creators, strings.TrimSpace(creator.Name))
+		}
+	}
+
+	if len(pkg.Metadata.Dates) > 0 {
+		metadata.Date = normalizeDate(pkg.Metadata.Dates[0])
+	}
+
+	for _, identifier := range pkg.Metadata.Identifiers {
+		if isbn := normalizeIsbn(identifier.Value); isbn != "" {
+			metadata.Isbn = isbn
+			break
+		}
+	}
+
+	// epub 3 flags the cover in the manifest, epub 2 points at it from a meta
+	coverId := ""
+	for _, meta := range pkg.Metadata.Metas {
+		if meta.Name == "cover" {
+			coverId = meta.Content
+		}
+	}
+
+	for _, item := range pkg.Items {
+		isCover := strings.Contains(" "+item.Properties+" ", " cover-image ") || (coverId != "" && item.Id == coverId)
+		if !isCover || !strings.HasPrefix(item.MediaType, "image/") {
+			continue
+		}
+
+		href, err := url.PathUnescape(item.Href)
+		if err != nil {
+			continue
+		}
+
+		cover, err := readZipPath(reader, path.Join(path.Dir(opfPath), href))
+		if err != nil {
+			continue
+		}
+
+		metadata.Cover = cover
+		metadata.CoverExt = path.Ext(href)
+		break
+	}
+
+	return metadata, nil
+}
+
+// ExtractPdf reads the document info dictionary. Pdfs carry no cover image,
+// and the publisher has no standard key, so both stay empty.
+func (e *ebook) ExtractPdf(data []byte) (*Metadata, error) {
+	document, err := parsePdf(data)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{}
+
+	infoRef, ok := findRef(document.trailer, "Info")
+	if !ok {
+		return metadata, nil
+	}
+
+	info := dictOf(document.objects[infoRef.num])
+	metadata.Title = pdfInfoString(info, "Title")
+	metadata.Description = pdfInfoString(info, "Subject")
+
+	for _, creator := range regexp.MustCompile(`\s*(?:;|,|&|\sand\s)\s*`).Split(pdfInfoString(info, "Author"), -1) {
+		if creator != "" {
+			metadata.Creators = append(metadata.Creators, creator)
+		}
+	}
+
+	if match := pdfDatePattern.FindStringSubmatch(pdfInfoString(info, "CreationDate")); match != nil {
+		metadata.Date = normalizeDate(match[1] + "-" + defaultPart(match[2]) + "-" + defaultPart(match[3]))
+	}
+
+	for _, word := range strings.Fields(pdfInfoString(info, "Keywords")) {
+		if isbn := normalizeIsbn(word); isbn != "" {
+			metadata.Isbn = isbn
+			break
+		}
+	}
+
+	return metadata, nil
+}
+
+func readZipPath(reader *zip.Reader, name string) ([]byte, error) {
+	for _, file := range reader.File {
+		if file.Name == name {
+			return readZipFile(file)
+		}
+	}
+	return nil, ErrUnsupported
+}
+
+// pdfInfoString decodes a literal or hex string value, handling the
+// UTF-16 encoding pdf producers use for non latin text
+func pdfInfoString(dict string, key string) string {
+	index := regexp.MustCompile(`/` + key + `\s*[(<]`).FindStringIndex(dict)
+	if index == nil {
+		return ""
+	}
+
+	rest := dict[index[1]-1:]
+	var raw []byte
+	if rest[0] == '<' {
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			return ""
+		}
+
+		digits := strings.Join(strings.Fields(rest[1:end]), "")
+		if len(digits)%2 == 1 {
+			digits += "0"
+		}
+
+		decoded, err := hex.DecodeString(digits)
+		if err != nil {
+			return ""
+		}
+		raw = decoded
+	} else {
+		raw = unescapePdfLiteral(rest)
+	}
+
+	if len(raw) >= 2 && raw[0] == 0xfe && raw[1] == 0xff {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return strings.TrimSpace(string(utf16.Decode(units)))
+	}
+
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+func unescapePdfLiteral(s string) []byte {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch next := s[i]; next {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				continue
+			default:
+				if next >= '0' && next <= '7' {
+					value := 0
+					for j := 0; j < 3 && i < len(s) && s[i] >= '0' && s[i] <= '7'; j++ {
+						value = value*8 + int(s[i]-'0')
+						i++
+					}
+					i--
+					c = byte(value)
+				} else {
+					c = next
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func normalizeIsbn(value string) string {
+	value = strings.ToUpper(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "urn:isbn:"))
+	value = strings.NewReplacer("-", "", " ", "").Replace(strings.TrimPrefix(value, "ISBN"))
+	if isbnPattern.MatchString(value) {
+		return value
+	}
+	return ""
+}
+
+// normalizeDate turns the partial dates books carry ("2004", "2004-10",
+// full timestamps) into the yyyy-mm-dd CreateBook expects
+func normalizeDate(date string) string {
+	date = strings.TrimSpace(date)
+	if len(date) >= 10 {
+		return date[:10]
+	}
+
+	parts := strings.Split(date, "-")
+	for len(parts) < 3 {
+		parts = append(parts, "01")
+	}
+	return strings.Join(parts[:3], "-")
+}
+
+func defaultPart(part string) string {
+	if part == "" {
+		return "01"
+	}
+	return part
+}
+
+func plainText(markup string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(markup, " "))), " ")
+}
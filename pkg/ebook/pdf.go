@@ -0,0 +1,549 @@
+package ebook
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The stamp goes into a full save, not an incremental update, so it can not
+// be dropped by cutting the file back to an earlier %%EOF. Every page draws
+// the licence line from its content stream and the document info carries the
+// licensee; objects the document no longer reaches are left out.
+
+// stampName is the resource name of the stamp form on each page
+const stampName = "FilkompediaStamp"
+
+var (
+	objPattern       = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	startxrefPattern = regexp.MustCompile(`startxref\s+(\d+)`)
+	pagePattern      = regexp.MustCompile(`/Type\s*/Page(?:[\s/<>\[\]()]|$)`)
+	objStmPattern    = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	xrefPattern      = regexp.MustCompile(`/Type\s*/XRef\b`)
+	mediaBoxPattern  = regexp.MustCompile(`/MediaBox\s*\[([^\]]*)\]`)
+	idPattern        = regexp.MustCompile(`/ID\s*\[[^\]]*\]`)
+)
+
+type pdfRef struct {
+	num int
+	gen int
+}
+
+func (r pdfRef) String() string {
+	return fmt.Sprintf("%d %d R", r.num, r.gen)
+}
+
+type pdfObject struct {
+	ref    pdfRef
+	body   string
+	offset int
+}
+
+type pdfDocument struct {
+	objects    map[int]pdfObject
+	trailer    string
+	xrefStream bool
+}
+
+// parsePdf indexes the objects and reads the latest trailer; encrypted
+// documents are refused since their strings and streams are unreadable
+func parsePdf(data []byte) (*pdfDocument, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, ErrUnsupported
+	}
+
+	startxrefs := startxrefPattern.FindAllSubmatch(data, -1)
+	if len(startxrefs) == 0 {
+		return nil, ErrUnsupported
+	}
+	prev, _ := strconv.Atoi(string(startxrefs[len(startxrefs)-1][1]))
+	if prev >= len(data) {
+		return nil, ErrUnsupported
+	}
+
+	objects := scanObjects(data)
+
+	var trailer string
+	var xrefStream bool
+	if bytes.HasPrefix(bytes.TrimLeft(data[prev:], " \r\n\t"), []byte("xref")) {
+		trailer = classicTrailer(data[prev:])
+	} else if dict := dictOf(objectAt(objects, prev)); xrefPattern.MatchString(dict) {
+		// startxref has to land on an xref stream, not just any object
+		trailer, xrefStream = dict, true
+	}
+	// a stale startxref is common in hand-edited files, so look for the last trailer instead
+	if index := bytes.LastIndex(data, []byte("trailer")); trailer == "" && index >= 0 {
+		trailer = classicTrailer(data[index:])
+	}
+	if trailer == "" || strings.Contains(trailer, "/Encrypt") {
+		return nil, ErrUnsupported
+	}
+
+	return &pdfDocument{
+		objects:    objects,
+		trailer:    trailer,
+		xrefStream: xrefStream,
+	}, nil
+}
+
+func (e *ebook) WatermarkPdf(data []byte, stamp Stamp) ([]byte, error) {
+	document, err := parsePdf(data)
+	if err != nil {
+		return nil, err
+	}
+	objects, trailer := document.objects, document.trailer
+
+	root, ok := findRef(trailer, "Root")
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	pagesRef, ok := findRef(dictOf(objects[root.num]), "Pages")
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	leaves := pageLeaves(pagesRef, objects, map[int]bool{})
+	if len(leaves) == 0 {
+		return nil, ErrUnsupported
+	}
+
+	next := 0
+	for num := range objects {
+		next = max(next, num+1)
+	}
+
+	written := map[int]string{}
+	add := func(body string) pdfRef {
+		ref := pdfRef{num: next}
+		next++
+		written[ref.num] = body
+		return ref
+	}
+
+	line := pdfString(stamp.Line())
+	font := add(`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>`)
+	form := add(streamObject(
+		fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 2000 12] /Resources << /Font << /Helv %s >> >>", font),
+		fmt.Sprintf("BT /Helv 7 Tf 0.35 g 0 3.5 Td %s Tj ET", line),
+	))
+	// the page content runs inside q/Q so whatever state it leaves behind
+	// can not move or hide the stamp
+	save := add(streamObject("", "q"))
+
+	for _, leaf := range leaves {
+		llx, lly, _, _ := mediaBox(leaf, objects)
+		draw := add(streamObject("", fmt.Sprintf("Q q 1 0 0 1 %.2f %.2f cm /%s Do Q", llx+18, lly+6, stampName)))
+		written[leaf.ref.num] = stampPage(leaf, objects, form, save, draw)
+	}
+
+	licence := fmt.Sprintf("/FilkompediaLicensee %s /FilkompediaOrder %s ",
+		pdfString(stamp.Name+" <"+stamp.Email+">"), pdfString(stamp.OrderId))
+	info, ok := findRef(trailer, "Info")
+	if existing, found := objects[info.num]; ok && found && dictOf(existing) != "" {
+		written[info.num] = insertIntoDict(dictOf(existing), licence)
+	} else {
+		info = add("<< " + licence + ">>")
+	}
+
+	pending := []int{root.num}
+	for _, body := range written {
+		pending = append(pending, referencedObjects(body)...)
+	}
+	copyReachable(written, objects, pending)
+
+	extra := fmt.Sprintf("/Root %s /Info %s", root, info)
+	if id := idPattern.FindString(trailer); id != "" {
+		extra += " " + id
+	}
+
+	return writePdf(written, objects, extra), nil
+}
+
+// stampPage wraps the page content between save and draw and adds the stamp
+// form to the page resources, which are copied onto the page when inherited
+// or shared so other pages are left alone
+func stampPage(page pdfObject, objects map[int]pdfObject, form, save, draw pdfRef) string {
+	entries := dictEntries(dictOf(page))
+
+	resources, found := entryValue(entries, "Resources")
+	if !found {
+		resources, _ = inheritedValue(page, "Resources", objects)
+	}
+
+	resourceEntries := dictEntries(resolveDict(resources, objects))
+	stampEntry := "/" + stampName + " " + form.String()
+	if xobjects, found := entryValue(resourceEntries, "XObject"); found && resolveDict(xobjects, objects) != "" {
+		resourceEntries = setEntry(resourceEntries, "XObject", insertIntoDict(resolveDict(xobjects, objects), stampEntry))
+	} else {
+		resourceEntries = setEntry(resourceEntries, "XObject", "<< "+stampEntry+" >>")
+	}
+	entries = setEntry(entries, "Resources", buildDict(resourceEntries))
+
+	var contents string
+	if value, found := entryValue(entries, "Contents"); found {
+		contents = resolveArray(value, objects)
+	}
+	entries = setEntry(entries, "Contents", fmt.Sprintf("[%s %s %s]", save, contents, draw))
+
+	return buildDict(entries)
+}
+
+// resolveDict returns the dictionary a value holds or points at
+func resolveDict(value string, objects map[int]pdfObject) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "<<") {
+		return dictOf(pdfObject{body: value})
+	}
+
+	if match := refPattern.FindStringSubmatch(value); match != nil && indirectPattern.MatchString(value) {
+		num, _ := strconv.Atoi(match[1])
+		return dictOf(objects[num])
+	}
+
+	return ""
+}
+
+// resolveArray returns the items of an array value, following a reference to
+// an array object; a single reference is returned as is
+func resolveArray(value string, objects map[int]pdfObject) string {
+	value = strings.TrimSpace(value)
+	if match := refPattern.FindStringSubmatch(value); match != nil && indirectPattern.MatchString(value) {
+		num, _ := strconv.Atoi(match[1])
+		if body := strings.TrimSpace(objects[num].body); strings.HasPrefix(body, "[") {
+			value = body
+		}
+	}
+
+	if strings.HasPrefix(value, "[") {
+		return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	}
+	return value
+}
+
+func setEntry(entries []dictEntry, key string, value string) []dictEntry {
+	for i := range entries {
+		if entries[i].key == key {
+			entries[i].value = value
+			return entries
+		}
+	}
+	return append(entries, dictEntry{key, value})
+}
+
+func streamObject(dict string, data string) string {
+	if dict != "" {
+		dict += " "
+	}
+	return fmt.Sprintf("<< %s/Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+// scanObjects indexes every object body in the file, including those packed
+// in object streams; when an object appears more than once the last wins
+func scanObjects(data []byte) map[int]pdfObject {
+	objects := map[int]pdfObject{}
+	text := string(data)
+
+	for pos := 0; pos < len(text); {
+		loc := objPattern.FindStringSubmatchIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+
+		start, bodyStart := pos+loc[0], pos+loc[1]
+		if start > 0 && !isPdfSpace(text[start-1]) {
+			pos = bodyStart
+			continue
+		}
+
+		num, _ := strconv.Atoi(text[pos+loc[2] : pos+loc[3]])
+		gen, _ := strconv.Atoi(text[pos+loc[4] : pos+loc[5]])
+
+		end := objectEnd(text, bodyStart)
+		if end < 0 {
+			break
+		}
+
+		objects[num] = pdfObject{ref: pdfRef{num, gen}, body: text[bodyStart:end], offset: start}
+		pos = end
+	}
+
+	for _, object := range objects {
+		if !objStmPattern.MatchString(dictOf(object)) {
+			continue
+		}
+
+		for _, packed := range unpackObjectStream(object) {
+			if existing, found := objects[packed.ref.num]; !found || existing.offset < packed.offset {
+				objects[packed.ref.num] = packed
+			}
+		}
+	}
+
+	return objects
+}
+
+func objectEnd(text string, bodyStart int) int {
+	end := strings.Index(text[bodyStart:], "endobj")
+	if end < 0 {
+		return -1
+	}
+
+	if stream := strings.Index(text[bodyStart:bodyStart+end], "stream"); stream >= 0 {
+		streamEnd := strings.Index(text[bodyStart+stream:], "endstream")
+		if streamEnd >= 0 {
+			if after := strings.Index(text[bodyStart+stream+streamEnd:], "endobj"); after >= 0 {
+				return bodyStart + stream + streamEnd + after
+			}
+		}
+	}
+
+	return bodyStart + end
+}
+
+func unpackObjectStream(object pdfObject) []pdfObject {
+	dict := dictOf(object)
+	data, ok := streamData(object.body, strings.Contains(dict, "/FlateDecode"))
+	if !ok {
+		return nil
+	}
+
+	first := dictInt(dict, "First")
+	count := dictInt(dict, "N")
+	if first <= 0 || first > len(data) {
+		return nil
+	}
+
+	header := strings.Fields(string(data[:first]))
+	var packed []pdfObject
+	for i := 0; i+1 < len(header) && i/2 < count; i += 2 {
+		num, err1 := strconv.Atoi(header[i])
+		start, err2 := strconv.Atoi(header[i+1])
+		if err1 != nil || err2 != nil || first+start > len(data) {
+			return packed
+		}
+
+		end := len(data)
+		if i+3 < len(header) {
+			if next, err := strconv.Atoi(header[i+3]); err == nil && first+next <= len(data) {
+				end = first + next
+			}
+		}
+
+		packed = append(packed, pdfObject{
+			ref:    pdfRef{num: num},
+			body:   string(data[first+start : end]),
+			offset: object.offset,
+		})
+	}
+	return packed
+}
+
+func streamData(body string, deflated bool) ([]byte, bool) {
+	start := strings.Index(body, "stream")
+	end := strings.LastIndex(body, "endstream")
+	if start < 0 || end < start {
+		return nil, false
+	}
+
+	start += len("stream")
+	if strings.HasPrefix(body[start:], "\r\n") {
+		start += 2
+	} else if strings.HasPrefix(body[start:], "\n") {
+		start++
+	}
+
+	raw := []byte(body[start:end])
+	if !deflated {
+		return raw, true
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false
+	}
+	defer reader.Close()
+
+	inflated, err := io.ReadAll(reader)
+	if err != nil && len(inflated) == 0 {
+		return nil, false
+	}
+	return inflated, true
+}
+
+func objectAt(objects map[int]pdfObject, offset int) pdfObject {
+	for _, object := range objects {
+		if object.offset == offset {
+			return object
+		}
+	}
+	return pdfObject{}
+}
+
+func classicTrailer(section []byte) string {
+	text := string(section)
+	index := strings.Index(text, "trailer")
+	if index < 0 {
+		return ""
+	}
+
+	rest := text[index+len("trailer"):]
+	start := strings.Index(rest, "<<")
+	if start < 0 {
+		return ""
+	}
+
+	end := dictEnd(rest[start:])
+	if end < 0 {
+		return ""
+	}
+	return rest[start : start+end]
+}
+
+// dictOf returns the dictionary an object body starts with, if any
+func dictOf(object pdfObject) string {
+	body := strings.TrimLeft(object.body, " \r\n\t\f\x00")
+	if !strings.HasPrefix(body, "<<") {
+		return ""
+	}
+
+	end := dictEnd(body)
+	if end < 0 {
+		return ""
+	}
+	return body[:end]
+}
+
+// dictEnd finds where the dictionary at the start of s closes, skipping
+// nested dictionaries and string literals
+func dictEnd(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			nesting := 0
+			for ; i < len(s); i++ {
+				if s[i] == '\\' {
+					i++
+				} else if s[i] == '(' {
+					nesting++
+				} else if s[i] == ')' {
+					nesting--
+					if nesting == 0 {
+						break
+					}
+				}
+			}
+		case '<':
+			if i+1 < len(s) && s[i+1] == '<' {
+				depth++
+				i++
+			} else if end := strings.IndexByte(s[i:], '>'); end >= 0 {
+				i += end
+			}
+		case '>':
+			if i+1 < len(s) && s[i+1] == '>' {
+				depth--
+				i++
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+	}
+	return -1
+}
+
+func insertIntoDict(body string, entries string) string {
+	index := strings.Index(body, "<<")
+	return body[:index+2] + " " + entries + body[index+2:]
+}
+
+func findRef(dict string, key string) (pdfRef, bool) {
+	match := regexp.MustCompile(`/` + key + `\s+(\d+)\s+(\d+)\s+R`).FindStringSubmatch(dict)
+	if match == nil {
+		return pdfRef{}, false
+	}
+
+	num, _ := strconv.Atoi(match[1])
+	gen, _ := strconv.Atoi(match[2])
+	return pdfRef{num, gen}, true
+}
+
+func dictInt(dict string, key string) int {
+	match := regexp.MustCompile(`/` + key + `\s+(\d+)`).FindStringSubmatch(dict)
+	if match == nil {
+		return 0
+	}
+
+	value, _ := strconv.Atoi(match[1])
+	return value
+}
+
+// mediaBox looks up the page size, following inherited boxes up the page tree
+func mediaBox(page pdfObject, objects map[int]pdfObject) (float64, float64, float64, float64) {
+	current := page
+	for depth := 0; depth < 32; depth++ {
+		dict := dictOf(current)
+		if match := mediaBoxPattern.FindStringSubmatch(dict); match != nil {
+			fields := strings.Fields(match[1])
+			if len(fields) == 4 {
+				var box [4]float64
+				valid := true
+				for i, field := range fields {
+					value, err := strconv.ParseFloat(field, 64)
+					if err != nil {
+						valid = false
+						break
+					}
+					box[i] = value
+				}
+
+				if valid {
+					return min(box[0], box[2]), min(box[1], box[3]), max(box[0], box[2]), max(box[1], box[3])
+				}
+			}
+		}
+
+		parent, ok := findRef(dict, "Parent")
+		if !ok {
+			break
+		}
+
+		current, ok = objects[parent.num]
+		if !ok {
+			break
+		}
+	}
+
+	return 0, 0, 612, 792
+}
+
+// pdfString encodes text as a WinAnsi literal string
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+func isPdfSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
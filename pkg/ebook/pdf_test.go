@@ -0,0 +1,316 @@
+package ebook
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sortedNums(objects map[int]string) []int {
+	nums := make([]int, 0, len(objects))
+	for num := range objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// classicPdf writes the objects with an xref table and a trailer holding the given entries
+func classicPdf(objects map[int]string, trailer string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := map[int]int{}
+	nums := sortedNums(objects)
+	for _, num := range nums {
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", num, objects[num])
+	}
+
+	start := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", nums[len(nums)-1]+1)
+	for num := 1; num <= nums[len(nums)-1]; num++ {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offsets[num])
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF\n", nums[len(nums)-1]+1, trailer, start)
+	return buf.Bytes()
+}
+
+// xrefStreamPdf writes plain objects as is, packs the others into an object
+// stream and ends with an xref stream; the xref rows are left empty since
+// the parser indexes objects by scanning
+func xrefStreamPdf(plain map[int]string, packed map[int]string, deflate bool, trailer string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+
+	next := 0
+	for _, num := range sortedNums(plain) {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", num, plain[num])
+		next = max(next, num+1)
+	}
+
+	if len(packed) > 0 {
+		var header, body strings.Builder
+		for _, num := range sortedNums(packed) {
+			fmt.Fprintf(&header, "%d %d ", num, body.Len())
+			body.WriteString(packed[num] + "\n")
+			next = max(next, num+1)
+		}
+
+		data := []byte(header.String() + body.String())
+		filter := ""
+		if deflate {
+			var deflated bytes.Buffer
+			writer := zlib.NewWriter(&deflated)
+			writer.Write(data)
+			writer.Close()
+			data = deflated.Bytes()
+			filter = "/Filter /FlateDecode "
+		}
+
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /ObjStm /N %d /First %d %s/Length %d >>\nstream\n",
+			next, len(packed), header.Len(), filter, len(data))
+		buf.Write(data)
+		buf.WriteString("\nendstream\nendobj\n")
+		next++
+	}
+
+	start := buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /XRef /Size %d %s /W [1 4 2] /Length 0 >>\nstream\n\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n",
+		next, next+1, trailer, start)
+	return buf.Bytes()
+}
+
+// bookObjects is a three page document; the page tree carries the inherited
+// MediaBox and Resources, and the first page has a link annotation
+func bookObjects() map[int]string {
+	return map[int]string{
+		1:  "<< /Type /Catalog /Pages 2 0 R >>",
+		2:  "<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /MediaBox [0 0 400 600] /Resources 9 0 R >>",
+		3:  "<< /Type /Page /Parent 2 0 R /Contents 6 0 R /Annots [10 0 R] >>",
+		4:  "<< /Type /Page /Parent 2 0 R /Contents [7 0 R] >>",
+		5:  "<< /Type /Page /Parent 2 0 R /Contents 8 0 R >>",
+		6:  streamObject("", "BT (PAGE1CONTENT) Tj ET"),
+		7:  streamObject("", "BT (PAGE2CONTENT) Tj ET"),
+		8:  streamObject("", "BT (PAGE3CONTENT) Tj ET"),
+		9:  "<< /Font << /F1 11 0 R >> >>",
+		10: "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /Dest [5 0 R /Fit] >>",
+		11: "<< /Type /Font /Subtype /Type1 /BaseFont /Times-Roman >>",
+		12: "<< /Title (A Book) >>",
+	}
+}
+
+// packedBook keeps the streams as plain objects and packs every dictionary
+// into an object stream, the way most modern writers save
+func packedBook() (map[int]string, map[int]string) {
+	plain, packed := map[int]string{}, map[int]string{}
+	for num, body := range bookObjects() {
+		if strings.Contains(body, "stream") {
+			plain[num] = body
+		} else {
+			packed[num] = body
+		}
+	}
+	return plain, packed
+}
+
+func TestParsePdf(t *testing.T) {
+	plain, packed := packedBook()
+
+	tests := []struct {
+		name       string
+		data       []byte
+		err        error
+		xrefStream bool
+		objects    []int
+	}{
+		{
+			name:    "classic xref table",
+			data:    classicPdf(bookObjects(), "/Root 1 0 R /Info 12 0 R"),
+			objects: []int{1, 2, 3, 8, 12},
+		},
+		{
+			name:       "xref stream",
+			data:       xrefStreamPdf(bookObjects(), nil, false, "/Root 1 0 R"),
+			xrefStream: true,
+			objects:    []int{1, 5, 11},
+		},
+		{
+			name:       "object stream",
+			data:       xrefStreamPdf(plain, packed, false, "/Root 1 0 R"),
+			xrefStream: true,
+			objects:    []int{1, 2, 3, 6, 9},
+		},
+		{
+			name:       "deflated object stream",
+			data:       xrefStreamPdf(plain, packed, true, "/Root 1 0 R"),
+			xrefStream: true,
+			objects:    []int{1, 2, 3, 6, 9},
+		},
+		{
+			name:    "stale startxref falls back to the last trailer",
+			data:    startxrefPattern.ReplaceAll(classicPdf(bookObjects(), "/Root 1 0 R"), []byte("startxref\n0")),
+			objects: []int{1, 2},
+		},
+		{
+			name: "not a pdf",
+			data: []byte("hello world"),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "missing startxref",
+			data: []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n%%EOF\n"),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "startxref past the end",
+			data: []byte("%PDF-1.4\nstartxref\n99999\n%%EOF\n"),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "missing trailer",
+			data: []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\nstartxref\n9\n%%EOF\n"),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "encrypted",
+			data: classicPdf(bookObjects(), "/Root 1 0 R /Encrypt 12 0 R"),
+			err:  ErrUnsupported,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := parsePdf(test.data)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("err = %v, want %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if document.xrefStream != test.xrefStream {
+				t.Errorf("xrefStream = %v, want %v", document.xrefStream, test.xrefStream)
+			}
+			if _, ok := findRef(document.trailer, "Root"); !ok {
+				t.Errorf("trailer %q has no /Root", document.trailer)
+			}
+			for _, num := range test.objects {
+				if strings.TrimSpace(document.objects[num].body) == "" {
+					t.Errorf("object %d was not indexed", num)
+				}
+			}
+		})
+	}
+}
+
+func TestDictEnd(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"flat", "<< /A 1 >>", 10},
+		{"trailing data", "<< /A 1 >> stream", 10},
+		{"nested", "<< /A << /B 2 >> /C 3 >>", 24},
+		{"string holding a closer", "<< /T (a >> b) >>", 17},
+		{"escaped parenthesis", `<< /T (a \) >> ) >>`, 19},
+		{"balanced parentheses", "<< /T (a (>>) b) >>", 19},
+		{"hex string", "<< /ID <AB3E> /B 1 >>", 21},
+		{"unterminated", "<< /A << /B 2 >>", -1},
+		{"unterminated string", "<< /T (a >>", -1},
+		{"empty", "", -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := dictEnd(test.in); got != test.want {
+				t.Errorf("dictEnd(%q) = %d, want %d", test.in, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWatermarkPdf(t *testing.T) {
+	plain, packed := packedBook()
+	stamp := Stamp{Name: "Budi (Reader)", Email: "budi@example.com", OrderId: "order-1", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"classic xref table", classicPdf(bookObjects(), "/Root 1 0 R /Info 12 0 R /ID [<AB> <CD>]"), nil},
+		{"xref stream", xrefStreamPdf(bookObjects(), nil, false, "/Root 1 0 R"), nil},
+		{"deflated object stream", xrefStreamPdf(plain, packed, true, "/Root 1 0 R /Info 12 0 R"), nil},
+		{"no page tree", classicPdf(map[int]string{1: "<< /Type /Catalog >>"}, "/Root 1 0 R"), ErrUnsupported},
+		{"not a pdf", []byte("%PDF"), ErrUnsupported},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := New().WatermarkPdf(test.data, stamp)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("err = %v, want %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+
+			// a single revision, so there is no earlier %%EOF to cut back to
+			if count := bytes.Count(out, []byte("%%EOF")); count != 1 {
+				t.Errorf("output has %d %%%%EOF markers, want 1", count)
+			}
+
+			document, err := parsePdf(out)
+			if err != nil {
+				t.Fatalf("parsing the output: %v", err)
+			}
+
+			root, _ := findRef(document.trailer, "Root")
+			pagesRef, _ := findRef(dictOf(document.objects[root.num]), "Pages")
+			leaves := pageLeaves(pagesRef, document.objects, map[int]bool{})
+			if len(leaves) != 3 {
+				t.Fatalf("output has %d pages, want 3", len(leaves))
+			}
+
+			for _, leaf := range leaves {
+				entries := dictEntries(dictOf(leaf))
+				contents, _ := entryValue(entries, "Contents")
+				refs := refPattern.FindAllStringSubmatch(contents, -1)
+				if len(refs) != 3 {
+					t.Errorf("page %d contents %q, want the original wrapped by two streams", leaf.ref.num, contents)
+					continue
+				}
+
+				num, _ := strconv.Atoi(refs[2][1])
+				draw := document.objects[num]
+				if !strings.Contains(draw.body, "/"+stampName+" Do") {
+					t.Errorf("page %d does not draw the stamp", leaf.ref.num)
+				}
+
+				resources, _ := entryValue(entries, "Resources")
+				if !strings.Contains(resources, "/"+stampName) || !strings.Contains(resources, "/F1 11 0 R") {
+					t.Errorf("page %d resources %q lost the page font or the stamp", leaf.ref.num, resources)
+				}
+			}
+
+			for _, marker := range []string{"PAGE1CONTENT", "PAGE3CONTENT", `Licensed to Budi \(Reader\) <budi@example.com> - order order-1 - 2024-05-01`, "/FilkompediaOrder (order-1)"} {
+				if !bytes.Contains(out, []byte(marker)) {
+					t.Errorf("output is missing %q", marker)
+				}
+			}
+
+			// the shared resources object is copied onto each page, never edited
+			if shared := document.objects[9].body; strings.Contains(shared, stampName) {
+				t.Errorf("shared resources were modified: %q", shared)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package ebook
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A sample must not carry the rest of the book, so it is written as a new
+// file holding only what the first pages reference.
+
+var refPattern = regexp.MustCompile(`(\d+)\s+(\d+)\s+R\b`)
+
+// page keys a leaf can inherit from the page tree, copied down because the
+// sample has a flat tree of its own
+var inheritedKeys = []string{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+type dictEntry struct {
+	key   string
+	value string
+}
+
+func (e *ebook) SamplePdf(data []byte, pages int) ([]byte, error) {
+	document, err := parsePdf(data)
+	if err != nil {
+		return nil, err
+	}
+	objects := document.objects
+
+	root, ok := findRef(document.trailer, "Root")
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	pagesRef, ok := findRef(dictOf(objects[root.num]), "Pages")
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	leaves := pageLeaves(pagesRef, objects, map[int]bool{})
+	if len(leaves) == 0 {
+		return nil, ErrUnsupported
+	}
+	leaves = leaves[:min(pages, len(leaves))]
+
+	next := 0
+	for num := range objects {
+		next = max(next, num+1)
+	}
+	catalog, tree := next, next+1
+
+	written := map[int]string{
+		catalog: fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", tree),
+	}
+
+	kids := make([]string, len(leaves))
+	var pending []int
+	for i, leaf := range leaves {
+		kids[i] = leaf.ref.String()
+
+		entries := dictEntries(dictOf(leaf))
+		for _, key := range inheritedKeys {
+			if _, found := entryValue(entries, key); !found {
+				if value, found := inheritedValue(leaf, key, objects); found {
+					entries = append(entries, dictEntry{key, value})
+				}
+			}
+		}
+
+		var kept []dictEntry
+		for _, entry := range entries {
+			switch entry.key {
+			case "Parent":
+				entry.value = fmt.Sprintf("%d 0 R", tree)
+			case "Annots", "B", "StructParents", "Thumb":
+				// links and article beads point at pages outside the sample
+				continue
+			}
+			kept = append(kept, entry)
+		}
+
+		body := buildDict(kept)
+		written[leaf.ref.num] = body
+		pending = append(pending, referencedObjects(body)...)
+	}
+
+	written[tree] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(leaves))
+
+	copyReachable(written, objects, pending)
+
+	return writePdf(written, objects, fmt.Sprintf("/Root %d 0 R", catalog)), nil
+}
+
+// copyReachable copies every object reachable from pending that has not been
+// written yet
+func copyReachable(written map[int]string, objects map[int]pdfObject, pending []int) {
+	for len(pending) > 0 {
+		num := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if _, done := written[num]; done {
+			continue
+		}
+
+		object, found := objects[num]
+		if !found {
+			continue
+		}
+
+		body := strings.TrimSpace(object.body)
+		written[num] = body
+		pending = append(pending, referencedObjects(body)...)
+	}
+}
+
+// pageLeaves lists the pages under a page tree node in reading order
+func pageLeaves(ref pdfRef, objects map[int]pdfObject, seen map[int]bool) []pdfObject {
+	object, found := objects[ref.num]
+	if !found || seen[ref.num] {
+		return nil
+	}
+	seen[ref.num] = true
+
+	dict := dictOf(object)
+	if pagePattern.MatchString(dict) {
+		return []pdfObject{object}
+	}
+
+	kids, _ := entryValue(dictEntries(dict), "Kids")
+	var leaves []pdfObject
+	for _, match := range refPattern.FindAllStringSubmatch(kids, -1) {
+		num, _ := strconv.Atoi(match[1])
+		gen, _ := strconv.Atoi(match[2])
+		leaves = append(leaves, pageLeaves(pdfRef{num, gen}, objects, seen)...)
+	}
+	return leaves
+}
+
+func inheritedValue(page pdfObject, key string, objects map[int]pdfObject) (string, bool) {
+	current := page
+	for depth := 0; depth < 32; depth++ {
+		parent, ok := findRef(dictOf(current), "Parent")
+		if !ok {
+			break
+		}
+
+		current, ok = objects[parent.num]
+		if !ok {
+			break
+		}
+
+		if value, found := entryValue(dictEntries(dictOf(current)), key); found {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// referencedObjects lists the objects a body points at; only the dictionary
+// of a stream is searched, never its binary data
+func referencedObjects(body string) []int {
+	if index := strings.Index(body, "stream"); index >= 0 && strings.HasPrefix(strings.TrimSpace(body), "<<") {
+		if end := dictEnd(strings.TrimSpace(body)); end >= 0 {
+			body = strings.TrimSpace(body)[:end]
+		}
+	}
+
+	var nums []int
+	for _, match := range refPattern.FindAllStringSubmatch(stripStrings(body), -1) {
+		num, _ := strconv.Atoi(match[1])
+		nums = append(nums, num)
+	}
+	return nums
+}
+
+// writePdf saves the objects as a new file, keeping the generation numbers of
+// those taken from source; trailer holds the entries besides /Size
+func writePdf(objects map[int]string, source map[int]pdfObject, trailer string) []byte {
+	nums := make([]int, 0, len(objects))
+	for num := range objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := map[int]int{}
+	for _, num := range nums {
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d %d obj\n%s\nendobj\n", num, source[num].ref.gen, objects[num])
+	}
+
+	start := buf.Len()
+	buf.WriteString("xref\n0 1\n0000000000 65535 f\r\n")
+	for _, num := range nums {
+		fmt.Fprintf(&buf, "%d 1\n%010d %05d n\r\n", num, offsets[num], source[num].ref.gen)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF\n", nums[len(nums)-1]+1, trailer, start)
+	return buf.Bytes()
+}
+
+// dictEntries splits a dictionary into its top level key/value pairs
+func dictEntries(dict string) []dictEntry {
+	if !strings.HasPrefix(dict, "<<") {
+		return nil
+	}
+
+	var entries []dictEntry
+	for i := 2; i < len(dict); {
+		i = skipSpace(dict, i)
+		if i >= len(dict) || dict[i] != '/' {
+			break
+		}
+
+		keyEnd := skipName(dict, i)
+		key := dict[i+1 : keyEnd]
+
+		valueStart := skipSpace(dict, keyEnd)
+		valueEnd := skipValue(dict, valueStart)
+		if valueEnd <= valueStart {
+			break
+		}
+
+		entries = append(entries, dictEntry{key, dict[valueStart:valueEnd]})
+		i = valueEnd
+	}
+	return entries
+}
+
+func entryValue(entries []dictEntry, key string) (string, bool) {
+	for _, entry := range entries {
+		if entry.key == key {
+			return entry.value, true
+		}
+	}
+	return "", false
+}
+
+func buildDict(entries []dictEntry) string {
+	var b strings.Builder
+	b.WriteString("<<")
+	for _, entry := range entries {
+		b.WriteString(" /" + entry.key + " " + entry.value)
+	}
+	b.WriteString(" >>")
+	return b.String()
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) {
+		if isPdfSpace(s[i]) {
+			i++
+		} else if s[i] == '%' {
+			for i < len(s) && s[i] != '\n' && s[i] != '\r' {
+				i++
+			}
+		} else {
+			break
+		}
+	}
+	return i
+}
+
+func skipName(s string, i int) int {
+	for i++; i < len(s) && !isPdfSpace(s[i]) && !strings.ContainsRune("/<>[]()%{}", rune(s[i])); i++ {
+	}
+	return i
+}
+
+var indirectPattern = regexp.MustCompile(`^\d+\s+\d+\s+R\b`)
+
+// skipValue returns where the pdf value starting at i ends
+func skipValue(s string, i int) int {
+	if i >= len(s) {
+		return i
+	}
+
+	switch {
+	case strings.HasPrefix(s[i:], "<<"):
+		if end := dictEnd(s[i:]); end >= 0 {
+			return i + end
+		}
+		return len(s)
+	case s[i] == '<':
+		if end := strings.IndexByte(s[i:], '>'); end >= 0 {
+			return i + end + 1
+		}
+		return len(s)
+	case s[i] == '(':
+		nesting := 0
+		for j := i; j < len(s); j++ {
+			switch s[j] {
+			case '\\':
+				j++
+			case '(':
+				nesting++
+			case ')':
+				nesting--
+				if nesting == 0 {
+					return j + 1
+				}
+			}
+		}
+		return len(s)
+	case s[i] == '[':
+		j := skipSpace(s, i+1)
+		for j < len(s) && s[j] != ']' {
+			end := skipValue(s, j)
+			if end <= j {
+				return len(s)
+			}
+			j = skipSpace(s, end)
+		}
+		return min(j+1, len(s))
+	case s[i] == '/':
+		return skipName(s, i)
+	}
+
+	if match := indirectPattern.FindStringIndex(s[i:]); match != nil {
+		return i + match[1]
+	}
+
+	j := i
+	for j < len(s) && !isPdfSpace(s[j]) && !strings.ContainsRune("/<>[]()%", rune(s[j])) {
+		j++
+	}
+	return j
+}
+
+// stripStrings blanks string literals so text inside them is never read as a reference
+func stripStrings(s string) string {
+	out := []byte(s)
+	for i := 0; i < len(out); i++ {
+		if out[i] != '(' {
+			continue
+		}
+
+		end := skipValue(s, i)
+		for j := i; j < end && j < len(out); j++ {
+			out[j] = ' '
+		}
+		i = end - 1
+	}
+	return string(out)
+}
+
+// SampleEpub keeps the first chapters of the spine, drops the remaining
+// content documents and closes with a page marking the end of the sample
+func (e *ebook) SampleEpub(data []byte, chapters int) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	opfPath, err := findRootfile(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	opf, err := readZipPath(reader, opfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg struct {
+		Items []struct {
+			Id   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IdRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.Unmarshal(opf, &pkg); err != nil {
+		return nil, ErrUnsupported
+	}
+
+	hrefs := map[string]string{}
+	for _, item := range pkg.Items {
+		hrefs[item.Id] = item.Href
+	}
+
+	opfText := string(opf)
+	dropped := map[string]bool{}
+	for i, itemref := range pkg.Spine {
+		if i < chapters {
+			continue
+		}
+
+		href, found := hrefs[itemref.IdRef]
+		if !found {
+			continue
+		}
+
+		dropped[path.Join(path.Dir(opfPath), href)] = true
+		opfText = removeElement(opfText, "itemref", "idref", itemref.IdRef)
+		opfText = removeElement(opfText, "item", "id", itemref.IdRef)
+	}
+
+	if !strings.Contains(opfText, "</manifest>") || !strings.Contains(opfText, "</spine>") {
+		return nil, ErrUnsupported
+	}
+	opfText = strings.Replace(opfText, "</manifest>", `<item id="filkompedia-sample-end" href="filkompedia-sample-end.xhtml" media-type="application/xhtml+xml"/></manifest>`, 1)
+	opfText = strings.Replace(opfText, "</spine>", `<itemref idref="filkompedia-sample-end"/></spine>`, 1)
+
+	var out bytes.Buffer
+	writer := zip.NewWriter(&out)
+	for _, file := range reader.File {
+		switch {
+		case dropped[file.Name]:
+			continue
+		case file.Name == opfPath:
+			if err := writeZipFile(writer, file.Name, []byte(opfText)); err != nil {
+				return nil, err
+			}
+		default:
+			if err := writer.Copy(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := writeZipFile(writer, path.Join(path.Dir(opfPath), "filkompedia-sample-end.xhtml"), []byte(sampleEnd)); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func removeElement(doc string, element string, attr string, value string) string {
+	pattern := regexp.MustCompile(`<(?:\w+:)?` + element + `\b[^>]*\s` + attr + `="` + regexp.QuoteMeta(html.EscapeString(value)) + `"[^>]*?(?:/>|>\s*</(?:\w+:)?` + element + `>)`)
+	return pattern.ReplaceAllString(doc, "")
+}
+
+const sampleEnd = `<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>End of sample</title></head>
+<body>
+<section>
+<h1>End of sample</h1>
+<p>You have reached the end of this free sample. Purchase the book to keep reading.</p>
+</section>
+</body>
+</html>
+`
@@ -0,0 +1,108 @@
+package ebook
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSamplePdf(t *testing.T) {
+	plain, packed := packedBook()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		pages   int
+		err     error
+		want    []string
+		missing []string
+	}{
+		{
+			name:    "classic xref table",
+			data:    classicPdf(bookObjects(), "/Root 1 0 R /Info 12 0 R"),
+			pages:   2,
+			want:    []string{"PAGE1CONTENT", "PAGE2CONTENT", "/Times-Roman"},
+			missing: []string{"PAGE3CONTENT", "/Annots", "(A Book)"},
+		},
+		{
+			name:    "deflated object stream",
+			data:    xrefStreamPdf(plain, packed, true, "/Root 1 0 R"),
+			pages:   1,
+			want:    []string{"PAGE1CONTENT", "/Times-Roman"},
+			missing: []string{"PAGE2CONTENT", "PAGE3CONTENT", "/ObjStm"},
+		},
+		{
+			name:  "more pages than the book has",
+			data:  xrefStreamPdf(bookObjects(), nil, false, "/Root 1 0 R"),
+			pages: 10,
+			want:  []string{"PAGE1CONTENT", "PAGE2CONTENT", "PAGE3CONTENT"},
+		},
+		{
+			name:  "no page tree",
+			data:  classicPdf(map[int]string{1: "<< /Type /Catalog >>"}, "/Root 1 0 R"),
+			pages: 1,
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "empty page tree",
+			data:  classicPdf(map[int]string{1: "<< /Type /Catalog /Pages 2 0 R >>", 2: "<< /Type /Pages /Kids [] /Count 0 >>"}, "/Root 1 0 R"),
+			pages: 1,
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "cyclic page tree",
+			data:  classicPdf(map[int]string{1: "<< /Type /Catalog /Pages 2 0 R >>", 2: "<< /Type /Pages /Kids [2 0 R] /Count 1 >>"}, "/Root 1 0 R"),
+			pages: 1,
+			err:   ErrUnsupported,
+		},
+		{
+			name:  "truncated",
+			data:  []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R"),
+			pages: 1,
+			err:   ErrUnsupported,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := New().SamplePdf(test.data, test.pages)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("err = %v, want %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, marker := range test.want {
+				if !bytes.Contains(out, []byte(marker)) {
+					t.Errorf("sample is missing %q", marker)
+				}
+			}
+			for _, marker := range test.missing {
+				if bytes.Contains(out, []byte(marker)) {
+					t.Errorf("sample should not contain %q", marker)
+				}
+			}
+
+			document, err := parsePdf(out)
+			if err != nil {
+				t.Fatalf("parsing the sample: %v", err)
+			}
+
+			root, _ := findRef(document.trailer, "Root")
+			pagesRef, _ := findRef(dictOf(document.objects[root.num]), "Pages")
+			leaves := pageLeaves(pagesRef, document.objects, map[int]bool{})
+			if want := min(test.pages, 3); len(leaves) != want {
+				t.Fatalf("sample has %d pages, want %d", len(leaves), want)
+			}
+
+			// the flat tree of the sample no longer carries the inherited keys
+			for _, leaf := range leaves {
+				if dict := dictOf(leaf); !strings.Contains(dict, "/MediaBox [0 0 400 600]") || !strings.Contains(dict, "/Resources 9 0 R") {
+					t.Errorf("page %d did not inherit its box and resources: %q", leaf.ref.num, dict)
+				}
+			}
+		})
+	}
+}
@@ -6,37 +6,73 @@ import (
 
 	"github.com/AgungAryansyah/filkompedia-be-insecure/entity"
 	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
 	"github.com/midtrans/midtrans-go/snap"
 )
 
 type IMidtrans interface {
-	NewTransactionToken(orderId string, amount int64, user *entity.User) (*snap.Response, error)
+	NewTransactionToken(orderId string, items []Item, user *entity.User) (*snap.Response, error)
+	Refund(orderId string, refundKey string, amount int64, reason string) error
+}
+
+// Item is one priced line of a transaction; discounts are negative items
+type Item struct {
+	Id    string
+	Name  string
+	Price int64
+	Qty   int32
 }
 
 type Midtrans struct {
 	Client snap.Client
+	Core   coreapi.Client
 }
 
 func NewMidtrans() IMidtrans {
 	client := snap.Client{}
 	client.New(os.Getenv("MIDTRANS_SERVER_KEY"), midtrans.Sandbox)
 
+	core := coreapi.Client{}
+	core.New(os.Getenv("MIDTRANS_SERVER_KEY"), midtrans.Sandbox)
+
 	return &Midtrans{
 		Client: client,
+		Core:   core,
 	}
 }
 
-func (m *Midtrans) NewTransactionToken(orderId string, amount int64, user *entity.User) (*snap.Response, error) {
+// GrossAmount is what a transaction of the items charges
+func GrossAmount(items []Item) int64 {
+	var amount int64
+	for _, item := range items {
+		amount += item.Price * int64(item.Qty)
+	}
+	return amount
+}
+
+// NewTransactionToken charges the sum of the items, since midtrans rejects a
+// gross amount that does not match the item details
+func (m *Midtrans) NewTransactionToken(orderId string, items []Item, user *entity.User) (*snap.Response, error) {
+	details := make([]midtrans.ItemDetails, len(items))
+	for i, item := range items {
+		details[i] = midtrans.ItemDetails{
+			ID:    truncate(item.Id, 50),
+			Name:  truncate(item.Name, 50),
+			Price: item.Price,
+			Qty:   item.Qty,
+		}
+	}
+
 	req := &snap.Request{
 		TransactionDetails: midtrans.TransactionDetails{
 			OrderID:  orderId,
-			GrossAmt: amount,
+			GrossAmt: GrossAmount(items),
 		},
 		CustomerDetail: &midtrans.CustomerDetails{
 			FName: user.Username,
 			Email: user.Email,
 		},
-		//todo add more details
+		Items: &details,
 	}
 
 	snapResp, err := m.Client.CreateTransaction(req)
@@ -46,3 +82,24 @@ func (m *Midtrans) NewTransactionToken(orderId string, amount int64, user *entit
 	}
 	return snapResp, err
 }
+
+// Refund returns part or all of a settled transaction; the refund key makes
+// retrying the same refund safe
+func (m *Midtrans) Refund(orderId string, refundKey string, amount int64, reason string) error {
+	_, err := m.Core.RefundTransaction(orderId, &coreapi.RefundReq{
+		RefundKey: refundKey,
+		Amount:    amount,
+		Reason:    reason,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func truncate(s string, max int) string {
+	if runes := []rune(s); len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
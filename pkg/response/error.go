@@ -31,6 +31,69 @@ var (
 	CheckoutNotFound = NewErrorResponse(http.StatusNotFound, "Checkout not found")
 	PaymentNotFound  = NewErrorResponse(http.StatusNotFound, "Payment not found")
 
+	CategoryNotFound    = NewErrorResponse(http.StatusNotFound, "Category not found")
+	DuplicateCategory   = NewErrorResponse(http.StatusConflict, "Category already exists")
+	CategoryHasChildren = NewErrorResponse(http.StatusConflict, "Category still has subcategories")
+	CategoryCycle       = NewErrorResponse(http.StatusBadRequest, "Category can not be moved under itself")
+
+	BundleNotFound    = NewErrorResponse(http.StatusNotFound, "Bundle not found")
+	BundleInCart      = NewErrorResponse(http.StatusConflict, "Bundle is already in the cart")
+	BundleUnavailable = NewErrorResponse(http.StatusConflict, "Bundle contains a book that is no longer sold")
+	BundleSplit       = NewErrorResponse(http.StatusBadRequest, "Bundle items must be checked out together")
+
+	SeriesNotFound      = NewErrorResponse(http.StatusNotFound, "Series not found")
+	DuplicateSeriesBook = NewErrorResponse(http.StatusConflict, "Book is listed twice in the series")
+
+	ReadingListNotFound  = NewErrorResponse(http.StatusNotFound, "Reading list not found")
+	BookInReadingList    = NewErrorResponse(http.StatusConflict, "Book is already in the reading list")
+	BookNotInReadingList = NewErrorResponse(http.StatusNotFound, "Book is not in the reading list")
+
+	BookFileNotFound     = NewErrorResponse(http.StatusNotFound, "Book file not found")
+	BookNotPurchased     = NewErrorResponse(http.StatusForbidden, "Book has not been purchased")
+	SampleNotFound       = NewErrorResponse(http.StatusNotFound, "Sample not found")
+	WatermarkFailed      = NewErrorResponse(http.StatusUnprocessableEntity, "Book file could not be prepared for download")
+	DownloadLimitReached = NewErrorResponse(http.StatusTooManyRequests, "Daily download limit reached")
+	RevisionNotFound     = NewErrorResponse(http.StatusNotFound, "Revision not found")
+	AuthorNotFound       = NewErrorResponse(http.StatusNotFound, "Author not found")
+	DuplicateAuthor      = NewErrorResponse(http.StatusConflict, "Author already exists")
+	AuthorHasBooks       = NewErrorResponse(http.StatusConflict, "Author still has books")
+	PublisherNotFound    = NewErrorResponse(http.StatusNotFound, "Publisher not found")
+	DuplicatePublisher   = NewErrorResponse(http.StatusConflict, "Publisher already exists")
+
+	FormatNotFound    = NewErrorResponse(http.StatusNotFound, "Book format not found")
+	DuplicateFormat   = NewErrorResponse(http.StatusConflict, "Book format or SKU already exists")
+	FormatInUse       = NewErrorResponse(http.StatusConflict, "Book format is in carts or orders")
+	AlreadyOwned      = NewErrorResponse(http.StatusConflict, "Digital format is already owned")
+	DigitalQuantity   = NewErrorResponse(http.StatusBadRequest, "Digital formats are limited to one copy")
+	EbookPriceOnBook  = NewErrorResponse(http.StatusBadRequest, "Ebook price is edited on the book")
+	ShippingRequired  = NewErrorResponse(http.StatusBadRequest, "Shipping address is required for physical formats")
+	NotPhysicalFormat = NewErrorResponse(http.StatusBadRequest, "Only physical formats have stock")
+
+	InventoryNotFound = NewErrorResponse(http.StatusNotFound, "Book has no tracked stock")
+	OutOfStock        = NewErrorResponse(http.StatusConflict, "Not enough stock")
+	InvalidStock      = NewErrorResponse(http.StatusBadRequest, "Stock can not go below the reserved quantity")
+
+	PreorderNotFound = NewErrorResponse(http.StatusNotFound, "Pre-order not found")
+	PreorderClosed   = NewErrorResponse(http.StatusConflict, "Pre-order is already fulfilled or cancelled")
+	NotReleased      = NewErrorResponse(http.StatusForbidden, "Book has not been released yet")
+
+	SaleNotFound    = NewErrorResponse(http.StatusNotFound, "Sale not found")
+	SaleUnavailable = NewErrorResponse(http.StatusConflict, "Sale price is no longer available")
+	InvalidSale     = NewErrorResponse(http.StatusBadRequest, "Sale price must be below the list price")
+
+	PromotionNotFound      = NewErrorResponse(http.StatusNotFound, "Promotion not found")
+	DuplicatePromotion     = NewErrorResponse(http.StatusConflict, "Promotion code already exists")
+	InvalidPromotion       = NewErrorResponse(http.StatusBadRequest, "Promotion code is invalid or expired")
+	PromotionNotApplicable = NewErrorResponse(http.StatusBadRequest, "Cart does not meet the promotion requirements")
+	PromotionExhausted     = NewErrorResponse(http.StatusConflict, "Promotion usage limit reached")
+
+	WishlistNotFound  = NewErrorResponse(http.StatusNotFound, "Book is not in wishlist")
+	AlreadyWishlisted = NewErrorResponse(http.StatusConflict, "Book is already in wishlist")
+
+	NotificationNotFound  = NewErrorResponse(http.StatusNotFound, "Notification not found")
+	InvalidPreference     = NewErrorResponse(http.StatusBadRequest, "Unknown notification category or channel")
+	MandatoryNotification = NewErrorResponse(http.StatusBadRequest, "Security emails can not be disabled")
+
 	UserUnverified   = NewErrorResponse(http.StatusForbidden, "User is not verified")
 	DuplicateAccount = NewErrorResponse(http.StatusConflict, "User already exists")
 
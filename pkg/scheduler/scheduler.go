@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type IScheduler interface {
+	Every(name string, interval time.Duration, job func() error)
+	Start()
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func() error
+}
+
+type scheduler struct {
+	jobs   []job
+	logger *logrus.Logger
+}
+
+func New(logger *logrus.Logger) IScheduler {
+	return &scheduler{
+		logger: logger,
+	}
+}
+
+func (s *scheduler) Every(name string, interval time.Duration, run func() error) {
+	s.jobs = append(s.jobs, job{
+		name:     name,
+		interval: interval,
+		run:      run,
+	})
+}
+
+func (s *scheduler) Start() {
+	for _, j := range s.jobs {
+		go s.loop(j)
+	}
+}
+
+func (s *scheduler) loop(j job) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		start := time.Now()
+		err := j.run()
+
+		entry := s.logger.WithFields(logrus.Fields{
+			"job":     j.name,
+			"latency": time.Since(start),
+		})
+
+		if err != nil {
+			entry.Error(err.Error())
+		} else {
+			entry.Info("scheduled job finished")
+		}
+	}
+}
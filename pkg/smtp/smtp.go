@@ -32,3 +32,10 @@ func (s *SMTPClient) SendEmail(to, subject, body string) error {
 	message := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", to, subject, body)
 	return smtp.SendMail(s.Address, s.Auth, s.From, []string{to}, []byte(message))
 }
+
+// for non-mandatory emails, adds the unsubscribe link to both the body and the List-Unsubscribe header
+func (s *SMTPClient) SendUnsubscribableEmail(to, subject, body, unsubscribeUrl string) error {
+	message := fmt.Sprintf("To: %s\r\nSubject: %s\r\nList-Unsubscribe: <%s>\r\nList-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n\r\n%s\r\n\r\nUnsubscribe: %s",
+		to, subject, unsubscribeUrl, body, unsubscribeUrl)
+	return smtp.SendMail(s.Address, s.Auth, s.From, []string{to}, []byte(message))
+}
@@ -1,10 +1,13 @@
 package supabase
 
 import (
+	"bytes"
 	"fmt"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/AgungAryansyah/filkompedia-be-insecure/model"
 	"github.com/google/uuid"
@@ -16,7 +19,14 @@ type Supabase struct {
 }
 
 type ISupabase interface {
-	UploadFile(file *multipart.FileHeader, dir string) (string, error)
+	UploadFile(file *multipart.FileHeader, dir string) (path string, url string, err error)
+	UploadBytes(data []byte, dir string, ext string) (path string, url string, err error)
+	DeleteFiles(paths []string) error
+	UploadPrivateFile(file *multipart.FileHeader, dir string) (path string, err error)
+	DeletePrivateFiles(paths []string) error
+	UploadPrivateBytes(path string, data []byte, contentType string) error
+	DownloadPrivateFile(path string) ([]byte, error)
+	CreateSignedUrl(path string, expiresIn int) (string, error)
 }
 
 func New() ISupabase {
@@ -28,17 +38,17 @@ func New() ISupabase {
 	}
 }
 
-func (s Supabase) UploadFile(file *multipart.FileHeader, dir string) (string, error) {
+func (s Supabase) UploadFile(file *multipart.FileHeader, dir string) (string, string, error) {
 	src, err := file.Open()
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer src.Close()
 
 	path := dir + "/" + uuid.NewString() + filepath.Ext(file.Filename)
 	contentType, err := model.GetImageType(file)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	_, err = s.client.UploadFile(
@@ -51,7 +61,33 @@ func (s Supabase) UploadFile(file *multipart.FileHeader, dir string) (string, er
 	)
 
 	if err != nil {
-		return "", err
+		return "", "", err
+	}
+
+	publicURL := fmt.Sprintf("%s/storage/v1/object/public/%s/%s",
+		os.Getenv("SUPABASE_PROJECT_URL"),
+		os.Getenv("SUPABASE_BUCKET_NAME"),
+		path,
+	)
+
+	return path, publicURL, nil
+}
+
+func (s Supabase) UploadBytes(data []byte, dir string, ext string) (string, string, error) {
+	path := dir + "/" + uuid.NewString() + ext
+	contentType := http.DetectContentType(data)
+
+	_, err := s.client.UploadFile(
+		os.Getenv("SUPABASE_BUCKET_NAME"),
+		path,
+		bytes.NewReader(data),
+		storage_go.FileOptions{
+			ContentType: &contentType,
+		},
+	)
+
+	if err != nil {
+		return "", "", err
 	}
 
 	publicURL := fmt.Sprintf("%s/storage/v1/object/public/%s/%s",
@@ -60,5 +96,83 @@ func (s Supabase) UploadFile(file *multipart.FileHeader, dir string) (string, er
 		path,
 	)
 
-	return publicURL, nil
+	return path, publicURL, nil
+}
+
+func (s Supabase) DeleteFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	_, err := s.client.RemoveFile(os.Getenv("SUPABASE_BUCKET_NAME"), paths)
+	return err
+}
+
+// private files live in a bucket without public access and are only
+// reachable through short-lived signed urls
+func (s Supabase) UploadPrivateFile(file *multipart.FileHeader, dir string) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	path := dir + "/" + uuid.NewString() + filepath.Ext(file.Filename)
+	contentType, err := model.GetBookFileType(file)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.client.UploadFile(
+		os.Getenv("SUPABASE_PRIVATE_BUCKET_NAME"),
+		path,
+		src,
+		storage_go.FileOptions{
+			ContentType: &contentType,
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (s Supabase) UploadPrivateBytes(path string, data []byte, contentType string) error {
+	_, err := s.client.UploadFile(
+		os.Getenv("SUPABASE_PRIVATE_BUCKET_NAME"),
+		path,
+		bytes.NewReader(data),
+		storage_go.FileOptions{
+			ContentType: &contentType,
+		},
+	)
+	return err
+}
+
+func (s Supabase) DownloadPrivateFile(path string) ([]byte, error) {
+	return s.client.DownloadFile(os.Getenv("SUPABASE_PRIVATE_BUCKET_NAME"), path)
+}
+
+func (s Supabase) DeletePrivateFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	_, err := s.client.RemoveFile(os.Getenv("SUPABASE_PRIVATE_BUCKET_NAME"), paths)
+	return err
+}
+
+func (s Supabase) CreateSignedUrl(path string, expiresIn int) (string, error) {
+	signed, err := s.client.CreateSignedUrl(os.Getenv("SUPABASE_PRIVATE_BUCKET_NAME"), path, expiresIn)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(signed.SignedURL, "http") {
+		return signed.SignedURL, nil
+	}
+
+	return fmt.Sprintf("%s/storage/v1%s", os.Getenv("SUPABASE_PROJECT_URL"), signed.SignedURL), nil
 }
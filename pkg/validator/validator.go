@@ -12,6 +12,8 @@ func RegisterValidator(v *validator.Validate) {
 	v.RegisterValidation("rfc3339date", Date)
 	v.RegisterValidation("image_type", ImageType)
 	v.RegisterValidation("image_size", ImageSize)
+	v.RegisterValidation("book_file_type", BookFileType)
+	v.RegisterValidation("book_file_size", BookFileSize)
 }
 
 func Date(fl validator.FieldLevel) bool {
@@ -47,3 +49,32 @@ func ImageSize(fl validator.FieldLevel) bool {
 	const maxSize = 2 * 1024 * 1024
 	return fileHeader.Size <= maxSize
 }
+
+func BookFileType(fl validator.FieldLevel) bool {
+	fileHeader, ok := fl.Field().Interface().(multipart.FileHeader)
+	if !ok {
+		return false
+	}
+
+	mimeType, err := model.GetBookFileType(&fileHeader)
+	if err != nil {
+		return false
+	}
+
+	allowedTypes := map[string]bool{
+		model.BookFilePdf:  true,
+		model.BookFileEpub: true,
+	}
+
+	return allowedTypes[mimeType]
+}
+
+func BookFileSize(fl validator.FieldLevel) bool {
+	fileHeader, ok := fl.Field().Interface().(multipart.FileHeader)
+	if !ok {
+		return false
+	}
+
+	const maxSize = 100 * 1024 * 1024
+	return fileHeader.Size <= maxSize
+}
@@ -0,0 +1,12 @@
+CREATE TABLE uploads (
+    id VARCHAR(36) PRIMARY KEY,
+    owner_id VARCHAR(36) NOT NULL,
+    purpose VARCHAR(36) NOT NULL,
+    path TEXT NOT NULL UNIQUE,
+    url TEXT NOT NULL UNIQUE,
+    status VARCHAR(36) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX uploads_status_updated_at_idx ON uploads (status, updated_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS comments_user_id_idx;
+
+ALTER TABLE users
+DROP COLUMN is_private,
+DROP COLUMN created_at;
@@ -0,0 +1,20 @@
+ALTER TABLE users
+ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN created_at TIMESTAMP;
+
+-- existing accounts predate the column, so the earliest activity we have stands
+-- in for their join date; accounts with no activity are left NULL
+UPDATE users SET created_at = activity.first_seen
+FROM (
+    SELECT user_id, MIN(created_at) AS first_seen FROM (
+        SELECT user_id, created_at FROM payments
+        UNION ALL
+        SELECT user_id, created_at FROM comments
+    ) events
+    GROUP BY user_id
+) activity
+WHERE activity.user_id = users.id;
+
+ALTER TABLE users ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX comments_user_id_idx ON comments (user_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS notifications;
+
+DROP TABLE IF EXISTS unsubscribe_tokens;
+
+DROP TABLE IF EXISTS notification_preferences;
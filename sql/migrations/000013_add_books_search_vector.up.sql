@@ -0,0 +1,20 @@
+ALTER TABLE books ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION books_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.author, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(NEW.introduction, '')), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER books_search_vector_trigger
+BEFORE INSERT OR UPDATE OF title, author, description, introduction ON books
+FOR EACH ROW EXECUTE FUNCTION books_search_vector_update();
+
+UPDATE books SET title = title;
+
+CREATE INDEX books_search_vector_idx ON books USING GIN (search_vector);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS book_categories;
+
+DROP TABLE IF EXISTS categories;
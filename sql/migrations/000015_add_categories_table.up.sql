@@ -0,0 +1,61 @@
+CREATE TABLE categories (
+    id VARCHAR(36) PRIMARY KEY,
+    parent_id VARCHAR(36),
+    name VARCHAR(64) NOT NULL,
+    FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX categories_parent_id_name_idx ON categories (COALESCE(parent_id, ''), name);
+
+CREATE TABLE book_categories (
+    book_id VARCHAR(36) NOT NULL,
+    category_id VARCHAR(36) NOT NULL,
+    PRIMARY KEY (book_id, category_id),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX book_categories_category_id_idx ON book_categories (category_id);
+
+INSERT INTO categories (id, parent_id, name) VALUES
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a01', NULL, 'Programming'),
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a02', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a01', 'Web'),
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a03', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a02', 'JavaScript'),
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a04', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a01', 'Algorithms'),
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a05', NULL, 'Software Engineering'),
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a06', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a05', 'Craftsmanship'),
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a07', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a05', 'Design & Architecture'),
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a08', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a05', 'Project Management'),
+('0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a09', NULL, 'Career');
+
+INSERT INTO book_categories (book_id, category_id)
+SELECT seeds.book_id, seeds.category_id
+FROM (VALUES
+    -- The Pragmatic Programmer
+    ('79fd3225-bfa7-404d-a233-3a73b27cefa4', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a06'),
+    -- Clean Code
+    ('b56f251c-a31b-47b2-b38e-8e7147929476', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a06'),
+    -- Design Patterns
+    ('ff50722c-5de9-44d5-ad16-9e44d040152d', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a07'),
+    -- Refactoring
+    ('853a47d6-29a7-4327-8894-0c34aab1076f', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a06'),
+    ('853a47d6-29a7-4327-8894-0c34aab1076f', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a07'),
+    -- The Mythical Man-Month
+    ('4001e6db-91f0-4074-a909-0cea31303a54', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a08'),
+    -- Code Complete
+    ('c0c01901-6504-422a-8448-08a0813a0a79', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a06'),
+    -- JavaScript: The Good Parts
+    ('ae9c2fdc-7a4d-4310-b66c-8d3d2bd9625b', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a03'),
+    -- The Clean Coder
+    ('8fd9bbdb-12fa-43c3-83f5-6125ab3fee9b', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a06'),
+    ('8fd9bbdb-12fa-43c3-83f5-6125ab3fee9b', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a09'),
+    -- Soft Skills
+    ('8e50d42f-674e-42c3-b990-7ec90865e8b9', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a09'),
+    -- Introduction to Algorithms
+    ('c0e43c0a-12d2-4074-8909-57e966a5ca7e', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a04'),
+    -- Eloquent JavaScript
+    ('b351d2b2-6c99-4f74-a011-938d6d499cae', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a03'),
+    -- Domain-Driven Design
+    ('7c83db44-e06e-435a-a3c3-0d176fd83087', '0c6f4a1e-4b8e-4a36-9a57-2f6f0b9f1a07')
+) AS seeds (book_id, category_id)
+INNER JOIN books ON books.id = seeds.book_id;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS book_authors;
+
+ALTER TABLE books DROP COLUMN publisher_id;
+
+DROP TABLE IF EXISTS publishers;
+
+DROP TABLE IF EXISTS authors;
@@ -0,0 +1,48 @@
+CREATE TABLE authors (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    bio TEXT NOT NULL DEFAULT '',
+    photo TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE publishers (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    logo TEXT NOT NULL DEFAULT ''
+);
+
+ALTER TABLE books ADD COLUMN publisher_id VARCHAR(36);
+ALTER TABLE books ADD FOREIGN KEY (publisher_id) REFERENCES publishers(id) ON DELETE SET NULL;
+
+CREATE TABLE book_authors (
+    book_id VARCHAR(36) NOT NULL,
+    author_id VARCHAR(36) NOT NULL,
+    role VARCHAR(16) NOT NULL DEFAULT 'author' CHECK(role IN ('author', 'editor', 'translator')),
+    position INTEGER NOT NULL,
+    PRIMARY KEY (book_id, author_id, role),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX book_authors_author_id_idx ON book_authors (author_id);
+
+-- split the free text bylines, e.g. "Andrew Hunt & David Thomas" or
+-- "Erich Gamma, Richard Helm, Ralph Johnson, John Vlissides", into authors
+INSERT INTO authors (id, name)
+SELECT gen_random_uuid()::text, names.name
+FROM (
+    SELECT DISTINCT trim(parts.name) AS name
+    FROM books
+    CROSS JOIN LATERAL regexp_split_to_table(books.author, '\s*(?:,|&|\sand\s)\s*') AS parts(name)
+    WHERE books.author != 'This book is deleted'
+) names
+WHERE names.name != '';
+
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT DISTINCT ON (books.id, authors.id) books.id, authors.id, 'author', parts.position
+FROM books
+CROSS JOIN LATERAL regexp_split_to_table(books.author, '\s*(?:,|&|\sand\s)\s*') WITH ORDINALITY AS parts(name, position)
+INNER JOIN authors ON authors.name = trim(parts.name)
+WHERE books.author != 'This book is deleted'
+ORDER BY books.id, authors.id, parts.position;
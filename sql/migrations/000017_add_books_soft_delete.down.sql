@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS books_active_idx;
+
+-- titles are unique, so each trashed book keeps its id in the placeholder
+UPDATE books
+SET title = 'Deleted Book ' || id,
+    description = 'This book is deleted',
+    introduction = 'This book is deleted',
+    image = 'This book is deleted',
+    author = 'This book is deleted'
+WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,10 @@
+ALTER TABLE books
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN deleted_by VARCHAR(36);
+
+ALTER TABLE books ADD FOREIGN KEY (deleted_by) REFERENCES users(id) ON DELETE SET NULL;
+
+-- rows deleted the old way already lost their content; keep them in the trash
+UPDATE books SET deleted_at = NOW() WHERE author = 'This book is deleted';
+
+CREATE INDEX books_active_idx ON books (release_date DESC) WHERE deleted_at IS NULL;
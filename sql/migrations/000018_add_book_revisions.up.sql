@@ -0,0 +1,13 @@
+CREATE TABLE book_revisions (
+    id VARCHAR(36) PRIMARY KEY,
+    book_id VARCHAR(36) NOT NULL,
+    editor_id VARCHAR(36),
+    changes JSONB NOT NULL,
+    reverts_id VARCHAR(36),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (reverts_id) REFERENCES book_revisions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX book_revisions_book_id_idx ON book_revisions (book_id, created_at DESC);
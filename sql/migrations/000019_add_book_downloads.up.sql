@@ -0,0 +1,12 @@
+CREATE TABLE book_downloads (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36),
+    book_id VARCHAR(36) NOT NULL,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX book_downloads_user_book_idx ON book_downloads (user_id, book_id, created_at DESC);
@@ -0,0 +1,10 @@
+CREATE TABLE book_file_copies (
+    payment_id VARCHAR(36) NOT NULL,
+    book_id VARCHAR(36) NOT NULL,
+    source_file TEXT NOT NULL,
+    path TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (payment_id, book_id),
+    FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
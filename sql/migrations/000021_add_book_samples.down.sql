@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS book_previews;
+DROP TABLE IF EXISTS book_samples;
@@ -0,0 +1,20 @@
+CREATE TABLE book_samples (
+    book_id VARCHAR(36) PRIMARY KEY,
+    mode VARCHAR(16) NOT NULL CHECK (mode IN ('pages', 'chapters', 'file')),
+    count INTEGER NOT NULL DEFAULT 0,
+    path TEXT NOT NULL,
+    source_file TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE TABLE book_previews (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36),
+    book_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX book_previews_book_user_idx ON book_previews (book_id, user_id, created_at);
@@ -0,0 +1,28 @@
+CREATE TABLE book_ratings (
+    book_id VARCHAR(36) PRIMARY KEY,
+    rating_count INT NOT NULL DEFAULT 0,
+    rating_sum INT NOT NULL DEFAULT 0,
+    star_1 INT NOT NULL DEFAULT 0,
+    star_2 INT NOT NULL DEFAULT 0,
+    star_3 INT NOT NULL DEFAULT 0,
+    star_4 INT NOT NULL DEFAULT 0,
+    star_5 INT NOT NULL DEFAULT 0,
+    average NUMERIC(3, 2) GENERATED ALWAYS AS (
+        CASE WHEN rating_count > 0 THEN rating_sum::numeric / rating_count ELSE 0 END
+    ) STORED,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+INSERT INTO book_ratings (book_id, rating_count, rating_sum, star_1, star_2, star_3, star_4, star_5)
+SELECT book_id,
+    COUNT(*),
+    SUM(rating),
+    COUNT(*) FILTER (WHERE rating = 1),
+    COUNT(*) FILTER (WHERE rating = 2),
+    COUNT(*) FILTER (WHERE rating = 3),
+    COUNT(*) FILTER (WHERE rating = 4),
+    COUNT(*) FILTER (WHERE rating = 5)
+FROM comments
+GROUP BY book_id;
+
+CREATE INDEX book_ratings_average_idx ON book_ratings (average DESC);
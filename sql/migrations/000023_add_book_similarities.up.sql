@@ -0,0 +1,12 @@
+CREATE TABLE book_similarities (
+    book_id VARCHAR(36) NOT NULL,
+    related_id VARCHAR(36) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    co_purchases INT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (book_id, related_id),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (related_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX book_similarities_book_score_idx ON book_similarities (book_id, score DESC);
@@ -0,0 +1,23 @@
+CREATE TABLE cart_events (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36),
+    book_id VARCHAR(36) NOT NULL,
+    amount INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX cart_events_created_at_idx ON cart_events (created_at);
+
+CREATE TABLE book_rankings (
+    list VARCHAR(32) NOT NULL,
+    book_id VARCHAR(36) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    position INT NOT NULL,
+    computed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list, book_id),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX book_rankings_list_position_idx ON book_rankings (list, position);
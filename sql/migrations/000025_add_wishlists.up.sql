@@ -0,0 +1,12 @@
+CREATE TABLE wishlists (
+    user_id VARCHAR(36) NOT NULL,
+    book_id VARCHAR(36) NOT NULL,
+    alert_price DECIMAL NOT NULL,
+    release_alerted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, book_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX wishlists_book_id_idx ON wishlists (book_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_reservations;
+DROP TABLE IF EXISTS stock_adjustments;
+DROP TABLE IF EXISTS book_inventory;
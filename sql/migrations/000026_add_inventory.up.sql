@@ -0,0 +1,37 @@
+CREATE TABLE book_inventory (
+    book_id VARCHAR(36) PRIMARY KEY,
+    on_hand INT NOT NULL DEFAULT 0,
+    reserved INT NOT NULL DEFAULT 0,
+    low_stock_threshold INT NOT NULL DEFAULT 5,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (reserved >= 0 AND reserved <= on_hand),
+    CHECK (low_stock_threshold >= 0),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE TABLE stock_adjustments (
+    id VARCHAR(36) PRIMARY KEY,
+    book_id VARCHAR(36) NOT NULL,
+    delta INT NOT NULL,
+    reason TEXT NOT NULL,
+    admin_id VARCHAR(36),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (admin_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX stock_adjustments_book_id_idx ON stock_adjustments (book_id, created_at DESC);
+
+CREATE TABLE stock_reservations (
+    checkout_id VARCHAR(36) NOT NULL,
+    book_id VARCHAR(36) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    status VARCHAR(16) NOT NULL DEFAULT 'held',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (checkout_id, book_id),
+    FOREIGN KEY (checkout_id) REFERENCES checkouts(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX stock_reservations_held_idx ON stock_reservations (created_at) WHERE status = 'held';
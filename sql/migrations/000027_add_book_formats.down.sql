@@ -0,0 +1,23 @@
+ALTER TABLE checkouts
+    DROP COLUMN shipping_name,
+    DROP COLUMN shipping_phone,
+    DROP COLUMN shipping_address,
+    DROP COLUMN shipping_fee;
+
+ALTER TABLE stock_reservations DROP CONSTRAINT stock_reservations_pkey;
+DELETE FROM stock_reservations a USING stock_reservations b
+WHERE a.checkout_id = b.checkout_id AND a.book_id = b.book_id AND a.format_id > b.format_id;
+ALTER TABLE stock_reservations DROP COLUMN format_id;
+ALTER TABLE stock_reservations ADD PRIMARY KEY (checkout_id, book_id);
+
+ALTER TABLE stock_adjustments DROP COLUMN format_id;
+
+ALTER TABLE book_inventory DROP CONSTRAINT book_inventory_pkey;
+DELETE FROM book_inventory a USING book_inventory b
+WHERE a.book_id = b.book_id AND a.format_id > b.format_id;
+ALTER TABLE book_inventory DROP COLUMN format_id;
+ALTER TABLE book_inventory ADD PRIMARY KEY (book_id);
+
+ALTER TABLE carts DROP COLUMN format_id;
+
+DROP TABLE IF EXISTS book_formats;
@@ -0,0 +1,66 @@
+CREATE TABLE book_formats (
+    id VARCHAR(36) PRIMARY KEY,
+    book_id VARCHAR(36) NOT NULL,
+    format VARCHAR(16) NOT NULL CHECK (format IN ('ebook', 'paperback', 'hardcover', 'audiobook')),
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    price DECIMAL NOT NULL CHECK (price > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (book_id, format),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+-- every existing book was sold as an ebook
+INSERT INTO book_formats (id, book_id, format, sku, price)
+SELECT gen_random_uuid()::text, id, 'ebook', 'EB-' || UPPER(LEFT(REPLACE(id, '-', ''), 12)), price
+FROM books;
+
+-- stocked books also had physical copies
+INSERT INTO book_formats (id, book_id, format, sku, price)
+SELECT gen_random_uuid()::text, books.id, 'paperback', 'PB-' || UPPER(LEFT(REPLACE(books.id, '-', ''), 12)), books.price
+FROM book_inventory
+INNER JOIN books ON books.id = book_inventory.book_id;
+
+ALTER TABLE carts ADD COLUMN format_id VARCHAR(36);
+UPDATE carts SET format_id = book_formats.id
+FROM book_formats
+WHERE book_formats.book_id = carts.book_id AND book_formats.format = 'ebook';
+
+-- carts that reserved stock were physical copies, not ebooks
+UPDATE carts SET format_id = book_formats.id
+FROM stock_reservations, book_formats
+WHERE stock_reservations.checkout_id = carts.checkout_id AND stock_reservations.book_id = carts.book_id
+    AND book_formats.book_id = carts.book_id AND book_formats.format = 'paperback';
+ALTER TABLE carts ALTER COLUMN format_id SET NOT NULL;
+ALTER TABLE carts ADD FOREIGN KEY (format_id) REFERENCES book_formats(id) ON DELETE RESTRICT;
+
+-- stock belongs to a physical format rather than to the book
+ALTER TABLE book_inventory ADD COLUMN format_id VARCHAR(36);
+UPDATE book_inventory SET format_id = book_formats.id
+FROM book_formats
+WHERE book_formats.book_id = book_inventory.book_id AND book_formats.format = 'paperback';
+ALTER TABLE book_inventory ALTER COLUMN format_id SET NOT NULL;
+ALTER TABLE book_inventory DROP CONSTRAINT book_inventory_pkey;
+ALTER TABLE book_inventory ADD PRIMARY KEY (format_id);
+ALTER TABLE book_inventory ADD FOREIGN KEY (format_id) REFERENCES book_formats(id) ON DELETE CASCADE;
+
+ALTER TABLE stock_adjustments ADD COLUMN format_id VARCHAR(36);
+UPDATE stock_adjustments SET format_id = book_inventory.format_id
+FROM book_inventory
+WHERE book_inventory.book_id = stock_adjustments.book_id;
+ALTER TABLE stock_adjustments ALTER COLUMN format_id SET NOT NULL;
+ALTER TABLE stock_adjustments ADD FOREIGN KEY (format_id) REFERENCES book_formats(id) ON DELETE CASCADE;
+
+ALTER TABLE stock_reservations ADD COLUMN format_id VARCHAR(36);
+UPDATE stock_reservations SET format_id = book_inventory.format_id
+FROM book_inventory
+WHERE book_inventory.book_id = stock_reservations.book_id;
+ALTER TABLE stock_reservations ALTER COLUMN format_id SET NOT NULL;
+ALTER TABLE stock_reservations DROP CONSTRAINT stock_reservations_pkey;
+ALTER TABLE stock_reservations ADD PRIMARY KEY (checkout_id, format_id);
+ALTER TABLE stock_reservations ADD FOREIGN KEY (format_id) REFERENCES book_formats(id) ON DELETE CASCADE;
+
+ALTER TABLE checkouts
+    ADD COLUMN shipping_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN shipping_phone VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN shipping_address TEXT NOT NULL DEFAULT '',
+    ADD COLUMN shipping_fee DECIMAL NOT NULL DEFAULT 0;
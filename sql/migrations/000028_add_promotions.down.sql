@@ -0,0 +1,10 @@
+ALTER TABLE checkouts
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS subtotal;
+
+ALTER TABLE carts
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS unit_price;
+
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,45 @@
+-- promotions without a code are applied automatically at checkout
+CREATE TABLE promotions (
+    id VARCHAR(36) PRIMARY KEY,
+    code VARCHAR(64) UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('percentage', 'fixed')),
+    value DECIMAL NOT NULL CHECK (value > 0),
+    scope VARCHAR(16) NOT NULL DEFAULT 'all' CHECK (scope IN ('all', 'book', 'category')),
+    book_id VARCHAR(36),
+    category_id VARCHAR(36),
+    min_spend DECIMAL NOT NULL DEFAULT 0,
+    min_quantity INT NOT NULL DEFAULT 0,
+    max_uses INT,
+    max_uses_per_user INT,
+    starts_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (kind <> 'percentage' OR value <= 100),
+    CHECK ((scope = 'book') = (book_id IS NOT NULL)),
+    CHECK ((scope = 'category') = (category_id IS NOT NULL)),
+    CHECK (ends_at IS NULL OR ends_at >= starts_at),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE promotion_redemptions (
+    promotion_id VARCHAR(36) NOT NULL,
+    checkout_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    discount DECIMAL NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (promotion_id, checkout_id),
+    FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE CASCADE,
+    FOREIGN KEY (checkout_id) REFERENCES checkouts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX promotion_redemptions_user_idx ON promotion_redemptions (promotion_id, user_id);
+
+ALTER TABLE carts
+    ADD COLUMN unit_price DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN discount DECIMAL NOT NULL DEFAULT 0;
+
+ALTER TABLE checkouts
+    ADD COLUMN subtotal DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN discount DECIMAL NOT NULL DEFAULT 0;
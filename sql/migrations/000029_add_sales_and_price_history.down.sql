@@ -0,0 +1,5 @@
+ALTER TABLE carts DROP COLUMN IF EXISTS sale_id;
+
+DROP TABLE IF EXISTS price_history;
+DROP TABLE IF EXISTS sale_claims;
+DROP TABLE IF EXISTS book_sales;
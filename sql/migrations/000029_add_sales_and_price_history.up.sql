@@ -0,0 +1,55 @@
+CREATE TABLE book_sales (
+    id VARCHAR(36) PRIMARY KEY,
+    book_id VARCHAR(36) NOT NULL,
+    format_id VARCHAR(36) NOT NULL,
+    sale_price DECIMAL NOT NULL CHECK (sale_price > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    quantity INT CHECK (quantity > 0),
+    sold INT NOT NULL DEFAULT 0 CHECK (sold >= 0),
+    created_by VARCHAR(36),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (ends_at >= starts_at),
+    CHECK (quantity IS NULL OR sold <= quantity),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (format_id) REFERENCES book_formats(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX book_sales_format_id_idx ON book_sales (format_id, ends_at);
+
+-- flash sale units held by a checkout until its payment settles
+CREATE TABLE sale_claims (
+    sale_id VARCHAR(36) NOT NULL,
+    checkout_id VARCHAR(36) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    status VARCHAR(16) NOT NULL DEFAULT 'held',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (sale_id, checkout_id),
+    FOREIGN KEY (sale_id) REFERENCES book_sales(id) ON DELETE CASCADE,
+    FOREIGN KEY (checkout_id) REFERENCES checkouts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX sale_claims_held_idx ON sale_claims (created_at) WHERE status = 'held';
+
+CREATE TABLE price_history (
+    id VARCHAR(36) PRIMARY KEY,
+    book_id VARCHAR(36) NOT NULL,
+    format_id VARCHAR(36) NOT NULL,
+    price DECIMAL NOT NULL,
+    sale_id VARCHAR(36),
+    recorded_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (format_id) REFERENCES book_formats(id) ON DELETE CASCADE,
+    FOREIGN KEY (sale_id) REFERENCES book_sales(id) ON DELETE SET NULL
+);
+
+CREATE INDEX price_history_format_id_idx ON price_history (format_id, recorded_at DESC);
+
+INSERT INTO price_history (id, book_id, format_id, price, recorded_at)
+SELECT gen_random_uuid()::text, book_id, id, price, NOW() FROM book_formats;
+
+ALTER TABLE carts
+    ADD COLUMN sale_id VARCHAR(36),
+    ADD FOREIGN KEY (sale_id) REFERENCES book_sales(id) ON DELETE SET NULL;
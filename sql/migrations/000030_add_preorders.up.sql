@@ -0,0 +1,23 @@
+-- one row per checked out cart of a book that was not yet released;
+-- release_date is the date customers were last told about
+CREATE TABLE preorders (
+    id VARCHAR(36) PRIMARY KEY,
+    cart_id VARCHAR(36) NOT NULL UNIQUE,
+    user_id VARCHAR(36) NOT NULL,
+    book_id VARCHAR(36) NOT NULL,
+    checkout_id VARCHAR(36) NOT NULL,
+    release_date DATE NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'fulfilled', 'cancelled')),
+    refund_amount DECIMAL NOT NULL DEFAULT 0,
+    cancel_reason TEXT NOT NULL DEFAULT '',
+    cancelled_by VARCHAR(36),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (cart_id) REFERENCES carts(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+    FOREIGN KEY (checkout_id) REFERENCES checkouts(id) ON DELETE CASCADE,
+    FOREIGN KEY (cancelled_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX preorders_open_book_id_idx ON preorders (book_id) WHERE status = 'open';
+CREATE INDEX preorders_user_id_idx ON preorders (user_id, created_at DESC);
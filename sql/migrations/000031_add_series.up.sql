@@ -0,0 +1,20 @@
+CREATE TABLE series (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- position is the reading order; label is what the volume is called, e.g. "Vol. 2"
+CREATE TABLE series_books (
+    series_id VARCHAR(36) NOT NULL,
+    book_id VARCHAR(36) NOT NULL,
+    position INT NOT NULL CHECK (position > 0),
+    label VARCHAR(64) NOT NULL DEFAULT '',
+    PRIMARY KEY (series_id, book_id),
+    UNIQUE (series_id, position),
+    FOREIGN KEY (series_id) REFERENCES series(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX series_books_book_id_idx ON series_books (book_id);
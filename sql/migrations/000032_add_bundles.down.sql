@@ -0,0 +1,4 @@
+ALTER TABLE carts DROP COLUMN IF EXISTS bundle_id;
+
+DROP TABLE IF EXISTS bundle_books;
+DROP TABLE IF EXISTS bundles;
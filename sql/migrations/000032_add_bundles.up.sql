@@ -0,0 +1,25 @@
+CREATE TABLE bundles (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    price DECIMAL NOT NULL CHECK (price > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+-- the contents are fixed once the bundle is created
+CREATE TABLE bundle_books (
+    bundle_id VARCHAR(36) NOT NULL,
+    book_id VARCHAR(36) NOT NULL,
+    position INT NOT NULL CHECK (position > 0),
+    PRIMARY KEY (bundle_id, book_id),
+    FOREIGN KEY (bundle_id) REFERENCES bundles(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);
+
+CREATE INDEX bundle_books_book_id_idx ON bundle_books (book_id);
+
+-- a bundle sits in the cart as one row per contained ebook, sharing bundle_id
+ALTER TABLE carts
+    ADD COLUMN bundle_id VARCHAR(36),
+    ADD FOREIGN KEY (bundle_id) REFERENCES bundles(id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+-- channels already delivered for a notification that is retried until every channel goes out
+CREATE TABLE notification_deliveries (
+    user_id VARCHAR(36) NOT NULL,
+    delivery_key VARCHAR(255) NOT NULL,
+    channel VARCHAR(36) NOT NULL,
+    delivered_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, delivery_key, channel),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
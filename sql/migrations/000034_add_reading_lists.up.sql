@@ -0,0 +1,21 @@
+CREATE TABLE reading_lists (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX reading_lists_user_id_idx ON reading_lists (user_id, created_at DESC);
+
+CREATE TABLE reading_list_books (
+    list_id VARCHAR(36) NOT NULL,
+    book_id VARCHAR(36) NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_id, book_id),
+    FOREIGN KEY (list_id) REFERENCES reading_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+);